@@ -0,0 +1,701 @@
+package xredis
+
+import (
+	"errors"
+	"github.com/garyburd/redigo/redis"
+	"net"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	configCommand       = "CONFIG"
+	clientCommand       = "CLIENT"
+	slowLogCommand      = "SLOWLOG"
+	latencyCommand      = "LATENCY"
+	memoryCommand       = "MEMORY"
+	dbSizeCommand       = "DBSIZE"
+	lastSaveCommand     = "LASTSAVE"
+	bgSaveCommand       = "BGSAVE"
+	bgRewriteAofCommand = "BGREWRITEAOF"
+	timeCommand         = "TIME"
+	roleCommand         = "ROLE"
+
+	getSubcommand       = "GET"
+	setSubcommand       = "SET"
+	rewriteSubcommand   = "REWRITE"
+	resetStatSubcommand = "RESETSTAT"
+	listSubcommand      = "LIST"
+	killSubcommand      = "KILL"
+	setNameSubcommand   = "SETNAME"
+	setInfoSubcommand   = "SETINFO"
+	getNameSubcommand   = "GETNAME"
+	idSubcommand        = "ID"
+	pauseSubcommand     = "PAUSE"
+	lenSubcommand       = "LEN"
+	resetSubcommand     = "RESET"
+	latestSubcommand    = "LATEST"
+	historySubcommand   = "HISTORY"
+	usageSubcommand     = "USAGE"
+	statsSubcommand     = "STATS"
+	doctorSubcommand    = "DOCTOR"
+
+	idOption      = "ID"
+	addressOption = "ADDR"
+	lAddrOption   = "LADDR"
+	userOption    = "USER"
+	maxAgeOption  = "MAXAGE"
+	samplesOption = "SAMPLES"
+	writeOption   = "WRITE"
+	allOption     = "ALL"
+	libNameOption = "LIB-NAME"
+	libVerOption  = "LIB-VER"
+
+	replicaRole  = "slave"
+	sentinelRole = "sentinel"
+
+	invalidReplyError = "invalid reply"
+	noKillFilterError = "at least one client kill filter must be set"
+
+	libraryName   = "xredis"
+	libraryModule = "github.com/shomali11/xredis"
+)
+
+// ClientInfo contains a client connection's information as returned by CLIENT LIST.
+// Fields contains every property as text, including the ones that are not parsed
+type ClientInfo struct {
+	ID                   int64
+	Address              string
+	LocalAddress         string
+	Name                 string
+	Age                  time.Duration
+	Idle                 time.Duration
+	Flags                string
+	Database             int64
+	Subscriptions        int64
+	PatternSubscriptions int64
+	Command              string
+	User                 string
+	Fields               map[string]string
+}
+
+// ClientKillOptions are the filters of the clients to close, only the ones that are set are applied
+type ClientKillOptions struct {
+	ID           int64
+	Address      string
+	LocalAddress string
+	Type         string
+	User         string
+	MaxAge       time.Duration
+}
+
+// SlowLogEntry is a command logged by the slow log
+type SlowLogEntry struct {
+	ID            int64
+	Time          time.Time
+	Duration      time.Duration
+	Args          []string
+	ClientAddress string
+	ClientName    string
+}
+
+// LatencyEvent is the latest and maximum latency spikes of an event
+type LatencyEvent struct {
+	Name    string
+	Time    time.Time
+	Latest  time.Duration
+	Maximum time.Duration
+}
+
+// LatencySample is a latency spike of an event
+type LatencySample struct {
+	Time    time.Time
+	Latency time.Duration
+}
+
+// MemoryStats contains the server's memory usage as returned by MEMORY STATS.
+// Fields contains every value, including the ones that are not parsed
+type MemoryStats struct {
+	PeakAllocated      int64
+	TotalAllocated     int64
+	StartupAllocated   int64
+	ReplicationBacklog int64
+	ClientsNormal      int64
+	ClientsReplicas    int64
+	KeysCount          int64
+	KeysBytesPerKey    int64
+	DatasetBytes       int64
+	DatasetPercentage  float64
+	Fragmentation      float64
+	Fields             map[string]interface{}
+}
+
+// RoleInfo contains the server's replication role as returned by ROLE.
+// Masters report their replication offset and replicas, replicas their master and sentinels their monitored masters
+type RoleInfo struct {
+	Role              string
+	ReplicationOffset int64
+	Replicas          []RoleReplica
+	MasterAddress     string
+	State             string
+	MasterNames       []string
+}
+
+// RoleReplica is a replica connected to a master
+type RoleReplica struct {
+	Address           string
+	ReplicationOffset int64
+}
+
+// ConfigGet returns the configuration parameters matching the pattern. It is not supported on a cluster
+func (c *Client) ConfigGet(pattern string) (map[string]string, error) {
+	if c.cluster != nil {
+		return nil, errors.New(clusterUnsupportedError)
+	}
+
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return redis.StringMap(connection.Do(configCommand, getSubcommand, pattern))
+}
+
+// ConfigSet sets a configuration parameter. On a cluster, it is set on every master
+func (c *Client) ConfigSet(parameter string, value string) error {
+	return c.forEachNode(func(connection redis.Conn) error {
+		return toError(connection.Do(configCommand, setSubcommand, parameter, value))
+	})
+}
+
+// ConfigRewrite rewrites the configuration file with the current configuration. On a cluster, every master rewrites its own
+func (c *Client) ConfigRewrite() error {
+	return c.forEachNode(func(connection redis.Conn) error {
+		return toError(connection.Do(configCommand, rewriteSubcommand))
+	})
+}
+
+// ConfigResetStat resets the statistics reported by INFO. On a cluster, the statistics of every master are reset
+func (c *Client) ConfigResetStat() error {
+	return c.forEachNode(func(connection redis.Conn) error {
+		return toError(connection.Do(configCommand, resetStatSubcommand))
+	})
+}
+
+// ClientList returns the connected clients. It is not supported on a cluster
+func (c *Client) ClientList() ([]ClientInfo, error) {
+	if c.cluster != nil {
+		return nil, errors.New(clusterUnsupportedError)
+	}
+
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	list, err := redis.String(connection.Do(clientCommand, listSubcommand))
+	if err != nil {
+		return nil, err
+	}
+
+	var clients []ClientInfo
+	for _, line := range strings.Split(list, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) > 0 {
+			clients = append(clients, parseClientInfo(line))
+		}
+	}
+	return clients, nil
+}
+
+// ClientKill closes the clients matching the options and returns how many were closed.
+// At least one filter must be set, since a bare CLIENT KILL is not a valid command. On a cluster,
+// the matching clients of every master are closed
+func (c *Client) ClientKill(options ClientKillOptions) (int64, error) {
+	args := []interface{}{killSubcommand}
+	if options.ID > 0 {
+		args = append(args, idOption, options.ID)
+	}
+	if len(options.Address) > 0 {
+		args = append(args, addressOption, options.Address)
+	}
+	if len(options.LocalAddress) > 0 {
+		args = append(args, lAddrOption, options.LocalAddress)
+	}
+	if len(options.Type) > 0 {
+		args = append(args, typeOption, options.Type)
+	}
+	if len(options.User) > 0 {
+		args = append(args, userOption, options.User)
+	}
+	if options.MaxAge > 0 {
+		args = append(args, maxAgeOption, int64(options.MaxAge/time.Second))
+	}
+	if len(args) == 1 {
+		return 0, errors.New(noKillFilterError)
+	}
+
+	var count int64
+	err := c.forEachNode(func(connection redis.Conn) error {
+		killed, err := redis.Int64(connection.Do(clientCommand, args...))
+		count += killed
+		return err
+	})
+	return count, err
+}
+
+// ClientSetName names the connection the command runs on.
+// Since connections are pooled, set ClientName on the options to name every connection
+func (c *Client) ClientSetName(name string) error {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return toError(connection.Do(clientCommand, setNameSubcommand, name))
+}
+
+// ClientGetName returns the name of the connection the command runs on and whether it has one
+func (c *Client) ClientGetName() (string, bool, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return toString(connection.Do(clientCommand, getNameSubcommand))
+}
+
+// ClientID returns the id of the connection the command runs on
+func (c *Client) ClientID() (int64, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return redis.Int64(connection.Do(clientCommand, idSubcommand))
+}
+
+// ClientPause suspends the clients' commands for the timeout provided, only the write commands if writeOnly is true.
+// On a cluster, the clients of every master are suspended
+func (c *Client) ClientPause(timeout time.Duration, writeOnly bool) error {
+	mode := allOption
+	if writeOnly {
+		mode = writeOption
+	}
+
+	return c.forEachNode(func(connection redis.Conn) error {
+		return toError(connection.Do(clientCommand, pauseSubcommand, int64(timeout/time.Millisecond), mode))
+	})
+}
+
+// SlowLogGet returns the latest entries of the slow log. If count is not positive, the server's default is used.
+// It is not supported on a cluster
+func (c *Client) SlowLogGet(count int) ([]SlowLogEntry, error) {
+	if c.cluster != nil {
+		return nil, errors.New(clusterUnsupportedError)
+	}
+
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	args := []interface{}{getSubcommand}
+	if count > 0 {
+		args = append(args, count)
+	}
+
+	values, err := redis.Values(connection.Do(slowLogCommand, args...))
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]SlowLogEntry, len(values))
+	for i, value := range values {
+		entries[i], err = toSlowLogEntry(value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+// SlowLogLen returns the number of entries in the slow log. It is not supported on a cluster
+func (c *Client) SlowLogLen() (int64, error) {
+	if c.cluster != nil {
+		return 0, errors.New(clusterUnsupportedError)
+	}
+
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return redis.Int64(connection.Do(slowLogCommand, lenSubcommand))
+}
+
+// SlowLogReset empties the slow log. On a cluster, the slow log of every master is emptied
+func (c *Client) SlowLogReset() error {
+	return c.forEachNode(func(connection redis.Conn) error {
+		return toError(connection.Do(slowLogCommand, resetSubcommand))
+	})
+}
+
+// LatencyLatest returns the latest latency spikes of every event. It is not supported on a cluster
+func (c *Client) LatencyLatest() ([]LatencyEvent, error) {
+	if c.cluster != nil {
+		return nil, errors.New(clusterUnsupportedError)
+	}
+
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	values, err := redis.Values(connection.Do(latencyCommand, latestSubcommand))
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]LatencyEvent, len(values))
+	for i, value := range values {
+		fields, err := redis.Values(value, nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(fields) < 4 {
+			return nil, errors.New(invalidReplyError)
+		}
+
+		events[i].Name, _ = redis.String(fields[0], nil)
+		timestamp, _ := redis.Int64(fields[1], nil)
+		latest, _ := redis.Int64(fields[2], nil)
+		maximum, _ := redis.Int64(fields[3], nil)
+
+		events[i].Time = time.Unix(timestamp, 0)
+		events[i].Latest = time.Duration(latest) * time.Millisecond
+		events[i].Maximum = time.Duration(maximum) * time.Millisecond
+	}
+	return events, nil
+}
+
+// LatencyHistory returns the latency spikes of an event. It is not supported on a cluster
+func (c *Client) LatencyHistory(event string) ([]LatencySample, error) {
+	if c.cluster != nil {
+		return nil, errors.New(clusterUnsupportedError)
+	}
+
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	values, err := redis.Values(connection.Do(latencyCommand, historySubcommand, event))
+	if err != nil {
+		return nil, err
+	}
+
+	samples := make([]LatencySample, len(values))
+	for i, value := range values {
+		fields, err := redis.Int64s(value, nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(fields) < 2 {
+			return nil, errors.New(invalidReplyError)
+		}
+
+		samples[i] = LatencySample{Time: time.Unix(fields[0], 0), Latency: time.Duration(fields[1]) * time.Millisecond}
+	}
+	return samples, nil
+}
+
+// MemoryUsage returns the number of bytes a key and its value use and whether the key exists.
+// If samples is positive, only that many nested values are sampled
+func (c *Client) MemoryUsage(key string, samples int) (int64, bool, error) {
+	connection := c.getReadConnection()
+	defer connection.Close()
+
+	args := []interface{}{usageSubcommand, key}
+	if samples > 0 {
+		args = append(args, samplesOption, samples)
+	}
+	return toInt(connection.Do(memoryCommand, args...))
+}
+
+// MemoryStats returns the server's memory usage. It is not supported on a cluster
+func (c *Client) MemoryStats() (*MemoryStats, error) {
+	if c.cluster != nil {
+		return nil, errors.New(clusterUnsupportedError)
+	}
+
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	fields, err := toFieldMap(connection.Do(memoryCommand, statsSubcommand))
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &MemoryStats{Fields: fields}
+	stats.PeakAllocated, _ = redis.Int64(fields["peak.allocated"], nil)
+	stats.TotalAllocated, _ = redis.Int64(fields["total.allocated"], nil)
+	stats.StartupAllocated, _ = redis.Int64(fields["startup.allocated"], nil)
+	stats.ReplicationBacklog, _ = redis.Int64(fields["replication.backlog"], nil)
+	stats.ClientsNormal, _ = redis.Int64(fields["clients.normal"], nil)
+	stats.ClientsReplicas, _ = redis.Int64(fields["clients.slaves"], nil)
+	stats.KeysCount, _ = redis.Int64(fields["keys.count"], nil)
+	stats.KeysBytesPerKey, _ = redis.Int64(fields["keys.bytes-per-key"], nil)
+	stats.DatasetBytes, _ = redis.Int64(fields["dataset.bytes"], nil)
+	stats.DatasetPercentage, _ = redis.Float64(fields["dataset.percentage"], nil)
+	stats.Fragmentation, _ = redis.Float64(fields["fragmentation"], nil)
+	return stats, nil
+}
+
+// MemoryDoctor returns the server's report of its memory issues. It is not supported on a cluster
+func (c *Client) MemoryDoctor() (string, error) {
+	if c.cluster != nil {
+		return "", errors.New(clusterUnsupportedError)
+	}
+
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return redis.String(connection.Do(memoryCommand, doctorSubcommand))
+}
+
+// DBSize returns the number of keys in the database. On a cluster, the keys of every master are counted
+func (c *Client) DBSize() (int64, error) {
+	if c.cluster != nil {
+		var size int64
+		err := c.forEachNode(func(connection redis.Conn) error {
+			nodeSize, err := redis.Int64(connection.Do(dbSizeCommand))
+			size += nodeSize
+			return err
+		})
+		return size, err
+	}
+
+	connection := c.getReadConnection()
+	defer connection.Close()
+
+	return redis.Int64(connection.Do(dbSizeCommand))
+}
+
+// LastSave returns the time of the last successful save on disk. It is not supported on a cluster
+func (c *Client) LastSave() (time.Time, error) {
+	if c.cluster != nil {
+		return time.Time{}, errors.New(clusterUnsupportedError)
+	}
+
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	timestamp, err := redis.Int64(connection.Do(lastSaveCommand))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(timestamp, 0), nil
+}
+
+// BgSave saves the database on disk in the background. On a cluster, every master saves its own
+func (c *Client) BgSave() error {
+	return c.forEachNode(func(connection redis.Conn) error {
+		return toError(connection.Do(bgSaveCommand))
+	})
+}
+
+// BgRewriteAof rewrites the append only file in the background. On a cluster, every master rewrites its own
+func (c *Client) BgRewriteAof() error {
+	return c.forEachNode(func(connection redis.Conn) error {
+		return toError(connection.Do(bgRewriteAofCommand))
+	})
+}
+
+// Time returns the server's time
+func (c *Client) Time() (time.Time, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	values, err := redis.Int64s(connection.Do(timeCommand))
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(values) < 2 {
+		return time.Time{}, errors.New(invalidReplyError)
+	}
+	return time.Unix(values[0], values[1]*int64(time.Microsecond)), nil
+}
+
+// Role returns the server's replication role. It is not supported on a cluster
+func (c *Client) Role() (*RoleInfo, error) {
+	if c.cluster != nil {
+		return nil, errors.New(clusterUnsupportedError)
+	}
+
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	values, err := redis.Values(connection.Do(roleCommand))
+	if err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		return nil, errors.New(invalidReplyError)
+	}
+
+	role, err := redis.String(values[0], nil)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &RoleInfo{Role: role}
+	switch role {
+	case masterRole:
+		if len(values) < 3 {
+			return nil, errors.New(invalidReplyError)
+		}
+
+		info.ReplicationOffset, _ = redis.Int64(values[1], nil)
+		replicas, err := redis.Values(values[2], nil)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, replica := range replicas {
+			fields, err := redis.Strings(replica, nil)
+			if err != nil {
+				return nil, err
+			}
+			if len(fields) < 3 {
+				return nil, errors.New(invalidReplyError)
+			}
+
+			offset, _ := strconv.ParseInt(fields[2], 10, 64)
+			info.Replicas = append(info.Replicas, RoleReplica{Address: net.JoinHostPort(fields[0], fields[1]), ReplicationOffset: offset})
+		}
+	case replicaRole:
+		if len(values) < 5 {
+			return nil, errors.New(invalidReplyError)
+		}
+
+		host, _ := redis.String(values[1], nil)
+		port, _ := redis.Int64(values[2], nil)
+		info.MasterAddress = net.JoinHostPort(host, strconv.FormatInt(port, 10))
+		info.State, _ = redis.String(values[3], nil)
+		info.ReplicationOffset, _ = redis.Int64(values[4], nil)
+	case sentinelRole:
+		if len(values) > 1 {
+			info.MasterNames, err = redis.Strings(values[1], nil)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return info, nil
+}
+
+// parseClientInfo parses a line of CLIENT LIST such as "id=3 addr=127.0.0.1:52555 name=worker age=10 idle=0 flags=N db=0"
+func parseClientInfo(line string) ClientInfo {
+	properties := infoFields{}
+	for _, property := range strings.Fields(line) {
+		name, value, ok := strings.Cut(property, valueSeparator)
+		if ok {
+			properties[name] = value
+		}
+	}
+
+	return ClientInfo{
+		ID:                   properties.int("id"),
+		Address:              properties.string("addr"),
+		LocalAddress:         properties.string("laddr"),
+		Name:                 properties.string("name"),
+		Age:                  time.Duration(properties.int("age")) * time.Second,
+		Idle:                 time.Duration(properties.int("idle")) * time.Second,
+		Flags:                properties.string("flags"),
+		Database:             properties.int("db"),
+		Subscriptions:        properties.int("sub"),
+		PatternSubscriptions: properties.int("psub"),
+		Command:              properties.string("cmd"),
+		User:                 properties.string("user"),
+		Fields:               properties,
+	}
+}
+
+// toSlowLogEntry parses a slow log entry, the client's address and name are only reported since Redis 4
+func toSlowLogEntry(reply interface{}) (SlowLogEntry, error) {
+	values, err := redis.Values(reply, nil)
+	if err != nil {
+		return SlowLogEntry{}, err
+	}
+	if len(values) < 4 {
+		return SlowLogEntry{}, errors.New(invalidReplyError)
+	}
+
+	entry := SlowLogEntry{}
+	entry.ID, _ = redis.Int64(values[0], nil)
+
+	timestamp, _ := redis.Int64(values[1], nil)
+	entry.Time = time.Unix(timestamp, 0)
+
+	duration, _ := redis.Int64(values[2], nil)
+	entry.Duration = time.Duration(duration) * time.Microsecond
+
+	entry.Args, err = redis.Strings(values[3], nil)
+	if err != nil {
+		return SlowLogEntry{}, err
+	}
+
+	if len(values) >= 6 {
+		entry.ClientAddress, _ = redis.String(values[4], nil)
+		entry.ClientName, _ = redis.String(values[5], nil)
+	}
+	return entry, nil
+}
+
+// identify names a new connection if a name is provided and reports the library's name and version if asked to.
+// Servers older than Redis 7.2 do not support CLIENT SETINFO, so its errors are ignored
+func identify(connection redis.Conn, name string, libraryInfo bool) error {
+	if len(name) > 0 {
+		_, err := connection.Do(clientCommand, setNameSubcommand, name)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !libraryInfo {
+		return nil
+	}
+
+	_, err := connection.Do(clientCommand, setInfoSubcommand, libNameOption, libraryName)
+	if err != nil && !isCommandError(err) {
+		return err
+	}
+
+	version := libraryVersion()
+	if len(version) == 0 {
+		return nil
+	}
+
+	_, err = connection.Do(clientCommand, setInfoSubcommand, libVerOption, version)
+	if err != nil && !isCommandError(err) {
+		return err
+	}
+	return nil
+}
+
+var (
+	libraryVersionOnce  sync.Once
+	libraryVersionValue string
+)
+
+// libraryVersion returns the library's module version, reading the build information only once
+func libraryVersion() string {
+	libraryVersionOnce.Do(func() {
+		libraryVersionValue = readLibraryVersion()
+	})
+	return libraryVersionValue
+}
+
+// readLibraryVersion returns the library's module version from the build information, if available
+func readLibraryVersion() string {
+	buildInfo, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+
+	for _, module := range buildInfo.Deps {
+		if module.Path == libraryModule {
+			if module.Replace != nil {
+				return module.Replace.Version
+			}
+			return module.Version
+		}
+	}
+	return ""
+}
@@ -0,0 +1,287 @@
+package xredis
+
+import (
+	"errors"
+	"github.com/garyburd/redigo/redis"
+	"github.com/rafaeljusto/redigomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestClient_Config(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("CONFIG", "GET", "max*").Expect([]interface{}{[]byte("maxclients"), []byte("10000"), []byte("maxmemory"), []byte("0")})
+	connection.Command("CONFIG", "SET", "maxmemory", "100mb").Expect("OK")
+	connection.Command("CONFIG", "SET", "unknown", "1").ExpectError(errors.New("error"))
+	connection.Command("CONFIG", "REWRITE").Expect("OK")
+	connection.Command("CONFIG", "RESETSTAT").Expect("OK")
+
+	client := mockClient(connection)
+
+	config, err := client.ConfigGet("max*")
+	assert.Equal(t, config, map[string]string{"maxclients": "10000", "maxmemory": "0"})
+	assert.Nil(t, err)
+
+	assert.Nil(t, client.ConfigSet("maxmemory", "100mb"))
+	assert.NotNil(t, client.ConfigSet("unknown", "1"))
+	assert.Nil(t, client.ConfigRewrite())
+	assert.Nil(t, client.ConfigResetStat())
+}
+
+func TestClient_ClientList(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("CLIENT", "LIST").Expect("id=3 addr=127.0.0.1:52555 laddr=127.0.0.1:6379 fd=8 name=worker age=10 idle=2 flags=N db=1 sub=2 psub=1 multi=-1 cmd=client|list user=default\n" +
+		"id=4 addr=127.0.0.1:52556 laddr=127.0.0.1:6379 fd=9 name= age=0 idle=0 flags=P db=0 sub=0 psub=0 multi=-1 cmd=subscribe user=default\n")
+
+	client := mockClient(connection)
+
+	clients, err := client.ClientList()
+	assert.Nil(t, err)
+	assert.Equal(t, len(clients), 2)
+	assert.Equal(t, clients[0].ID, int64(3))
+	assert.Equal(t, clients[0].Address, "127.0.0.1:52555")
+	assert.Equal(t, clients[0].LocalAddress, "127.0.0.1:6379")
+	assert.Equal(t, clients[0].Name, "worker")
+	assert.Equal(t, clients[0].Age, 10*time.Second)
+	assert.Equal(t, clients[0].Idle, 2*time.Second)
+	assert.Equal(t, clients[0].Flags, "N")
+	assert.Equal(t, clients[0].Database, int64(1))
+	assert.Equal(t, clients[0].Subscriptions, int64(2))
+	assert.Equal(t, clients[0].PatternSubscriptions, int64(1))
+	assert.Equal(t, clients[0].Command, "client|list")
+	assert.Equal(t, clients[0].User, "default")
+	assert.Equal(t, clients[0].Fields["fd"], "8")
+	assert.Equal(t, clients[1].Name, "")
+	assert.Equal(t, clients[1].Command, "subscribe")
+}
+
+func TestClient_ClientKill(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("CLIENT", "KILL", "ID", int64(3)).Expect(int64(1))
+	connection.Command("CLIENT", "KILL", "ADDR", "127.0.0.1:52555", "TYPE", "normal", "USER", "default", "MAXAGE", int64(60)).Expect(int64(2))
+
+	client := mockClient(connection)
+
+	count, err := client.ClientKill(ClientKillOptions{ID: 3})
+	assert.Equal(t, count, int64(1))
+	assert.Nil(t, err)
+
+	count, err = client.ClientKill(ClientKillOptions{Address: "127.0.0.1:52555", Type: "normal", User: "default", MaxAge: time.Minute})
+	assert.Equal(t, count, int64(2))
+	assert.Nil(t, err)
+
+	count, err = client.ClientKill(ClientKillOptions{})
+	assert.Equal(t, count, int64(0))
+	assert.NotNil(t, err)
+}
+
+func TestClient_ClientName(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("CLIENT", "SETNAME", "worker").Expect("OK")
+	connection.Command("CLIENT", "GETNAME").Expect("worker")
+	connection.Command("CLIENT", "ID").Expect(int64(7))
+	connection.Command("CLIENT", "PAUSE", int64(500), "WRITE").Expect("OK")
+	connection.Command("CLIENT", "PAUSE", int64(1000), "ALL").Expect("OK")
+
+	client := mockClient(connection)
+
+	assert.Nil(t, client.ClientSetName("worker"))
+
+	name, found, err := client.ClientGetName()
+	assert.Equal(t, name, "worker")
+	assert.True(t, found)
+	assert.Nil(t, err)
+
+	id, err := client.ClientID()
+	assert.Equal(t, id, int64(7))
+	assert.Nil(t, err)
+
+	assert.Nil(t, client.ClientPause(500*time.Millisecond, true))
+	assert.Nil(t, client.ClientPause(time.Second, false))
+}
+
+func TestClient_SlowLog(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("SLOWLOG", "GET", 2).Expect([]interface{}{
+		[]interface{}{int64(14), int64(1700000000), int64(1500), []interface{}{[]byte("KEYS"), []byte("*")}, []byte("127.0.0.1:52555"), []byte("worker")},
+		[]interface{}{int64(13), int64(1600000000), int64(20), []interface{}{[]byte("GET"), []byte("key")}},
+	})
+	connection.Command("SLOWLOG", "GET").Expect([]interface{}{[]interface{}{int64(1)}})
+	connection.Command("SLOWLOG", "LEN").Expect(int64(2))
+	connection.Command("SLOWLOG", "RESET").Expect("OK")
+
+	client := mockClient(connection)
+
+	entries, err := client.SlowLogGet(2)
+	assert.Nil(t, err)
+	assert.Equal(t, entries, []SlowLogEntry{
+		{ID: 14, Time: time.Unix(1700000000, 0), Duration: 1500 * time.Microsecond, Args: []string{"KEYS", "*"}, ClientAddress: "127.0.0.1:52555", ClientName: "worker"},
+		{ID: 13, Time: time.Unix(1600000000, 0), Duration: 20 * time.Microsecond, Args: []string{"GET", "key"}},
+	})
+
+	entries, err = client.SlowLogGet(0)
+	assert.Nil(t, entries)
+	assert.NotNil(t, err)
+
+	count, err := client.SlowLogLen()
+	assert.Equal(t, count, int64(2))
+	assert.Nil(t, err)
+
+	assert.Nil(t, client.SlowLogReset())
+}
+
+func TestClient_Latency(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("LATENCY", "LATEST").Expect([]interface{}{
+		[]interface{}{[]byte("command"), int64(1700000000), int64(250), int64(1000)},
+	})
+	connection.Command("LATENCY", "HISTORY", "command").Expect([]interface{}{
+		[]interface{}{int64(1600000000), int64(1000)},
+		[]interface{}{int64(1700000000), int64(250)},
+	})
+
+	client := mockClient(connection)
+
+	events, err := client.LatencyLatest()
+	assert.Equal(t, events, []LatencyEvent{{Name: "command", Time: time.Unix(1700000000, 0), Latest: 250 * time.Millisecond, Maximum: time.Second}})
+	assert.Nil(t, err)
+
+	samples, err := client.LatencyHistory("command")
+	assert.Equal(t, samples, []LatencySample{
+		{Time: time.Unix(1600000000, 0), Latency: time.Second},
+		{Time: time.Unix(1700000000, 0), Latency: 250 * time.Millisecond},
+	})
+	assert.Nil(t, err)
+}
+
+func TestClient_Memory(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("MEMORY", "USAGE", "key").Expect(int64(56))
+	connection.Command("MEMORY", "USAGE", "missing", "SAMPLES", 10).Expect(nil)
+	connection.Command("MEMORY", "STATS").Expect([]interface{}{
+		[]byte("peak.allocated"), int64(2048),
+		[]byte("total.allocated"), int64(1024),
+		[]byte("keys.count"), int64(10),
+		[]byte("dataset.percentage"), []byte("42.5"),
+		[]byte("fragmentation"), []byte("1.5"),
+		[]byte("db.0"), []interface{}{[]byte("overhead.hashtable.main"), int64(72)},
+	})
+	connection.Command("MEMORY", "DOCTOR").Expect("Sam, I detected a few issues")
+
+	client := mockClient(connection)
+
+	usage, found, err := client.MemoryUsage("key", 0)
+	assert.Equal(t, usage, int64(56))
+	assert.True(t, found)
+	assert.Nil(t, err)
+
+	usage, found, err = client.MemoryUsage("missing", 10)
+	assert.Equal(t, usage, int64(0))
+	assert.False(t, found)
+	assert.Nil(t, err)
+
+	stats, err := client.MemoryStats()
+	assert.Nil(t, err)
+	assert.Equal(t, stats.PeakAllocated, int64(2048))
+	assert.Equal(t, stats.TotalAllocated, int64(1024))
+	assert.Equal(t, stats.KeysCount, int64(10))
+	assert.Equal(t, stats.DatasetPercentage, 42.5)
+	assert.Equal(t, stats.Fragmentation, 1.5)
+	assert.NotNil(t, stats.Fields["db.0"])
+
+	report, err := client.MemoryDoctor()
+	assert.Equal(t, report, "Sam, I detected a few issues")
+	assert.Nil(t, err)
+}
+
+func TestClient_Server(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("DBSIZE").Expect(int64(10))
+	connection.Command("LASTSAVE").Expect(int64(1700000000))
+	connection.Command("BGSAVE").Expect("Background saving started")
+	connection.Command("BGREWRITEAOF").Expect("Background append only file rewriting started")
+	connection.Command("TIME").Expect([]interface{}{[]byte("1700000000"), []byte("250")})
+
+	client := mockClient(connection)
+
+	size, err := client.DBSize()
+	assert.Equal(t, size, int64(10))
+	assert.Nil(t, err)
+
+	lastSave, err := client.LastSave()
+	assert.Equal(t, lastSave, time.Unix(1700000000, 0))
+	assert.Nil(t, err)
+
+	assert.Nil(t, client.BgSave())
+	assert.Nil(t, client.BgRewriteAof())
+
+	serverTime, err := client.Time()
+	assert.Equal(t, serverTime, time.Unix(1700000000, 250000))
+	assert.Nil(t, err)
+}
+
+func TestClient_Role(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("ROLE").Expect([]interface{}{
+		[]byte("master"), int64(3129659),
+		[]interface{}{
+			[]interface{}{[]byte("127.0.0.1"), []byte("9001"), []byte("3129242")},
+			[]interface{}{[]byte("127.0.0.1"), []byte("9002"), []byte("3129543")},
+		},
+	})
+
+	client := mockClient(connection)
+
+	role, err := client.Role()
+	assert.Nil(t, err)
+	assert.Equal(t, role, &RoleInfo{
+		Role:              "master",
+		ReplicationOffset: 3129659,
+		Replicas: []RoleReplica{
+			{Address: "127.0.0.1:9001", ReplicationOffset: 3129242},
+			{Address: "127.0.0.1:9002", ReplicationOffset: 3129543},
+		},
+	})
+
+	connection = redigomock.NewConn()
+	connection.Command("ROLE").Expect([]interface{}{[]byte("slave"), []byte("127.0.0.1"), int64(9000), []byte("connected"), int64(3167038)})
+
+	client = mockClient(connection)
+
+	role, err = client.Role()
+	assert.Nil(t, err)
+	assert.Equal(t, role, &RoleInfo{Role: "slave", MasterAddress: "127.0.0.1:9000", State: "connected", ReplicationOffset: 3167038})
+
+	connection = redigomock.NewConn()
+	connection.Command("ROLE").Expect([]interface{}{[]byte("sentinel"), []interface{}{[]byte("resque-master"), []byte("html-fragments-master")}})
+
+	client = mockClient(connection)
+
+	role, err = client.Role()
+	assert.Nil(t, err)
+	assert.Equal(t, role, &RoleInfo{Role: "sentinel", MasterNames: []string{"resque-master", "html-fragments-master"}})
+}
+
+func TestIdentify(t *testing.T) {
+	connection := redigomock.NewConn()
+	assert.Nil(t, identify(connection, "", false))
+
+	connection = redigomock.NewConn()
+	setName := connection.Command("CLIENT", "SETNAME", "worker").Expect("OK")
+	assert.Nil(t, identify(connection, "worker", false))
+	assert.Equal(t, connection.Stats(setName), 1)
+
+	connection = redigomock.NewConn()
+	connection.Command("CLIENT", "SETNAME", "bad name").ExpectError(redis.Error("ERR Client names cannot contain spaces, newlines or special characters."))
+	assert.NotNil(t, identify(connection, "bad name", true))
+
+	connection = redigomock.NewConn()
+	setInfo := connection.Command("CLIENT", "SETINFO", "LIB-NAME", "xredis").ExpectError(redis.Error("ERR unknown subcommand 'SETINFO'"))
+	assert.Nil(t, identify(connection, "", true))
+	assert.Equal(t, connection.Stats(setInfo), 1)
+
+	connection = redigomock.NewConn()
+	connection.Command("CLIENT", "SETINFO", "LIB-NAME", "xredis").ExpectError(errors.New("connection reset"))
+	assert.NotNil(t, identify(connection, "", true))
+}
@@ -0,0 +1,220 @@
+package xredis
+
+import (
+	"errors"
+	"github.com/garyburd/redigo/redis"
+	"sort"
+)
+
+const (
+	mSetCommand   = "MSET"
+	mSetNxCommand = "MSETNX"
+
+	crossSlotError         = "keys must hash to the same slot"
+	unexpectedRepliesError = "unexpected number of replies"
+)
+
+// NullString is a value that may not exist
+type NullString struct {
+	Value string
+	Found bool
+}
+
+// MGet retrieves the keys' values in the order of the keys.
+// On a cluster, the keys are split by slot and retrieved in one round trip per node
+func (c *Client) MGet(keys ...string) ([]NullString, error) {
+	values, err := c.mGet(keys)
+	if err != nil {
+		return nil, err
+	}
+	return toNullStrings(values)
+}
+
+// MSet sets the key/value pairs. On a cluster, the pairs are split by slot and are not set atomically
+func (c *Client) MSet(values map[string]string) (bool, error) {
+	if len(values) == 0 {
+		return true, nil
+	}
+
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	if c.cluster == nil {
+		return toBool(connection.Do(mSetCommand, fromStringMap(values)...))
+	}
+
+	groups := groupBySlot(mapKeys(values))
+	for _, group := range groups {
+		args := make([]interface{}, 0, len(group.keys)*2)
+		for _, key := range group.keys {
+			args = append(args, key, values[key])
+		}
+
+		err := connection.Send(mSetCommand, args...)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	replies, err := receiveAll(connection, len(groups))
+	if err != nil {
+		return false, err
+	}
+
+	for _, reply := range replies {
+		ok, err := toBool(reply, nil)
+		if !ok || err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// MSetNx sets the key/value pairs only if none of the keys exist. On a cluster, the keys must hash to the same slot
+func (c *Client) MSetNx(values map[string]string) (bool, error) {
+	if len(values) == 0 {
+		return false, nil
+	}
+
+	if c.cluster != nil && len(groupBySlot(mapKeys(values))) > 1 {
+		return false, errors.New(crossSlotError)
+	}
+
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return toPositive(connection.Do(mSetNxCommand, fromStringMap(values)...))
+}
+
+// HMGet retrieves a key's fields' values in the order of the fields
+func (c *Client) HMGet(key string, fields ...string) ([]NullString, error) {
+	connection := c.getReadConnection()
+	defer connection.Close()
+
+	values, err := redis.Values(connection.Do(hMGetCommand, prepend(key, fields)...))
+	if err != nil {
+		return nil, err
+	}
+	return toNullStrings(values)
+}
+
+// HSetMulti sets a key's field/value pairs in one round trip and returns the number of fields added
+func (c *Client) HSetMulti(key string, values map[string]string) (int64, error) {
+	if len(values) == 0 {
+		return 0, nil
+	}
+
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return redis.Int64(connection.Do(hSetCommand, append([]interface{}{key}, fromStringMap(values)...)...))
+}
+
+// mGet retrieves the keys' raw values in the order of the keys, with one MGET per slot on a cluster
+func (c *Client) mGet(keys []string) ([]interface{}, error) {
+	if len(keys) == 0 {
+		return []interface{}{}, nil
+	}
+
+	connection := c.getReadConnection()
+	defer connection.Close()
+
+	if c.cluster == nil {
+		return redis.Values(connection.Do(mGetCommand, toInterfaces(keys)...))
+	}
+
+	groups := groupBySlot(keys)
+	for _, group := range groups {
+		err := connection.Send(mGetCommand, toInterfaces(group.keys)...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	replies, err := receiveAll(connection, len(groups))
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]interface{}, len(keys))
+	for i, group := range groups {
+		values, err := redis.Values(replies[i], nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(values) != len(group.indexes) {
+			return nil, errors.New(unexpectedRepliesError)
+		}
+
+		for j, index := range group.indexes {
+			results[index] = values[j]
+		}
+	}
+	return results, nil
+}
+
+type slotGroup struct {
+	keys    []string
+	indexes []int
+}
+
+// groupBySlot groups the keys by slot in the order the slots first appear
+func groupBySlot(keys []string) []*slotGroup {
+	var groups []*slotGroup
+	slots := map[int]*slotGroup{}
+	for index, key := range keys {
+		slot := keySlot(key)
+		group, ok := slots[slot]
+		if !ok {
+			group = &slotGroup{}
+			slots[slot] = group
+			groups = append(groups, group)
+		}
+
+		group.keys = append(group.keys, key)
+		group.indexes = append(group.indexes, index)
+	}
+	return groups
+}
+
+// receiveAll flushes the connection and reads the replies of the commands sent, failing on the first error
+func receiveAll(connection redis.Conn, count int) ([]interface{}, error) {
+	err := connection.Flush()
+	if err != nil {
+		return nil, err
+	}
+
+	replies := make([]interface{}, count)
+	var commandErr error
+	for i := range replies {
+		replies[i], err = connection.Receive()
+		if err != nil && !isCommandError(err) {
+			return nil, err
+		}
+		if err != nil && commandErr == nil {
+			commandErr = err
+		}
+	}
+	return replies, commandErr
+}
+
+func toNullStrings(values []interface{}) ([]NullString, error) {
+	results := make([]NullString, len(values))
+	for i, value := range values {
+		result, found, err := toString(value, nil)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = NullString{Value: result, Found: found}
+	}
+	return results, nil
+}
+
+func mapKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,149 @@
+package xredis
+
+import (
+	"errors"
+	"github.com/garyburd/redigo/redis"
+	"github.com/rafaeljusto/redigomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestClient_MGet(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("MGET", "a", "b").Expect([]interface{}{[]byte("1"), nil})
+
+	client := mockClient(connection)
+
+	values, err := client.MGet("a", "b")
+	assert.Equal(t, values, []NullString{{Value: "1", Found: true}, {}})
+	assert.Nil(t, err)
+
+	values, err = client.MGet()
+	assert.Equal(t, values, []NullString{})
+	assert.Nil(t, err)
+
+	connection = redigomock.NewConn()
+	connection.Command("MGET", "a").ExpectError(errors.New("Oops"))
+
+	client = mockClient(connection)
+
+	values, err = client.MGet("a")
+	assert.Nil(t, values)
+	assert.NotNil(t, err)
+}
+
+func TestClient_MGet_Cluster(t *testing.T) {
+	a := redigomock.NewConn()
+	a.Command("MGET", "bar", "{bar}2").Expect([]interface{}{[]byte("1"), nil})
+	b := redigomock.NewConn()
+	b.Command("MGET", "foo").Expect([]interface{}{[]byte("2")})
+
+	client := mockClusterClient(&ClusterOptions{}, map[string]*redigomock.Conn{"a:1": a, "b:2": b})
+
+	values, err := client.MGet("foo", "bar", "{bar}2")
+	assert.Equal(t, values, []NullString{{Value: "2", Found: true}, {Value: "1", Found: true}, {}})
+	assert.Nil(t, err)
+
+	bytes, err := client.MGetBytes("bar", "foo")
+	assert.NotNil(t, err)
+	assert.Nil(t, bytes)
+}
+
+func TestClient_MSet(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("MSET", "a", "1", "b", "2").Expect("OK")
+
+	client := mockClient(connection)
+
+	ok, err := client.MSet(map[string]string{"b": "2", "a": "1"})
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	ok, err = client.MSet(map[string]string{})
+	assert.True(t, ok)
+	assert.Nil(t, err)
+}
+
+func TestClient_MSet_Cluster(t *testing.T) {
+	a := redigomock.NewConn()
+	a.Command("MSET", "bar", "1", "{bar}2", "2").Expect("OK")
+	b := redigomock.NewConn()
+	b.Command("MSET", "foo", "3").Expect("OK")
+
+	client := mockClusterClient(&ClusterOptions{}, map[string]*redigomock.Conn{"a:1": a, "b:2": b})
+
+	ok, err := client.MSet(map[string]string{"foo": "3", "bar": "1", "{bar}2": "2"})
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	b.Command("MSET", "foo", "3").ExpectError(redis.Error("MOVED 12182 a:1"))
+
+	ok, err = client.MSet(map[string]string{"foo": "3"})
+	assert.False(t, ok)
+	assert.Equal(t, err, redis.Error("MOVED 12182 a:1"))
+	assert.Equal(t, client.cluster.masterAddress(12182), "a:1")
+}
+
+func TestClient_MSetNx(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("MSETNX", "a", "1", "b", "2").Expect(int64(1))
+
+	client := mockClient(connection)
+
+	ok, err := client.MSetNx(map[string]string{"b": "2", "a": "1"})
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	ok, err = client.MSetNx(map[string]string{})
+	assert.False(t, ok)
+	assert.Nil(t, err)
+}
+
+func TestClient_MSetNx_Cluster(t *testing.T) {
+	a := redigomock.NewConn()
+	a.Command("MSETNX", "bar", "1", "{bar}2", "2").Expect(int64(0))
+
+	client := mockClusterClient(&ClusterOptions{}, map[string]*redigomock.Conn{"a:1": a})
+
+	ok, err := client.MSetNx(map[string]string{"bar": "1", "{bar}2": "2"})
+	assert.False(t, ok)
+	assert.Nil(t, err)
+
+	ok, err = client.MSetNx(map[string]string{"foo": "1", "bar": "2"})
+	assert.False(t, ok)
+	assert.Equal(t, err, errors.New(crossSlotError))
+}
+
+func TestClient_HMGet(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("HMGET", "key", "a", "b").Expect([]interface{}{nil, []byte("2")})
+
+	client := mockClient(connection)
+
+	values, err := client.HMGet("key", "a", "b")
+	assert.Equal(t, values, []NullString{{}, {Value: "2", Found: true}})
+	assert.Nil(t, err)
+}
+
+func TestClient_HSetMulti(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("HSET", "key", "a", "1", "b", "2").Expect(int64(2))
+
+	client := mockClient(connection)
+
+	count, err := client.HSetMulti("key", map[string]string{"b": "2", "a": "1"})
+	assert.Equal(t, count, int64(2))
+	assert.Nil(t, err)
+
+	count, err = client.HSetMulti("key", nil)
+	assert.Equal(t, count, int64(0))
+	assert.Nil(t, err)
+}
+
+func TestGroupBySlot(t *testing.T) {
+	groups := groupBySlot([]string{"foo", "bar", "{foo}2", "{bar}2"})
+	assert.Equal(t, groups, []*slotGroup{
+		{keys: []string{"foo", "{foo}2"}, indexes: []int{0, 2}},
+		{keys: []string{"bar", "{bar}2"}, indexes: []int{1, 3}},
+	})
+}
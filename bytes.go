@@ -0,0 +1,159 @@
+package xredis
+
+import (
+	"errors"
+	"github.com/garyburd/redigo/redis"
+)
+
+const mGetCommand = "MGET"
+
+// GetBytes retrieves a key's value as is
+func (c *Client) GetBytes(key string) ([]byte, bool, error) {
+	connection := c.getReadConnection()
+	defer connection.Close()
+
+	return toBytes(connection.Do(getCommand, key))
+}
+
+// SetBytes sets a key/value pair
+func (c *Client) SetBytes(key string, value []byte) (bool, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return toBool(connection.Do(setCommand, key, value))
+}
+
+// SetNxBytes sets a key/value pair if the key does not exist
+func (c *Client) SetNxBytes(key string, value []byte) (bool, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return toBool(connection.Do(setCommand, key, value, notExistsOption))
+}
+
+// SetExBytes sets a key/value pair with a timeout in seconds
+func (c *Client) SetExBytes(key string, value []byte, timeout int64) (bool, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return toBool(connection.Do(setCommand, key, value, expireOption, timeout))
+}
+
+// AppendBytes appends to a key's value
+func (c *Client) AppendBytes(key string, value []byte) (int64, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return redis.Int64(connection.Do(appendCommand, key, value))
+}
+
+// MGetBytes retrieves the keys' values as is, the value of a key that does not exist is nil.
+// On a cluster, the keys are split by slot and retrieved in one round trip per node
+func (c *Client) MGetBytes(keys ...string) ([][]byte, error) {
+	return redis.ByteSlices(c.mGet(keys))
+}
+
+// HSetBytes sets a key's field/value pair
+func (c *Client) HSetBytes(key string, field string, value []byte) (bool, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return toPositive(connection.Do(hSetCommand, key, field, value))
+}
+
+// HGetBytes retrieves a key's field's value as is
+func (c *Client) HGetBytes(key string, field string) ([]byte, bool, error) {
+	connection := c.getReadConnection()
+	defer connection.Close()
+
+	return toBytes(connection.Do(hGetCommand, key, field))
+}
+
+// HGetAllBytes retrieves a key's fields and values as is
+func (c *Client) HGetAllBytes(key string) (map[string][]byte, error) {
+	connection := c.getReadConnection()
+	defer connection.Close()
+
+	return toBytesMap(connection.Do(hGetAllCommand, key))
+}
+
+// GetBytes queues retrieving a key's value as is
+func (p *Pipeline) GetBytes(key string) *BytesResult {
+	result := &BytesResult{}
+	p.queue(result, getCommand, key)
+	return result
+}
+
+// SetBytes queues setting a key/value pair
+func (p *Pipeline) SetBytes(key string, value []byte) *BoolResult {
+	return p.queueBool(toBool, setCommand, key, value)
+}
+
+// HGetBytes queues retrieving a key's field's value as is
+func (p *Pipeline) HGetBytes(key string, field string) *BytesResult {
+	result := &BytesResult{}
+	p.queue(result, hGetCommand, key, field)
+	return result
+}
+
+// HSetBytes queues setting a key's field/value pair
+func (p *Pipeline) HSetBytes(key string, field string, value []byte) *BoolResult {
+	return p.queueBool(toPositive, hSetCommand, key, field, value)
+}
+
+// BytesResult holds the reply of a pipelined command returning a byte slice
+type BytesResult struct {
+	value []byte
+	found bool
+	err   error
+}
+
+// Result returns the value, whether it exists and the command's error
+func (r *BytesResult) Result() ([]byte, bool, error) {
+	return r.value, r.found, r.err
+}
+
+// Err returns the command's error
+func (r *BytesResult) Err() error {
+	return r.err
+}
+
+func (r *BytesResult) set(reply interface{}, err error) {
+	r.value, r.found, r.err = toBytes(reply, err)
+}
+
+func toBytes(reply interface{}, err error) ([]byte, bool, error) {
+	result, e := redis.Bytes(reply, err)
+	if e == redis.ErrNil {
+		return nil, false, nil
+	}
+	if e != nil {
+		return nil, false, e
+	}
+	return result, true, nil
+}
+
+func toBytesMap(reply interface{}, err error) (map[string][]byte, error) {
+	values, err := redis.Values(reply, err)
+	if err != nil {
+		return nil, err
+	}
+	if len(values)%2 != 0 {
+		return nil, errors.New(oddRepliesError)
+	}
+
+	results := make(map[string][]byte, len(values)/2)
+	for i := 0; i < len(values); i += 2 {
+		field, err := redis.String(values[i], nil)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := redis.Bytes(values[i+1], nil)
+		if err != nil {
+			return nil, err
+		}
+		results[field] = value
+	}
+	return results, nil
+}
@@ -0,0 +1,166 @@
+package xredis
+
+import (
+	"errors"
+	"github.com/garyburd/redigo/redis"
+	"github.com/rafaeljusto/redigomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var binaryValue = []byte{0x00, 0xff, 0x10, 0x80}
+
+func TestClient_GetBytes(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("GET", "key").Expect(binaryValue)
+
+	client := mockClient(connection)
+
+	value, found, err := client.GetBytes("key")
+	assert.Equal(t, value, binaryValue)
+	assert.True(t, found)
+	assert.Nil(t, err)
+
+	connection = redigomock.NewConn()
+	connection.Command("GET", "key").Expect(nil)
+
+	client = mockClient(connection)
+
+	value, found, err = client.GetBytes("key")
+	assert.Nil(t, value)
+	assert.False(t, found)
+	assert.Nil(t, err)
+
+	connection = redigomock.NewConn()
+	connection.Command("GET", "key").ExpectError(errors.New("Oops"))
+
+	client = mockClient(connection)
+
+	value, found, err = client.GetBytes("key")
+	assert.Nil(t, value)
+	assert.False(t, found)
+	assert.NotNil(t, err)
+}
+
+func TestClient_SetBytes(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("SET", "key", binaryValue).Expect("OK")
+	connection.Command("SET", "key", binaryValue, "NX").Expect(nil)
+	connection.Command("SET", "key", binaryValue, "EX", int64(1)).Expect("OK")
+
+	client := mockClient(connection)
+
+	ok, err := client.SetBytes("key", binaryValue)
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	ok, err = client.SetNxBytes("key", binaryValue)
+	assert.False(t, ok)
+	assert.Nil(t, err)
+
+	ok, err = client.SetExBytes("key", binaryValue, 1)
+	assert.True(t, ok)
+	assert.Nil(t, err)
+}
+
+func TestClient_AppendBytes(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("APPEND", "key", binaryValue).Expect(int64(4))
+
+	client := mockClient(connection)
+
+	length, err := client.AppendBytes("key", binaryValue)
+	assert.Equal(t, length, int64(4))
+	assert.Nil(t, err)
+}
+
+func TestClient_MGetBytes(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("MGET", "a", "b", "c").Expect([]interface{}{binaryValue, nil, []byte{}})
+
+	client := mockClient(connection)
+
+	values, err := client.MGetBytes("a", "b", "c")
+	assert.Equal(t, values, [][]byte{binaryValue, nil, {}})
+	assert.Nil(t, err)
+}
+
+func TestClient_HSetBytes(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("HSET", "key", "field", binaryValue).Expect(int64(1))
+
+	client := mockClient(connection)
+
+	ok, err := client.HSetBytes("key", "field", binaryValue)
+	assert.True(t, ok)
+	assert.Nil(t, err)
+}
+
+func TestClient_HGetBytes(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("HGET", "key", "field").Expect(binaryValue)
+	connection.Command("HGET", "key", "other").Expect(nil)
+
+	client := mockClient(connection)
+
+	value, found, err := client.HGetBytes("key", "field")
+	assert.Equal(t, value, binaryValue)
+	assert.True(t, found)
+	assert.Nil(t, err)
+
+	value, found, err = client.HGetBytes("key", "other")
+	assert.Nil(t, value)
+	assert.False(t, found)
+	assert.Nil(t, err)
+}
+
+func TestClient_HGetAllBytes(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("HGETALL", "key").Expect([]interface{}{[]byte("field"), binaryValue})
+
+	client := mockClient(connection)
+
+	values, err := client.HGetAllBytes("key")
+	assert.Equal(t, values, map[string][]byte{"field": binaryValue})
+	assert.Nil(t, err)
+
+	connection = redigomock.NewConn()
+	connection.Command("HGETALL", "key").Expect([]interface{}{[]byte("field")})
+
+	client = mockClient(connection)
+
+	_, err = client.HGetAllBytes("key")
+	assert.NotNil(t, err)
+}
+
+func TestPipeline_Bytes(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("SET", "key", binaryValue).Expect("OK")
+	connection.Command("GET", "key").Expect(binaryValue)
+	connection.Command("HSET", "hash", "field", binaryValue).Expect(int64(1))
+	connection.Command("HGET", "hash", "field").ExpectError(redis.Error("WRONGTYPE"))
+
+	client := mockClient(connection)
+
+	pipeline := client.Pipeline()
+	set := pipeline.SetBytes("key", binaryValue)
+	get := pipeline.GetBytes("key")
+	hSet := pipeline.HSetBytes("hash", "field", binaryValue)
+	hGet := pipeline.HGetBytes("hash", "field")
+	assert.Nil(t, pipeline.Exec())
+
+	ok, err := set.Result()
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	value, found, err := get.Result()
+	assert.Equal(t, value, binaryValue)
+	assert.True(t, found)
+	assert.Nil(t, err)
+
+	ok, err = hSet.Result()
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	assert.Equal(t, hGet.Err(), redis.Error("WRONGTYPE"))
+}
@@ -0,0 +1,500 @@
+package xredis
+
+import (
+	"errors"
+	"github.com/garyburd/redigo/redis"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	clusterCommand   = "CLUSTER"
+	readOnlyCommand  = "READONLY"
+	askingCommand    = "ASKING"
+	shardsSubcommand = "SHARDS"
+	slotsSubcommand  = "SLOTS"
+
+	movedRedirect = "MOVED"
+	askRedirect   = "ASK"
+
+	slotsField     = "slots"
+	nodesField     = "nodes"
+	ipField        = "ip"
+	endpointField  = "endpoint"
+	portField      = "port"
+	tlsPortField   = "tls-port"
+	roleField      = "role"
+	healthField    = "health"
+	masterNodeRole = "master"
+	onlineHealth   = "online"
+
+	clusterSlotCount = 16384
+
+	invalidSlotsReplyError  = "invalid cluster slots reply"
+	clusterUnsupportedError = "not supported on a cluster"
+	noClusterNodesError     = "no cluster nodes"
+)
+
+// clusterNodes are the addresses of the master and replicas serving a range of slots
+type clusterNodes struct {
+	master   string
+	replicas []string
+}
+
+type clusterRange struct {
+	start int
+	end   int
+	nodes *clusterNodes
+}
+
+// cluster keeps the slots' topology and a connection pool per node
+type cluster struct {
+	options *ClusterOptions
+	dial    func(address string) (redis.Conn, error)
+	mutex   sync.RWMutex
+	slots   []*clusterNodes
+	masters []string
+	pools   map[string]*redis.Pool
+	refresh chan struct{}
+	done    chan struct{}
+	wait    sync.WaitGroup
+}
+
+func newCluster(options *ClusterOptions, dial func(address string) (redis.Conn, error)) *cluster {
+	return &cluster{
+		options: options,
+		dial:    dial,
+		slots:   make([]*clusterNodes, clusterSlotCount),
+		pools:   map[string]*redis.Pool{},
+		refresh: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+}
+
+// start loads the topology and keeps refreshing it periodically and after redirects
+func (c *cluster) start() error {
+	err := c.loadTopology()
+	if err != nil {
+		return err
+	}
+
+	c.wait.Add(1)
+	go func() {
+		defer c.wait.Done()
+
+		ticker := time.NewTicker(c.options.GetRefreshInterval())
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-c.done:
+				return
+			case <-ticker.C:
+			case <-c.refresh:
+			}
+			c.loadTopology()
+		}
+	}()
+	return nil
+}
+
+func (c *cluster) close() error {
+	select {
+	case <-c.done:
+	default:
+		close(c.done)
+	}
+	c.wait.Wait()
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var err error
+	for address, pool := range c.pools {
+		e := pool.Close()
+		if e != nil && err == nil {
+			err = e
+		}
+		delete(c.pools, address)
+	}
+	return err
+}
+
+// triggerRefresh asks for a topology refresh without waiting for it
+func (c *cluster) triggerRefresh() {
+	select {
+	case c.refresh <- struct{}{}:
+	default:
+	}
+}
+
+// loadTopology asks the known masters and then the configured nodes for the topology until one answers
+func (c *cluster) loadTopology() error {
+	c.mutex.RLock()
+	addresses := append([]string{}, c.masters...)
+	c.mutex.RUnlock()
+
+	err := errors.New(noClusterNodesError)
+	for _, address := range append(addresses, c.options.GetAddresses()...) {
+		var ranges []clusterRange
+		ranges, err = c.queryTopology(address)
+		if err == nil {
+			c.setRanges(ranges)
+			return nil
+		}
+	}
+	return err
+}
+
+func (c *cluster) queryTopology(address string) ([]clusterRange, error) {
+	connection, err := c.dial(address)
+	if err != nil {
+		return nil, err
+	}
+	defer connection.Close()
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := connection.Do(clusterCommand, shardsSubcommand)
+	if err == nil {
+		return parseClusterShards(reply, host)
+	}
+	if !isCommandError(err) {
+		return nil, err
+	}
+
+	// CLUSTER SHARDS is only available since Redis 7
+	reply, err = connection.Do(clusterCommand, slotsSubcommand)
+	if err != nil {
+		return nil, err
+	}
+	return parseClusterSlots(reply, host)
+}
+
+func (c *cluster) setRanges(ranges []clusterRange) {
+	slots := make([]*clusterNodes, clusterSlotCount)
+	addresses := map[string]bool{}
+	var masters []string
+
+	for _, slotRange := range ranges {
+		for slot := slotRange.start; slot <= slotRange.end && slot < clusterSlotCount; slot++ {
+			slots[slot] = slotRange.nodes
+		}
+
+		if !addresses[slotRange.nodes.master] {
+			masters = append(masters, slotRange.nodes.master)
+		}
+		addresses[slotRange.nodes.master] = true
+		for _, replica := range slotRange.nodes.replicas {
+			addresses[replica] = true
+		}
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.slots = slots
+	c.masters = masters
+	for address, pool := range c.pools {
+		if !addresses[address] {
+			pool.Close()
+			delete(c.pools, address)
+		}
+	}
+}
+
+// moved records a slot's new master reported by a MOVED redirect until the topology is refreshed
+func (c *cluster) moved(slot int, address string) {
+	c.mutex.Lock()
+	if slot >= 0 && slot < clusterSlotCount {
+		c.slots[slot] = &clusterNodes{master: address}
+	}
+	c.mutex.Unlock()
+
+	c.triggerRefresh()
+}
+
+func (c *cluster) pool(address string) *redis.Pool {
+	c.mutex.RLock()
+	pool, ok := c.pools[address]
+	c.mutex.RUnlock()
+	if ok {
+		return pool
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	pool, ok = c.pools[address]
+	if !ok {
+		pool = newClusterPool(c.options, func() (redis.Conn, error) {
+			return c.dial(address)
+		})
+		c.pools[address] = pool
+	}
+	return pool
+}
+
+func (c *cluster) masterAddress(slot int) string {
+	c.mutex.RLock()
+	nodes := c.slots[slot]
+	c.mutex.RUnlock()
+
+	if nodes == nil {
+		return c.anyMaster()
+	}
+	return nodes.master
+}
+
+func (c *cluster) replicaAddress(slot int) string {
+	c.mutex.RLock()
+	nodes := c.slots[slot]
+	c.mutex.RUnlock()
+
+	if nodes == nil {
+		return c.anyMaster()
+	}
+	if len(nodes.replicas) == 0 {
+		return nodes.master
+	}
+	return nodes.replicas[rand.Intn(len(nodes.replicas))]
+}
+
+func (c *cluster) masterAddresses() []string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	if len(c.masters) == 0 {
+		return append([]string{}, c.options.GetAddresses()...)
+	}
+	return append([]string{}, c.masters...)
+}
+
+func (c *cluster) anyMaster() string {
+	c.mutex.RLock()
+	masters := c.masters
+	c.mutex.RUnlock()
+
+	if len(masters) == 0 {
+		masters = c.options.GetAddresses()
+	}
+	return masters[rand.Intn(len(masters))]
+}
+
+func parseClusterSlots(reply interface{}, host string) ([]clusterRange, error) {
+	entries, err := redis.Values(reply, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ranges := make([]clusterRange, 0, len(entries))
+	for _, entry := range entries {
+		values, err := redis.Values(entry, nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(values) < 3 {
+			return nil, errors.New(invalidSlotsReplyError)
+		}
+
+		start, err := redis.Int(values[0], nil)
+		if err != nil {
+			return nil, err
+		}
+
+		end, err := redis.Int(values[1], nil)
+		if err != nil {
+			return nil, err
+		}
+
+		nodes := &clusterNodes{}
+		for i, value := range values[2:] {
+			address, err := parseSlotsNode(value, host)
+			if err != nil {
+				return nil, err
+			}
+
+			if i == 0 {
+				nodes.master = address
+			} else {
+				nodes.replicas = append(nodes.replicas, address)
+			}
+		}
+		ranges = append(ranges, clusterRange{start: start, end: end, nodes: nodes})
+	}
+	return ranges, nil
+}
+
+func parseSlotsNode(reply interface{}, host string) (string, error) {
+	values, err := redis.Values(reply, nil)
+	if err != nil {
+		return "", err
+	}
+	if len(values) < 2 {
+		return "", errors.New(invalidSlotsReplyError)
+	}
+
+	nodeHost, err := redis.String(values[0], nil)
+	if err != nil {
+		return "", err
+	}
+	if len(nodeHost) == 0 {
+		nodeHost = host
+	}
+
+	port, err := redis.Int(values[1], nil)
+	if err != nil {
+		return "", err
+	}
+	return net.JoinHostPort(nodeHost, strconv.Itoa(port)), nil
+}
+
+func parseClusterShards(reply interface{}, host string) ([]clusterRange, error) {
+	shards, err := redis.Values(reply, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var ranges []clusterRange
+	for _, shard := range shards {
+		fields, err := toFieldMap(shard, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		slots, err := redis.Ints(fields[slotsField], nil)
+		if err != nil && err != redis.ErrNil {
+			return nil, err
+		}
+		if len(slots)%2 != 0 {
+			return nil, errors.New(invalidSlotsReplyError)
+		}
+
+		nodes, err := parseShardNodes(fields[nodesField], host)
+		if err != nil {
+			return nil, err
+		}
+
+		// Shards without a master or without slots do not serve any keys
+		if len(nodes.master) == 0 {
+			continue
+		}
+
+		for i := 0; i < len(slots); i += 2 {
+			ranges = append(ranges, clusterRange{start: slots[i], end: slots[i+1], nodes: nodes})
+		}
+	}
+	return ranges, nil
+}
+
+func parseShardNodes(reply interface{}, host string) (*clusterNodes, error) {
+	values, err := redis.Values(reply, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := &clusterNodes{}
+	for _, value := range values {
+		fields, err := toFieldMap(value, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		node := toStringFields(fields)
+		if node[healthField] != onlineHealth {
+			continue
+		}
+
+		address := nodeAddress(node, host)
+		if node[roleField] == masterNodeRole {
+			nodes.master = address
+		} else {
+			nodes.replicas = append(nodes.replicas, address)
+		}
+	}
+	return nodes, nil
+}
+
+func nodeAddress(node map[string]string, host string) string {
+	nodeHost := node[endpointField]
+	if len(nodeHost) == 0 || nodeHost == "?" {
+		nodeHost = node[ipField]
+	}
+	if len(nodeHost) == 0 {
+		nodeHost = host
+	}
+
+	port := node[portField]
+	if len(port) == 0 {
+		port = node[tlsPortField]
+	}
+	return net.JoinHostPort(nodeHost, port)
+}
+
+func toStringFields(fields map[string]interface{}) map[string]string {
+	results := make(map[string]string, len(fields))
+	for name, value := range fields {
+		switch value := value.(type) {
+		case int64:
+			results[name] = strconv.FormatInt(value, 10)
+		case []byte:
+			results[name] = string(value)
+		case string:
+			results[name] = value
+		}
+	}
+	return results
+}
+
+// parseRedirect parses MOVED and ASK errors such as "MOVED 3999 127.0.0.1:6381"
+func parseRedirect(err error) (string, int, string, bool) {
+	e, ok := err.(redis.Error)
+	if !ok {
+		return "", 0, "", false
+	}
+
+	parts := strings.Fields(string(e))
+	if len(parts) != 3 || (parts[0] != movedRedirect && parts[0] != askRedirect) {
+		return "", 0, "", false
+	}
+
+	slot, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return "", 0, "", false
+	}
+	return parts[0], slot, parts[2], true
+}
+
+// keySlot returns the key's hash slot. Only the hash tag, the part between the first { and the next },
+// is hashed if it is not empty so that related keys can be stored on the same slot
+func keySlot(key string) int {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+	return int(crc16(key)) % clusterSlotCount
+}
+
+// crc16 implements CRC16-CCITT (XMODEM) used by redis cluster
+func crc16(key string) uint16 {
+	var crc uint16
+	for i := 0; i < len(key); i++ {
+		crc ^= uint16(key[i]) << 8
+		for bit := 0; bit < 8; bit++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
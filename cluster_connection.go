@@ -0,0 +1,343 @@
+package xredis
+
+import (
+	"fmt"
+	"github.com/garyburd/redigo/redis"
+	"strings"
+	"time"
+)
+
+const (
+	discardCommand = "DISCARD"
+
+	okReply = "OK"
+)
+
+// keylessCommands are the commands that do not have a key and run on the connection's current node
+var keylessCommands = map[string]bool{
+	pingCommand:         true,
+	echoCommand:         true,
+	infoCommand:         true,
+	flushDbCommand:      true,
+	flushAllCommand:     true,
+	scanCommand:         true,
+	keysCommand:         true,
+	multiCommand:        true,
+	execCommand:         true,
+	discardCommand:      true,
+	unwatchCommand:      true,
+	scriptCommand:       true,
+	clusterCommand:      true,
+	readOnlyCommand:     true,
+	askingCommand:       true,
+	randomKeyCommand:    true,
+	selectCommand:       true,
+	configCommand:       true,
+	clientCommand:       true,
+	slowLogCommand:      true,
+	latencyCommand:      true,
+	dbSizeCommand:       true,
+	lastSaveCommand:     true,
+	bgSaveCommand:       true,
+	bgRewriteAofCommand: true,
+	timeCommand:         true,
+	roleCommand:         true,
+}
+
+// clusterConnection routes each command to the node serving its key's slot and follows MOVED and ASK redirects.
+// Commands without a key run on the node of the previous command, and a transaction stays on the node it started on.
+// A transaction started before any command chose a node is held back until its first command, so that it starts
+// on the node of that command's key
+type clusterConnection struct {
+	client      *Client
+	cluster     *cluster
+	readOnly    bool
+	connections map[string]redis.Conn
+	address     string
+	multi       bool
+	heldMulti   bool
+	pending     []redis.Conn
+}
+
+func newClusterConnection(client *Client, readOnly bool) *clusterConnection {
+	return &clusterConnection{
+		client:      client,
+		cluster:     client.cluster,
+		readOnly:    readOnly,
+		connections: map[string]redis.Conn{},
+	}
+}
+
+func (c *clusterConnection) Do(commandName string, args ...interface{}) (interface{}, error) {
+	return c.do(func(connection redis.Conn, commandName string, args ...interface{}) (interface{}, error) {
+		return connection.Do(commandName, args...)
+	}, commandName, args...)
+}
+
+func (c *clusterConnection) DoWithTimeout(timeout time.Duration, commandName string, args ...interface{}) (interface{}, error) {
+	return c.do(func(connection redis.Conn, commandName string, args ...interface{}) (interface{}, error) {
+		return redis.DoWithTimeout(connection, timeout, commandName, args...)
+	}, commandName, args...)
+}
+
+func (c *clusterConnection) Send(commandName string, args ...interface{}) error {
+	if c.holdMulti(commandName) {
+		return nil
+	}
+
+	connection := c.connection(c.route(commandName, args))
+	if c.heldMulti {
+		c.heldMulti = false
+		err := connection.Send(multiCommand)
+		if err != nil {
+			return err
+		}
+		c.pending = append(c.pending, connection)
+	}
+
+	err := connection.Send(commandName, args...)
+	if err != nil {
+		return err
+	}
+
+	c.pending = append(c.pending, connection)
+	return nil
+}
+
+func (c *clusterConnection) Flush() error {
+	var err error
+	for _, connection := range c.connections {
+		e := connection.Flush()
+		if e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+func (c *clusterConnection) Receive() (interface{}, error) {
+	return c.redirected(c.next().Receive())
+}
+
+func (c *clusterConnection) ReceiveWithTimeout(timeout time.Duration) (interface{}, error) {
+	return c.redirected(redis.ReceiveWithTimeout(c.next(), timeout))
+}
+
+func (c *clusterConnection) Err() error {
+	for _, connection := range c.connections {
+		err := connection.Err()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *clusterConnection) Close() error {
+	var err error
+	for address, connection := range c.connections {
+		e := connection.Close()
+		if e != nil && err == nil {
+			err = e
+		}
+		delete(c.connections, address)
+	}
+	c.pending = nil
+	return err
+}
+
+func (c *clusterConnection) do(execute func(redis.Conn, string, ...interface{}) (interface{}, error), commandName string, args ...interface{}) (interface{}, error) {
+	if len(c.pending) > 0 || len(commandName) == 0 {
+		return c.flushPending(commandName, args...)
+	}
+	if c.holdMulti(commandName) {
+		return okReply, nil
+	}
+
+	address := c.route(commandName, args)
+	multi := c.heldMulti
+	c.heldMulti = false
+
+	asking := false
+	for redirects := 0; ; redirects++ {
+		connection := c.connection(address)
+		if asking {
+			err := connection.Send(askingCommand)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if multi {
+			err := connection.Send(multiCommand)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		reply, err := execute(connection, commandName, args...)
+		redirect, slot, target, ok := parseRedirect(err)
+		if ok && redirect == movedRedirect {
+			c.cluster.moved(slot, target)
+		}
+		if !ok || redirects >= c.cluster.options.GetMaxRedirects() {
+			return reply, err
+		}
+
+		if multi {
+			connection.Do(discardCommand)
+		}
+
+		asking = redirect == askRedirect
+		address = target
+		c.address = target
+	}
+}
+
+// flushPending sends the command, if any, and reads the pending replies like redigo does:
+// the last reply is returned along with the first command error
+func (c *clusterConnection) flushPending(commandName string, args ...interface{}) (interface{}, error) {
+	if len(commandName) > 0 {
+		err := c.Send(commandName, args...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := c.Flush()
+	if err != nil {
+		return nil, err
+	}
+
+	var reply interface{}
+	var commandErr error
+	for len(c.pending) > 0 {
+		var e error
+		reply, e = c.Receive()
+		if e != nil && !isCommandError(e) {
+			return nil, e
+		}
+		if e != nil && commandErr == nil {
+			commandErr = e
+		}
+	}
+	return reply, commandErr
+}
+
+// redirected records the new master of a slot moved while the command was pipelined, the command itself is not retried
+func (c *clusterConnection) redirected(reply interface{}, err error) (interface{}, error) {
+	redirect, slot, target, ok := parseRedirect(err)
+	if ok && redirect == movedRedirect {
+		c.cluster.moved(slot, target)
+	}
+	return reply, err
+}
+
+func (c *clusterConnection) next() redis.Conn {
+	if c.heldMulti {
+		c.heldMulti = false
+		connection := c.connection(c.current())
+		connection.Send(multiCommand)
+		connection.Flush()
+		return connection
+	}
+
+	if len(c.pending) == 0 {
+		return c.connection(c.current())
+	}
+
+	connection := c.pending[0]
+	c.pending = c.pending[1:]
+	return connection
+}
+
+// route returns the address of the node the command runs on
+func (c *clusterConnection) route(commandName string, args []interface{}) string {
+	name := strings.ToUpper(commandName)
+	switch name {
+	case multiCommand:
+		c.multi = true
+	case execCommand, discardCommand:
+		defer func() {
+			c.multi = false
+		}()
+	}
+
+	key, ok := commandKey(name, args)
+	if !ok || (c.multi && !c.heldMulti) {
+		return c.current()
+	}
+
+	slot := keySlot(key)
+	if c.readOnly {
+		c.address = c.cluster.replicaAddress(slot)
+	} else {
+		c.address = c.cluster.masterAddress(slot)
+	}
+	return c.address
+}
+
+// holdMulti holds back a MULTI sent before any command chose a node
+func (c *clusterConnection) holdMulti(commandName string) bool {
+	if len(c.address) > 0 || c.multi || strings.ToUpper(commandName) != multiCommand {
+		return false
+	}
+
+	c.multi = true
+	c.heldMulti = true
+	return true
+}
+
+func (c *clusterConnection) current() string {
+	if len(c.address) == 0 {
+		c.address = c.cluster.anyMaster()
+	}
+	return c.address
+}
+
+func (c *clusterConnection) connection(address string) redis.Conn {
+	connection, ok := c.connections[address]
+	if !ok {
+		connection = c.client.getConnection(c.cluster.pool(address))
+		c.connections[address] = connection
+	}
+	return connection
+}
+
+// commandKey returns the command's first key
+func commandKey(commandName string, args []interface{}) (string, bool) {
+	if keylessCommands[commandName] {
+		return "", false
+	}
+
+	index := 0
+	switch commandName {
+	case evalCommand, evalShaCommand:
+		if len(args) < 2 || fmt.Sprint(args[1]) == "0" {
+			return "", false
+		}
+		index = 2
+	case xReadCommand, xReadGroupCommand:
+		index = -1
+		for i, arg := range args {
+			if strings.ToUpper(fmt.Sprint(arg)) == streamsOption {
+				index = i + 1
+				break
+			}
+		}
+	case xGroupCommand, xInfoCommand, objectCommand, memoryCommand:
+		index = 1
+	}
+
+	if index < 0 || index >= len(args) {
+		return "", false
+	}
+
+	switch key := args[index].(type) {
+	case string:
+		return key, true
+	case []byte:
+		return string(key), true
+	default:
+		return fmt.Sprint(key), true
+	}
+}
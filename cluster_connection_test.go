@@ -0,0 +1,291 @@
+package xredis
+
+import (
+	"errors"
+	"github.com/garyburd/redigo/redis"
+	"github.com/rafaeljusto/redigomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// "bar" is stored on slot 5061 and "foo" on slot 12182
+func mockClusterClient(options *ClusterOptions, nodes map[string]*redigomock.Conn) *Client {
+	clusterDetails := newCluster(options, func(address string) (redis.Conn, error) {
+		connection, ok := nodes[address]
+		if !ok {
+			return nil, errors.New("unknown node " + address)
+		}
+		return connection, nil
+	})
+
+	clusterDetails.setRanges([]clusterRange{
+		{start: 0, end: 8191, nodes: &clusterNodes{master: "a:1", replicas: []string{"c:3"}}},
+		{start: 8192, end: 16383, nodes: &clusterNodes{master: "b:2"}},
+	})
+	return &Client{cluster: clusterDetails}
+}
+
+func TestClusterConnection_Routing(t *testing.T) {
+	a := redigomock.NewConn()
+	a.Command("SET", "bar", "1").Expect("OK")
+	b := redigomock.NewConn()
+	b.Command("SET", "foo", "2").Expect("OK")
+	b.Command("PING").Expect("PONG")
+	c := redigomock.NewConn()
+	c.Command("GET", "bar").Expect("1")
+
+	client := mockClusterClient(&ClusterOptions{ReadFromReplicas: true}, map[string]*redigomock.Conn{"a:1": a, "b:2": b, "c:3": c})
+
+	ok, err := client.Set("bar", "1")
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	ok, err = client.Set("foo", "2")
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	value, found, err := client.Get("bar")
+	assert.Equal(t, value, "1")
+	assert.True(t, found)
+	assert.Nil(t, err)
+
+	connection := client.GetConnection()
+	defer connection.Close()
+
+	connection.Do("SET", "foo", "2")
+	reply, err := redis.String(connection.Do("PING"))
+	assert.Equal(t, reply, "PONG")
+	assert.Nil(t, err)
+}
+
+func TestClusterConnection_Moved(t *testing.T) {
+	a := redigomock.NewConn()
+	a.Command("GET", "bar").ExpectError(redis.Error("MOVED 5061 b:2"))
+	b := redigomock.NewConn()
+	b.Command("GET", "bar").Expect("1")
+
+	client := mockClusterClient(&ClusterOptions{MaxRedirects: defaultClusterMaxRedirects}, map[string]*redigomock.Conn{"a:1": a, "b:2": b})
+
+	value, found, err := client.Get("bar")
+	assert.Equal(t, value, "1")
+	assert.True(t, found)
+	assert.Nil(t, err)
+	assert.Equal(t, client.cluster.masterAddress(5061), "b:2")
+	assert.Equal(t, len(client.cluster.refresh), 1)
+}
+
+func TestClusterConnection_Ask(t *testing.T) {
+	a := redigomock.NewConn()
+	a.Command("GET", "bar").ExpectError(redis.Error("ASK 5061 b:2"))
+	b := redigomock.NewConn()
+	asking := b.Command("ASKING").Expect("OK")
+	b.Command("GET", "bar").Expect("1")
+
+	client := mockClusterClient(&ClusterOptions{MaxRedirects: defaultClusterMaxRedirects}, map[string]*redigomock.Conn{"a:1": a, "b:2": b})
+
+	value, found, err := client.Get("bar")
+	assert.Equal(t, value, "1")
+	assert.True(t, found)
+	assert.Nil(t, err)
+	assert.Equal(t, b.Stats(asking), 1)
+	assert.Equal(t, client.cluster.masterAddress(5061), "a:1")
+	assert.Equal(t, len(client.cluster.refresh), 0)
+}
+
+func TestClusterConnection_MaxRedirects(t *testing.T) {
+	a := redigomock.NewConn()
+	redirected := a.Command("GET", "bar").ExpectError(redis.Error("MOVED 5061 a:1"))
+
+	client := mockClusterClient(&ClusterOptions{MaxRedirects: 2}, map[string]*redigomock.Conn{"a:1": a})
+
+	_, _, err := client.Get("bar")
+	assert.Equal(t, err, redis.Error("MOVED 5061 a:1"))
+	assert.Equal(t, a.Stats(redirected), 3)
+
+	b := redigomock.NewConn()
+	get := b.Command("GET", "bar").ExpectError(redis.Error("MOVED 5061 a:1"))
+
+	client = mockClusterClient(&ClusterOptions{}, map[string]*redigomock.Conn{"b:2": b})
+	client.cluster.setRanges([]clusterRange{{start: 0, end: 16383, nodes: &clusterNodes{master: "b:2"}}})
+
+	_, _, err = client.Get("bar")
+	assert.Equal(t, err, redis.Error("MOVED 5061 a:1"))
+	assert.Equal(t, b.Stats(get), 1)
+	assert.Equal(t, client.cluster.masterAddress(5061), "a:1")
+}
+
+func TestClusterConnection_Pipeline(t *testing.T) {
+	a := redigomock.NewConn()
+	a.Command("SET", "bar", "1").Expect("OK")
+	b := redigomock.NewConn()
+	b.Command("GET", "foo").Expect("2")
+
+	client := mockClusterClient(&ClusterOptions{}, map[string]*redigomock.Conn{"a:1": a, "b:2": b})
+
+	pipeline := client.Pipeline()
+	set := pipeline.Set("bar", "1")
+	get := pipeline.Get("foo")
+	assert.Nil(t, pipeline.Exec())
+
+	ok, err := set.Result()
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	value, found, err := get.Result()
+	assert.Equal(t, value, "2")
+	assert.True(t, found)
+	assert.Nil(t, err)
+}
+
+func TestClusterConnection_Transaction(t *testing.T) {
+	a := redigomock.NewConn()
+	a.Command("WATCH", "{bar}1").Expect("OK")
+	a.Command("MULTI").Expect("OK")
+	a.Command("SET", "{bar}2", "1").Expect("QUEUED")
+	a.Command("EXEC").Expect([]interface{}{"OK"})
+
+	client := mockClusterClient(&ClusterOptions{}, map[string]*redigomock.Conn{"a:1": a})
+
+	var result *BoolResult
+	err := client.Transaction(func(tx *Tx) error {
+		result = tx.Multi().Set("{bar}2", "1")
+		return nil
+	}, "{bar}1")
+	assert.Nil(t, err)
+
+	ok, err := result.Result()
+	assert.True(t, ok)
+	assert.Nil(t, err)
+}
+
+func TestClusterConnection_TransactionWithoutWatch(t *testing.T) {
+	b := redigomock.NewConn()
+	b.Command("MULTI").Expect("OK")
+	b.Command("SET", "foo", "2").Expect("QUEUED")
+	b.Command("EXEC").Expect([]interface{}{"OK"})
+
+	client := mockClusterClient(&ClusterOptions{}, map[string]*redigomock.Conn{"b:2": b})
+
+	var result *BoolResult
+	err := client.Transaction(func(tx *Tx) error {
+		result = tx.Multi().Set("foo", "2")
+		return nil
+	})
+	assert.Nil(t, err)
+
+	ok, err := result.Result()
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	b = redigomock.NewConn()
+	b.Command("MULTI").Expect("OK")
+	b.Command("SET", "foo", "2").Expect("QUEUED")
+	b.Command("EXEC").Expect([]interface{}{"OK"})
+
+	client = mockClusterClient(&ClusterOptions{}, map[string]*redigomock.Conn{"b:2": b})
+
+	connection := client.GetConnection()
+	defer connection.Close()
+
+	reply, err := connection.Do("MULTI")
+	assert.Equal(t, reply, "OK")
+	assert.Nil(t, err)
+
+	reply, err = connection.Do("SET", "foo", "2")
+	assert.Equal(t, reply, "QUEUED")
+	assert.Nil(t, err)
+
+	replies, err := redis.Values(connection.Do("EXEC"))
+	assert.Equal(t, replies, []interface{}{"OK"})
+	assert.Nil(t, err)
+}
+
+func TestClusterConnection_EveryMaster(t *testing.T) {
+	a := redigomock.NewConn()
+	a.Command("KEYS", "*").Expect([]interface{}{[]byte("bar")})
+	a.Command("DBSIZE").Expect(int64(1))
+	a.Command("FLUSHDB").Expect("OK")
+	a.Command("RANDOMKEY").Expect(nil)
+	a.Command("CONFIG", "SET", "maxmemory", "1gb").Expect("OK")
+	a.Command("CLIENT", "KILL", "USER", "app").Expect(int64(1))
+	b := redigomock.NewConn()
+	b.Command("KEYS", "*").Expect([]interface{}{[]byte("foo")})
+	b.Command("DBSIZE").Expect(int64(2))
+	b.Command("FLUSHDB").Expect("OK")
+	b.Command("RANDOMKEY").Expect([]byte("foo"))
+	b.Command("CONFIG", "SET", "maxmemory", "1gb").Expect("OK")
+	b.Command("CLIENT", "KILL", "USER", "app").Expect(int64(2))
+
+	client := mockClusterClient(&ClusterOptions{}, map[string]*redigomock.Conn{"a:1": a, "b:2": b})
+
+	keys, err := client.Keys("*")
+	assert.ElementsMatch(t, keys, []string{"bar", "foo"})
+	assert.Nil(t, err)
+
+	size, err := client.DBSize()
+	assert.Equal(t, size, int64(3))
+	assert.Nil(t, err)
+
+	key, found, err := client.RandomKey()
+	assert.Equal(t, key, "foo")
+	assert.True(t, found)
+	assert.Nil(t, err)
+
+	assert.Nil(t, client.FlushDb())
+	assert.Equal(t, a.Stats(a.Command("FLUSHDB")), 1)
+	assert.Equal(t, b.Stats(b.Command("FLUSHDB")), 1)
+
+	_, err = client.Info()
+	assert.Equal(t, err, errors.New(clusterUnsupportedError))
+
+	_, err = client.InfoSection()
+	assert.Equal(t, err, errors.New(clusterUnsupportedError))
+
+	assert.Nil(t, client.ConfigSet("maxmemory", "1gb"))
+	assert.Equal(t, a.Stats(a.Command("CONFIG", "SET", "maxmemory", "1gb")), 1)
+	assert.Equal(t, b.Stats(b.Command("CONFIG", "SET", "maxmemory", "1gb")), 1)
+
+	count, err := client.ClientKill(ClientKillOptions{User: "app"})
+	assert.Equal(t, count, int64(3))
+	assert.Nil(t, err)
+
+	_, err = client.ConfigGet("maxmemory")
+	assert.Equal(t, err, errors.New(clusterUnsupportedError))
+
+	_, err = client.ClientList()
+	assert.Equal(t, err, errors.New(clusterUnsupportedError))
+}
+
+func TestCommandKey(t *testing.T) {
+	key, ok := commandKey("GET", []interface{}{"foo"})
+	assert.Equal(t, key, "foo")
+	assert.True(t, ok)
+
+	_, ok = commandKey("PING", nil)
+	assert.False(t, ok)
+
+	key, ok = commandKey("EVALSHA", []interface{}{"sha", 1, []byte("foo")})
+	assert.Equal(t, key, "foo")
+	assert.True(t, ok)
+
+	_, ok = commandKey("EVAL", []interface{}{"return 1", 0})
+	assert.False(t, ok)
+
+	key, ok = commandKey("XREADGROUP", []interface{}{"GROUP", "group", "consumer", "STREAMS", "events", ">"})
+	assert.Equal(t, key, "events")
+	assert.True(t, ok)
+
+	key, ok = commandKey("XGROUP", []interface{}{"CREATE", "events", "group", "$"})
+	assert.Equal(t, key, "events")
+	assert.True(t, ok)
+
+	key, ok = commandKey("OBJECT", []interface{}{"ENCODING", "events"})
+	assert.Equal(t, key, "events")
+	assert.True(t, ok)
+
+	_, ok = commandKey("RANDOMKEY", nil)
+	assert.False(t, ok)
+
+	_, ok = commandKey("GET", nil)
+	assert.False(t, ok)
+}
@@ -0,0 +1,248 @@
+package xredis
+
+import (
+	"crypto/tls"
+	"github.com/garyburd/redigo/redis"
+	"time"
+)
+
+const (
+	defaultClusterAddress               = "localhost:7000"
+	defaultClusterPassword              = ""
+	defaultClusterNetwork               = "tcp"
+	defaultClusterConnectTimeout        = time.Second
+	defaultClusterWriteTimeout          = time.Second
+	defaultClusterReadTimeout           = time.Second
+	defaultClusterConnectionIdleTimeout = 240 * time.Second
+	defaultClusterConnectionMaxIdle     = 100
+	defaultClusterConnectionMaxActive   = 10000
+	defaultClusterTestOnBorrowTimeout   = time.Minute
+	defaultClusterRefreshInterval       = time.Minute
+	defaultClusterMaxRedirects          = 5
+)
+
+// ClusterOptions contains redis cluster options.
+// A negative MaxRedirects uses the default, while zero never follows MOVED and ASK redirects.
+// RefreshInterval uses the default unless positive
+type ClusterOptions struct {
+	Addresses             []string
+	Username              string
+	Password              string
+	Network               string
+	ConnectTimeout        time.Duration
+	WriteTimeout          time.Duration
+	ReadTimeout           time.Duration
+	ConnectionIdleTimeout time.Duration
+	ConnectionMaxIdle     int
+	ConnectionMaxActive   int
+	ConnectionWait        bool
+	TlsConfig             *tls.Config
+	TlsSkipVerify         bool
+	TestOnBorrowPeriod    time.Duration
+	ScriptRegistry        *ScriptRegistry
+	ReadFromReplicas      bool
+	RefreshInterval       time.Duration
+	MaxRedirects          int
+	ClientName            string
+	LibraryInfo           bool
+	CredentialsProvider   CredentialsProvider
+}
+
+// GetAddresses returns the addresses of the nodes the topology is discovered from
+func (o *ClusterOptions) GetAddresses() []string {
+	if len(o.Addresses) == 0 {
+		return []string{defaultClusterAddress}
+	}
+	return o.Addresses
+}
+
+// GetUsername returns the ACL username
+func (o *ClusterOptions) GetUsername() string {
+	return o.Username
+}
+
+// GetPassword returns password
+func (o *ClusterOptions) GetPassword() string {
+	if len(o.Password) == 0 {
+		return defaultClusterPassword
+	}
+	return o.Password
+}
+
+// GetNetwork returns network
+func (o *ClusterOptions) GetNetwork() string {
+	if len(o.Network) == 0 {
+		return defaultClusterNetwork
+	}
+	return o.Network
+}
+
+// GetConnectTimeout returns connect timeout
+func (o *ClusterOptions) GetConnectTimeout() time.Duration {
+	if o.ConnectTimeout < 0 {
+		return defaultClusterConnectTimeout
+	}
+	return o.ConnectTimeout
+}
+
+// GetWriteTimeout returns write timeout
+func (o *ClusterOptions) GetWriteTimeout() time.Duration {
+	if o.WriteTimeout < 0 {
+		return defaultClusterWriteTimeout
+	}
+	return o.WriteTimeout
+}
+
+// GetReadTimeout returns read timeout
+func (o *ClusterOptions) GetReadTimeout() time.Duration {
+	if o.ReadTimeout < 0 {
+		return defaultClusterReadTimeout
+	}
+	return o.ReadTimeout
+}
+
+// GetConnectionIdleTimeout returns connection idle timeout
+func (o *ClusterOptions) GetConnectionIdleTimeout() time.Duration {
+	if o.ConnectionIdleTimeout < 0 {
+		return defaultClusterConnectionIdleTimeout
+	}
+	return o.ConnectionIdleTimeout
+}
+
+// GetConnectionMaxIdle returns connection max idle
+func (o *ClusterOptions) GetConnectionMaxIdle() int {
+	if o.ConnectionMaxIdle < 0 {
+		return defaultClusterConnectionMaxIdle
+	}
+	return o.ConnectionMaxIdle
+}
+
+// GetConnectionMaxActive returns connection max active
+func (o *ClusterOptions) GetConnectionMaxActive() int {
+	if o.ConnectionMaxActive < 0 {
+		return defaultClusterConnectionMaxActive
+	}
+	return o.ConnectionMaxActive
+}
+
+// GetConnectionWait returns connection wait
+func (o *ClusterOptions) GetConnectionWait() bool {
+	return o.ConnectionWait
+}
+
+// GetTlsConfig returns tls config
+func (o *ClusterOptions) GetTlsConfig() *tls.Config {
+	return o.TlsConfig
+}
+
+// GetTlsSkipVerify returns tls skip verify
+func (o *ClusterOptions) GetTlsSkipVerify() bool {
+	return o.TlsSkipVerify
+}
+
+// GetTestOnBorrowPeriod return test on borrow period
+func (o *ClusterOptions) GetTestOnBorrowPeriod() time.Duration {
+	if o.TestOnBorrowPeriod < 0 {
+		return defaultClusterTestOnBorrowTimeout
+	}
+	return o.TestOnBorrowPeriod
+}
+
+// GetScriptRegistry returns the registry of scripts loaded on every new connection
+func (o *ClusterOptions) GetScriptRegistry() *ScriptRegistry {
+	return o.ScriptRegistry
+}
+
+// GetReadFromReplicas returns whether reads go to the replicas
+func (o *ClusterOptions) GetReadFromReplicas() bool {
+	return o.ReadFromReplicas
+}
+
+// GetRefreshInterval returns how often the topology is refreshed
+func (o *ClusterOptions) GetRefreshInterval() time.Duration {
+	if o.RefreshInterval <= 0 {
+		return defaultClusterRefreshInterval
+	}
+	return o.RefreshInterval
+}
+
+// GetMaxRedirects returns the maximum number of MOVED and ASK redirects followed per command
+func (o *ClusterOptions) GetMaxRedirects() int {
+	if o.MaxRedirects < 0 {
+		return defaultClusterMaxRedirects
+	}
+	return o.MaxRedirects
+}
+
+// GetClientName returns the name given to every new connection
+func (o *ClusterOptions) GetClientName() string {
+	return o.ClientName
+}
+
+// GetLibraryInfo returns whether new connections report the library's name and version
+func (o *ClusterOptions) GetLibraryInfo() bool {
+	return o.LibraryInfo
+}
+
+// GetCredentialsProvider returns the provider of every new connection's credentials, the username and password by default
+func (o *ClusterOptions) GetCredentialsProvider() CredentialsProvider {
+	if o.CredentialsProvider != nil {
+		return o.CredentialsProvider
+	}
+	return staticCredentials(o.GetUsername(), o.GetPassword())
+}
+
+func newClusterPool(options *ClusterOptions, dial func() (redis.Conn, error)) *redis.Pool {
+	connectionIdleTimeout := options.GetConnectionIdleTimeout()
+	connectionMaxActive := options.GetConnectionMaxActive()
+	connectionMaxIdle := options.GetConnectionMaxIdle()
+	connectionWait := options.GetConnectionWait()
+
+	return &redis.Pool{
+		IdleTimeout:  connectionIdleTimeout,
+		MaxActive:    connectionMaxActive,
+		MaxIdle:      connectionMaxIdle,
+		Wait:         connectionWait,
+		Dial:         interruptibleDial(dial),
+		TestOnBorrow: clusterTestOnBorrow(options),
+	}
+}
+
+func clusterDial(options *ClusterOptions) func(address string) (redis.Conn, error) {
+	network := options.GetNetwork()
+	setup := connectionSetup{
+		credentials:    options.GetCredentialsProvider(),
+		clientName:     options.GetClientName(),
+		libraryInfo:    options.GetLibraryInfo(),
+		readOnly:       options.GetReadFromReplicas(),
+		scriptRegistry: options.GetScriptRegistry(),
+	}
+
+	dialOptions := make([]redis.DialOption, 5)
+	dialOptions[0] = redis.DialConnectTimeout(options.GetConnectTimeout())
+	dialOptions[1] = redis.DialWriteTimeout(options.GetWriteTimeout())
+	dialOptions[2] = redis.DialReadTimeout(options.GetReadTimeout())
+	dialOptions[3] = redis.DialTLSSkipVerify(options.GetTlsSkipVerify())
+	dialOptions[4] = redis.DialTLSConfig(options.GetTlsConfig())
+
+	return func(address string) (redis.Conn, error) {
+		connection, err := redis.Dial(network, address, dialOptions...)
+		if err != nil {
+			return nil, err
+		}
+		return setup.prepare(connection)
+	}
+}
+
+func clusterTestOnBorrow(options *ClusterOptions) func(redis.Conn, time.Time) error {
+	period := options.GetTestOnBorrowPeriod()
+
+	return func(connection redis.Conn, t time.Time) error {
+		if time.Since(t) < period {
+			return nil
+		}
+
+		_, err := connection.Do(pingCommand)
+		return err
+	}
+}
@@ -0,0 +1,136 @@
+package xredis
+
+import (
+	"crypto/tls"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestClusterOptions_GetAddresses(t *testing.T) {
+	options := ClusterOptions{}
+	assert.Equal(t, options.GetAddresses(), []string{defaultClusterAddress})
+
+	options = ClusterOptions{Addresses: []string{"abc:1", "def:2"}}
+	assert.Equal(t, options.GetAddresses(), []string{"abc:1", "def:2"})
+}
+
+func TestClusterOptions_GetPassword(t *testing.T) {
+	options := ClusterOptions{}
+	assert.Equal(t, options.GetPassword(), defaultClusterPassword)
+
+	options = ClusterOptions{Password: "abc"}
+	assert.Equal(t, options.GetPassword(), "abc")
+}
+
+func TestClusterOptions_GetNetwork(t *testing.T) {
+	options := ClusterOptions{}
+	assert.Equal(t, options.GetNetwork(), defaultClusterNetwork)
+
+	options = ClusterOptions{Network: "abc"}
+	assert.Equal(t, options.GetNetwork(), "abc")
+}
+
+func TestClusterOptions_GetTimeouts(t *testing.T) {
+	options := ClusterOptions{ConnectTimeout: 1, WriteTimeout: 2, ReadTimeout: 3, ConnectionIdleTimeout: 4, TestOnBorrowPeriod: 5}
+	assert.Equal(t, options.GetConnectTimeout(), time.Duration(1))
+	assert.Equal(t, options.GetWriteTimeout(), time.Duration(2))
+	assert.Equal(t, options.GetReadTimeout(), time.Duration(3))
+	assert.Equal(t, options.GetConnectionIdleTimeout(), time.Duration(4))
+	assert.Equal(t, options.GetTestOnBorrowPeriod(), time.Duration(5))
+
+	options = ClusterOptions{ConnectTimeout: -1, WriteTimeout: -1, ReadTimeout: -1, ConnectionIdleTimeout: -1, TestOnBorrowPeriod: -1}
+	assert.Equal(t, options.GetConnectTimeout(), defaultClusterConnectTimeout)
+	assert.Equal(t, options.GetWriteTimeout(), defaultClusterWriteTimeout)
+	assert.Equal(t, options.GetReadTimeout(), defaultClusterReadTimeout)
+	assert.Equal(t, options.GetConnectionIdleTimeout(), defaultClusterConnectionIdleTimeout)
+	assert.Equal(t, options.GetTestOnBorrowPeriod(), defaultClusterTestOnBorrowTimeout)
+}
+
+func TestClusterOptions_GetConnections(t *testing.T) {
+	options := ClusterOptions{ConnectionMaxIdle: 1, ConnectionMaxActive: 2, ConnectionWait: true}
+	assert.Equal(t, options.GetConnectionMaxIdle(), 1)
+	assert.Equal(t, options.GetConnectionMaxActive(), 2)
+	assert.True(t, options.GetConnectionWait())
+
+	options = ClusterOptions{ConnectionMaxIdle: -1, ConnectionMaxActive: -1}
+	assert.Equal(t, options.GetConnectionMaxIdle(), defaultClusterConnectionMaxIdle)
+	assert.Equal(t, options.GetConnectionMaxActive(), defaultClusterConnectionMaxActive)
+	assert.False(t, options.GetConnectionWait())
+}
+
+func TestClusterOptions_GetTls(t *testing.T) {
+	options := ClusterOptions{}
+	assert.Nil(t, options.GetTlsConfig())
+	assert.False(t, options.GetTlsSkipVerify())
+
+	config := &tls.Config{}
+	options = ClusterOptions{TlsConfig: config, TlsSkipVerify: true}
+	assert.Equal(t, options.GetTlsConfig(), config)
+	assert.True(t, options.GetTlsSkipVerify())
+}
+
+func TestClusterOptions_GetScriptRegistry(t *testing.T) {
+	options := ClusterOptions{}
+	assert.Nil(t, options.GetScriptRegistry())
+
+	registry := NewScriptRegistry()
+	options = ClusterOptions{ScriptRegistry: registry}
+	assert.Equal(t, options.GetScriptRegistry(), registry)
+}
+
+func TestClusterOptions_GetReadFromReplicas(t *testing.T) {
+	options := ClusterOptions{}
+	assert.False(t, options.GetReadFromReplicas())
+
+	options = ClusterOptions{ReadFromReplicas: true}
+	assert.True(t, options.GetReadFromReplicas())
+}
+
+func TestClusterOptions_GetRefreshInterval(t *testing.T) {
+	options := ClusterOptions{}
+	assert.Equal(t, options.GetRefreshInterval(), defaultClusterRefreshInterval)
+
+	options = ClusterOptions{RefreshInterval: 1}
+	assert.Equal(t, options.GetRefreshInterval(), time.Duration(1))
+}
+
+func TestClusterOptions_GetMaxRedirects(t *testing.T) {
+	options := ClusterOptions{}
+	assert.Equal(t, options.GetMaxRedirects(), 0)
+
+	options = ClusterOptions{MaxRedirects: -1}
+	assert.Equal(t, options.GetMaxRedirects(), defaultClusterMaxRedirects)
+
+	options = ClusterOptions{MaxRedirects: 1}
+	assert.Equal(t, options.GetMaxRedirects(), 1)
+}
+
+func TestClusterOptions_GetClientName(t *testing.T) {
+	options := ClusterOptions{}
+	assert.Equal(t, options.GetClientName(), "")
+	assert.False(t, options.GetLibraryInfo())
+
+	options = ClusterOptions{ClientName: "worker", LibraryInfo: true}
+	assert.Equal(t, options.GetClientName(), "worker")
+	assert.True(t, options.GetLibraryInfo())
+}
+
+func TestClusterOptions_GetCredentialsProvider(t *testing.T) {
+	options := ClusterOptions{Username: "user", Password: "secret"}
+	assert.Equal(t, options.GetUsername(), "user")
+
+	username, password, err := options.GetCredentialsProvider()()
+	assert.Equal(t, username, "user")
+	assert.Equal(t, password, "secret")
+	assert.Nil(t, err)
+
+	options = ClusterOptions{CredentialsProvider: func() (string, string, error) {
+		return "rotated", "token", nil
+	}}
+
+	username, password, err = options.GetCredentialsProvider()()
+	assert.Equal(t, username, "rotated")
+	assert.Equal(t, password, "token")
+	assert.Nil(t, err)
+}
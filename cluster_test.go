@@ -0,0 +1,148 @@
+package xredis
+
+import (
+	"errors"
+	"github.com/garyburd/redigo/redis"
+	"github.com/rafaeljusto/redigomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCrc16(t *testing.T) {
+	assert.Equal(t, crc16("123456789"), uint16(0x31C3))
+	assert.Equal(t, crc16(""), uint16(0))
+}
+
+func TestKeySlot(t *testing.T) {
+	assert.Equal(t, keySlot("foo"), 12182)
+	assert.Equal(t, keySlot("{user1000}.following"), keySlot("user1000"))
+	assert.Equal(t, keySlot("{user1000}.followers"), keySlot("{user1000}.following"))
+	assert.Equal(t, keySlot("foo{}{bar}"), int(crc16("foo{}{bar}"))%clusterSlotCount)
+	assert.Equal(t, keySlot("foo{{bar}}zap"), keySlot("{bar"))
+	assert.Equal(t, keySlot("foo{bar}{zap}"), keySlot("bar"))
+}
+
+func TestParseRedirect(t *testing.T) {
+	redirect, slot, address, ok := parseRedirect(redis.Error("MOVED 3999 127.0.0.1:6381"))
+	assert.Equal(t, redirect, "MOVED")
+	assert.Equal(t, slot, 3999)
+	assert.Equal(t, address, "127.0.0.1:6381")
+	assert.True(t, ok)
+
+	redirect, slot, address, ok = parseRedirect(redis.Error("ASK 1 127.0.0.1:6382"))
+	assert.Equal(t, redirect, "ASK")
+	assert.Equal(t, slot, 1)
+	assert.Equal(t, address, "127.0.0.1:6382")
+	assert.True(t, ok)
+
+	_, _, _, ok = parseRedirect(redis.Error("ERR oops"))
+	assert.False(t, ok)
+
+	_, _, _, ok = parseRedirect(errors.New("MOVED 1 127.0.0.1:6381"))
+	assert.False(t, ok)
+
+	_, _, _, ok = parseRedirect(nil)
+	assert.False(t, ok)
+}
+
+func TestParseClusterSlots(t *testing.T) {
+	reply := []interface{}{
+		[]interface{}{int64(0), int64(8191),
+			[]interface{}{[]byte("10.0.0.1"), int64(7000), []byte("id1")},
+			[]interface{}{[]byte("10.0.0.2"), int64(7001), []byte("id2")},
+		},
+		[]interface{}{int64(8192), int64(16383),
+			[]interface{}{[]byte(""), int64(7002), []byte("id3")},
+		},
+	}
+
+	ranges, err := parseClusterSlots(reply, "10.0.0.9")
+	assert.Nil(t, err)
+	assert.Equal(t, ranges, []clusterRange{
+		{start: 0, end: 8191, nodes: &clusterNodes{master: "10.0.0.1:7000", replicas: []string{"10.0.0.2:7001"}}},
+		{start: 8192, end: 16383, nodes: &clusterNodes{master: "10.0.0.9:7002"}},
+	})
+
+	_, err = parseClusterSlots([]interface{}{[]interface{}{int64(0), int64(1)}}, "")
+	assert.NotNil(t, err)
+}
+
+func TestParseClusterShards(t *testing.T) {
+	reply := []interface{}{
+		[]interface{}{
+			[]byte("slots"), []interface{}{int64(0), int64(100), int64(200), int64(16383)},
+			[]byte("nodes"), []interface{}{
+				[]interface{}{[]byte("id"), []byte("id1"), []byte("port"), int64(7000), []byte("ip"), []byte("10.0.0.1"), []byte("endpoint"), []byte("node1"), []byte("role"), []byte("master"), []byte("health"), []byte("online")},
+				[]interface{}{[]byte("id"), []byte("id2"), []byte("port"), int64(7001), []byte("ip"), []byte("10.0.0.2"), []byte("endpoint"), []byte("?"), []byte("role"), []byte("replica"), []byte("health"), []byte("online")},
+				[]interface{}{[]byte("id"), []byte("id3"), []byte("port"), int64(7002), []byte("ip"), []byte("10.0.0.3"), []byte("role"), []byte("replica"), []byte("health"), []byte("loading")},
+			},
+		},
+		[]interface{}{
+			[]byte("slots"), []interface{}{},
+			[]byte("nodes"), []interface{}{
+				[]interface{}{[]byte("id"), []byte("id4"), []byte("tls-port"), int64(7003), []byte("role"), []byte("master"), []byte("health"), []byte("online")},
+			},
+		},
+	}
+
+	ranges, err := parseClusterShards(reply, "10.0.0.9")
+	assert.Nil(t, err)
+
+	nodes := &clusterNodes{master: "node1:7000", replicas: []string{"10.0.0.2:7001"}}
+	assert.Equal(t, ranges, []clusterRange{
+		{start: 0, end: 100, nodes: nodes},
+		{start: 200, end: 16383, nodes: nodes},
+	})
+}
+
+func TestCluster_Topology(t *testing.T) {
+	node := redigomock.NewConn()
+	node.Command("CLUSTER", "SHARDS").ExpectError(redis.Error("ERR unknown subcommand"))
+	node.Command("CLUSTER", "SLOTS").Expect([]interface{}{
+		[]interface{}{int64(0), int64(8191),
+			[]interface{}{[]byte("10.0.0.1"), int64(7000)},
+			[]interface{}{[]byte("10.0.0.2"), int64(7001)},
+		},
+		[]interface{}{int64(8192), int64(16383),
+			[]interface{}{[]byte("10.0.0.3"), int64(7002)},
+		},
+	})
+
+	clusterDetails := newCluster(&ClusterOptions{Addresses: []string{"10.0.0.1:7000"}}, func(address string) (redis.Conn, error) {
+		if address != "10.0.0.1:7000" {
+			return nil, errors.New("Oops")
+		}
+		return node, nil
+	})
+	assert.Equal(t, clusterDetails.anyMaster(), "10.0.0.1:7000")
+
+	assert.Nil(t, clusterDetails.loadTopology())
+	assert.Equal(t, clusterDetails.masters, []string{"10.0.0.1:7000", "10.0.0.3:7002"})
+	assert.Equal(t, clusterDetails.masterAddress(0), "10.0.0.1:7000")
+	assert.Equal(t, clusterDetails.replicaAddress(0), "10.0.0.2:7001")
+	assert.Equal(t, clusterDetails.masterAddress(16383), "10.0.0.3:7002")
+	assert.Equal(t, clusterDetails.replicaAddress(16383), "10.0.0.3:7002")
+
+	clusterDetails.moved(0, "10.0.0.4:7003")
+	assert.Equal(t, clusterDetails.masterAddress(0), "10.0.0.4:7003")
+	assert.Equal(t, len(clusterDetails.refresh), 1)
+
+	pool := clusterDetails.pool("10.0.0.4:7003")
+	assert.Equal(t, clusterDetails.pool("10.0.0.4:7003"), pool)
+
+	clusterDetails.setRanges([]clusterRange{{start: 0, end: 16383, nodes: &clusterNodes{master: "10.0.0.1:7000"}}})
+	assert.Equal(t, len(clusterDetails.pools), 0)
+	assert.Equal(t, clusterDetails.masterAddress(0), "10.0.0.1:7000")
+
+	assert.Nil(t, clusterDetails.close())
+	assert.Nil(t, clusterDetails.close())
+}
+
+func TestCluster_TopologyError(t *testing.T) {
+	clusterDetails := newCluster(&ClusterOptions{Addresses: []string{"10.0.0.1:7000"}}, func(address string) (redis.Conn, error) {
+		return nil, errors.New("Oops")
+	})
+
+	assert.Equal(t, clusterDetails.loadTopology(), errors.New("Oops"))
+	assert.Equal(t, clusterDetails.masterAddress(0), "10.0.0.1:7000")
+}
@@ -0,0 +1,132 @@
+package xredis
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"time"
+)
+
+// Codec encodes values to and decodes values from the bytes stored in redis
+type Codec interface {
+	Marshal(value interface{}) ([]byte, error)
+	Unmarshal(data []byte, value interface{}) error
+}
+
+// JSONCodec encodes values as JSON
+type JSONCodec struct{}
+
+// Marshal encodes the value as JSON
+func (JSONCodec) Marshal(value interface{}) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+// Unmarshal decodes the JSON data into the value
+func (JSONCodec) Unmarshal(data []byte, value interface{}) error {
+	return json.Unmarshal(data, value)
+}
+
+// GobCodec encodes values with encoding/gob
+type GobCodec struct{}
+
+// Marshal encodes the value with gob
+func (GobCodec) Marshal(value interface{}) ([]byte, error) {
+	var buffer bytes.Buffer
+	err := gob.NewEncoder(&buffer).Encode(value)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// Unmarshal decodes the gob data into the value
+func (GobCodec) Unmarshal(data []byte, value interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(value)
+}
+
+// WithCodec returns a shallow copy of the client that encodes typed values using the provided codec
+func (c *Client) WithCodec(codec Codec) *Client {
+	client := *c
+	client.codec = codec
+	return &client
+}
+
+// Codec returns the client's codec, JSON by default
+func (c *Client) Codec() Codec {
+	if c.codec == nil {
+		return JSONCodec{}
+	}
+	return c.codec
+}
+
+// GetAs retrieves a key's value decoded with the client's codec
+func GetAs[T any](client *Client, key string) (T, bool, error) {
+	data, found, err := client.GetBytes(key)
+	return decode[T](client.Codec(), data, found, err)
+}
+
+// SetAs sets a key's value encoded with the client's codec and expires it after the ttl if it is positive
+func SetAs[T any](client *Client, key string, value T, ttl time.Duration) (bool, error) {
+	data, err := client.Codec().Marshal(value)
+	if err != nil {
+		return false, err
+	}
+
+	if ttl <= 0 {
+		return client.SetBytes(key, data)
+	}
+
+	connection := client.getWriteConnection()
+	defer connection.Close()
+
+	args := append([]interface{}{key, data}, expirationArgs(ttl, time.Time{})...)
+	return toBool(connection.Do(setCommand, args...))
+}
+
+// HGetAs retrieves a key's field's value decoded with the client's codec
+func HGetAs[T any](client *Client, key string, field string) (T, bool, error) {
+	data, found, err := client.HGetBytes(key, field)
+	return decode[T](client.Codec(), data, found, err)
+}
+
+// HSetAs sets a key's field's value encoded with the client's codec
+func HSetAs[T any](client *Client, key string, field string, value T) (bool, error) {
+	data, err := client.Codec().Marshal(value)
+	if err != nil {
+		return false, err
+	}
+	return client.HSetBytes(key, field, data)
+}
+
+// HGetAllAs retrieves a key's fields and values decoded with the client's codec
+func HGetAllAs[T any](client *Client, key string) (map[string]T, error) {
+	values, err := client.HGetAllBytes(key)
+	if err != nil {
+		return nil, err
+	}
+
+	codec := client.Codec()
+	results := make(map[string]T, len(values))
+	for field, data := range values {
+		var value T
+		err = codec.Unmarshal(data, &value)
+		if err != nil {
+			return nil, err
+		}
+		results[field] = value
+	}
+	return results, nil
+}
+
+func decode[T any](codec Codec, data []byte, found bool, err error) (T, bool, error) {
+	var value T
+	if err != nil || !found {
+		return value, found, err
+	}
+
+	err = codec.Unmarshal(data, &value)
+	if err != nil {
+		return value, false, err
+	}
+	return value, true, nil
+}
@@ -0,0 +1,143 @@
+package xredis
+
+import (
+	"errors"
+	"github.com/rafaeljusto/redigomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type testUser struct {
+	Name string
+	Age  int
+}
+
+type failingCodec struct{}
+
+func (failingCodec) Marshal(value interface{}) ([]byte, error) {
+	return nil, errors.New("Oops")
+}
+
+func (failingCodec) Unmarshal(data []byte, value interface{}) error {
+	return errors.New("Oops")
+}
+
+func TestJSONCodec(t *testing.T) {
+	codec := JSONCodec{}
+
+	data, err := codec.Marshal(testUser{Name: "Raed", Age: 30})
+	assert.Equal(t, string(data), `{"Name":"Raed","Age":30}`)
+	assert.Nil(t, err)
+
+	var user testUser
+	assert.Nil(t, codec.Unmarshal(data, &user))
+	assert.Equal(t, user, testUser{Name: "Raed", Age: 30})
+}
+
+func TestGobCodec(t *testing.T) {
+	codec := GobCodec{}
+
+	data, err := codec.Marshal(testUser{Name: "Raed", Age: 30})
+	assert.Nil(t, err)
+
+	var user testUser
+	assert.Nil(t, codec.Unmarshal(data, &user))
+	assert.Equal(t, user, testUser{Name: "Raed", Age: 30})
+}
+
+func TestClient_WithCodec(t *testing.T) {
+	client := mockClient(redigomock.NewConn())
+	assert.Equal(t, client.Codec(), JSONCodec{})
+
+	gobClient := client.WithCodec(GobCodec{})
+	assert.Equal(t, gobClient.Codec(), GobCodec{})
+	assert.Equal(t, client.Codec(), JSONCodec{})
+}
+
+func TestGetAs(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("GET", "user").Expect([]byte(`{"Name":"Raed","Age":30}`))
+	connection.Command("GET", "missing").Expect(nil)
+	connection.Command("GET", "invalid").Expect([]byte(`{`))
+
+	client := mockClient(connection)
+
+	user, found, err := GetAs[testUser](client, "user")
+	assert.Equal(t, user, testUser{Name: "Raed", Age: 30})
+	assert.True(t, found)
+	assert.Nil(t, err)
+
+	user, found, err = GetAs[testUser](client, "missing")
+	assert.Equal(t, user, testUser{})
+	assert.False(t, found)
+	assert.Nil(t, err)
+
+	_, found, err = GetAs[testUser](client, "invalid")
+	assert.False(t, found)
+	assert.NotNil(t, err)
+}
+
+func TestSetAs(t *testing.T) {
+	data := []byte(`{"Name":"Raed","Age":30}`)
+
+	connection := redigomock.NewConn()
+	connection.Command("SET", "user", data).Expect("OK")
+	connection.Command("SET", "session", data, "PX", int64(1500)).Expect("OK")
+
+	client := mockClient(connection)
+
+	ok, err := SetAs(client, "user", testUser{Name: "Raed", Age: 30}, 0)
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	ok, err = SetAs(client, "session", testUser{Name: "Raed", Age: 30}, 1500*time.Millisecond)
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	ok, err = SetAs(client.WithCodec(failingCodec{}), "user", testUser{}, 0)
+	assert.False(t, ok)
+	assert.NotNil(t, err)
+}
+
+func TestHGetAs(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("HGET", "users", "1").Expect([]byte(`{"Name":"Raed","Age":30}`))
+
+	client := mockClient(connection)
+
+	user, found, err := HGetAs[testUser](client, "users", "1")
+	assert.Equal(t, user, testUser{Name: "Raed", Age: 30})
+	assert.True(t, found)
+	assert.Nil(t, err)
+}
+
+func TestHSetAs(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("HSET", "users", "1", []byte(`{"Name":"Raed","Age":30}`)).Expect(int64(1))
+
+	client := mockClient(connection)
+
+	ok, err := HSetAs(client, "users", "1", testUser{Name: "Raed", Age: 30})
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	ok, err = HSetAs(client.WithCodec(failingCodec{}), "users", "1", testUser{})
+	assert.False(t, ok)
+	assert.NotNil(t, err)
+}
+
+func TestHGetAllAs(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("HGETALL", "users").Expect([]interface{}{[]byte("1"), []byte(`{"Name":"Raed","Age":30}`)})
+
+	client := mockClient(connection)
+
+	users, err := HGetAllAs[testUser](client, "users")
+	assert.Equal(t, users, map[string]testUser{"1": {Name: "Raed", Age: 30}})
+	assert.Nil(t, err)
+
+	users, err = HGetAllAs[testUser](client.WithCodec(failingCodec{}), "users")
+	assert.Nil(t, users)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,192 @@
+package xredis
+
+import (
+	"context"
+	"github.com/garyburd/redigo/redis"
+	"time"
+)
+
+const interruptibleCommand = "XREDIS.INTERRUPTIBLE"
+
+type reply struct {
+	value interface{}
+	err   error
+}
+
+// contextConnection wraps a redis connection so that its calls honor a context.
+// If the connection was dialed by one of the pools the client built, it is closed as soon as the context is done
+// so that an abandoned call does not keep it busy
+type contextConnection struct {
+	redis.Conn
+	ctx           context.Context
+	interruptible *interruptibleConnection
+	pending       chan reply
+}
+
+// newContextConnection wraps the connection, looking up the interruptible connection behind it only if
+// its pool wraps every connection it dials, so that the lookup never reaches a server
+func newContextConnection(ctx context.Context, connection redis.Conn, interruptible bool) redis.Conn {
+	contextConnection := &contextConnection{Conn: connection, ctx: ctx}
+	if interruptible {
+		contextConnection.interruptible = interruptibleOf(connection)
+	}
+	return contextConnection
+}
+
+// Do sends a command and waits for its reply unless the context is done first
+func (c *contextConnection) Do(commandName string, args ...interface{}) (interface{}, error) {
+	return c.wait(func() (interface{}, error) {
+		return c.Conn.Do(commandName, args...)
+	})
+}
+
+// DoWithTimeout sends a command and waits for its reply using the read timeout unless the context is done first
+func (c *contextConnection) DoWithTimeout(timeout time.Duration, commandName string, args ...interface{}) (interface{}, error) {
+	return c.wait(func() (interface{}, error) {
+		return redis.DoWithTimeout(c.Conn, timeout, commandName, args...)
+	})
+}
+
+// Send writes a command to the connection's output buffer unless the context is done
+func (c *contextConnection) Send(commandName string, args ...interface{}) error {
+	if err := c.ctx.Err(); err != nil {
+		return err
+	}
+	return c.Conn.Send(commandName, args...)
+}
+
+// Flush flushes the connection's output buffer unless the context is done
+func (c *contextConnection) Flush() error {
+	if err := c.ctx.Err(); err != nil {
+		return err
+	}
+	return c.Conn.Flush()
+}
+
+// Receive waits for a single reply unless the context is done first
+func (c *contextConnection) Receive() (interface{}, error) {
+	return c.wait(c.Conn.Receive)
+}
+
+// ReceiveWithTimeout waits for a single reply using the read timeout unless the context is done first
+func (c *contextConnection) ReceiveWithTimeout(timeout time.Duration) (interface{}, error) {
+	return c.wait(func() (interface{}, error) {
+		return redis.ReceiveWithTimeout(c.Conn, timeout)
+	})
+}
+
+// Close closes the connection once any abandoned call has completed
+func (c *contextConnection) Close() error {
+	if c.pending == nil {
+		return c.Conn.Close()
+	}
+
+	pending := c.pending
+	c.pending = nil
+	go func() {
+		<-pending
+		c.Conn.Close()
+	}()
+	return nil
+}
+
+func (c *contextConnection) wait(call func() (interface{}, error)) (interface{}, error) {
+	if err := c.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if c.ctx.Done() == nil {
+		return call()
+	}
+
+	replies := make(chan reply, 1)
+	go func() {
+		value, err := call()
+		replies <- reply{value: value, err: err}
+	}()
+
+	select {
+	case result := <-replies:
+		return result.value, result.err
+	case <-c.ctx.Done():
+		c.interruptible.interrupt()
+		c.pending = replies
+		return nil, c.ctx.Err()
+	}
+}
+
+// interruptibleConnection wraps the connections dialed by the pools the client builds, which hide them behind
+// their own connections, so that a context connection can reach and close the connection while a call is in flight
+type interruptibleConnection struct {
+	redis.Conn
+}
+
+// interruptibleDial wraps the connections dialed
+func interruptibleDial(dial func() (redis.Conn, error)) func() (redis.Conn, error) {
+	if dial == nil {
+		return nil
+	}
+
+	return func() (redis.Conn, error) {
+		connection, err := dial()
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := connection.(*interruptibleConnection); ok {
+			return connection, nil
+		}
+		return &interruptibleConnection{Conn: connection}, nil
+	}
+}
+
+// interruptibleOf returns the interruptible connection behind a connection of a pool whose dial is wrapped
+func interruptibleOf(connection redis.Conn) *interruptibleConnection {
+	reply, err := connection.Do(interruptibleCommand)
+	if err != nil {
+		return nil
+	}
+
+	interruptible, _ := reply.(*interruptibleConnection)
+	return interruptible
+}
+
+// Do answers the lookup of the interruptible connection without sending it, and sends any other command
+func (c *interruptibleConnection) Do(commandName string, args ...interface{}) (interface{}, error) {
+	if commandName == interruptibleCommand {
+		return c, nil
+	}
+	return c.Conn.Do(commandName, args...)
+}
+
+func (c *interruptibleConnection) DoWithTimeout(timeout time.Duration, commandName string, args ...interface{}) (interface{}, error) {
+	if commandName == interruptibleCommand {
+		return c, nil
+	}
+	return redis.DoWithTimeout(c.Conn, timeout, commandName, args...)
+}
+
+func (c *interruptibleConnection) ReceiveWithTimeout(timeout time.Duration) (interface{}, error) {
+	return redis.ReceiveWithTimeout(c.Conn, timeout)
+}
+
+// interrupt closes the connection, which fails the call in flight. The connection then reports an error,
+// so its pool discards it
+func (c *interruptibleConnection) interrupt() {
+	if c == nil {
+		return
+	}
+	c.Conn.Close()
+}
+
+// errorConnection is returned when a connection could not be acquired
+type errorConnection struct {
+	err error
+}
+
+func (c errorConnection) Do(string, ...interface{}) (interface{}, error) { return nil, c.err }
+func (c errorConnection) Send(string, ...interface{}) error              { return c.err }
+func (c errorConnection) Err() error                                     { return c.err }
+func (c errorConnection) Close() error                                   { return nil }
+func (c errorConnection) Flush() error                                   { return c.err }
+func (c errorConnection) Receive() (interface{}, error)                  { return nil, c.err }
@@ -0,0 +1,164 @@
+package xredis
+
+import (
+	"context"
+	"errors"
+	"github.com/garyburd/redigo/redis"
+	"github.com/rafaeljusto/redigomock"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+type blockingConnection struct {
+	redis.Conn
+	release chan struct{}
+	closed  chan struct{}
+	once    sync.Once
+}
+
+func newBlockingConnection() *blockingConnection {
+	return &blockingConnection{release: make(chan struct{}), closed: make(chan struct{})}
+}
+
+func (c *blockingConnection) Do(commandName string, args ...interface{}) (interface{}, error) {
+	select {
+	case <-c.release:
+		return "OK", nil
+	case <-c.closed:
+		return nil, errors.New("use of closed network connection")
+	}
+}
+
+func (c *blockingConnection) Err() error {
+	select {
+	case <-c.closed:
+		return errors.New("use of closed network connection")
+	default:
+		return nil
+	}
+}
+
+func (c *blockingConnection) Close() error {
+	c.once.Do(func() { close(c.closed) })
+	return nil
+}
+
+func TestContextConnection_Do(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("PING").Expect("PONG")
+
+	contextConnection := newContextConnection(context.Background(), connection, false)
+
+	result, err := redis.String(contextConnection.Do("PING"))
+	assert.Equal(t, result, "PONG")
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	contextConnection = newContextConnection(ctx, connection, false)
+
+	_, err = contextConnection.Do("PING")
+	assert.Equal(t, err, context.Canceled)
+	assert.Equal(t, contextConnection.Send("PING"), context.Canceled)
+	assert.Equal(t, contextConnection.Flush(), context.Canceled)
+}
+
+func TestContextConnection_Cancel(t *testing.T) {
+	connection := newBlockingConnection()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	contextConnection := newContextConnection(ctx, connection, false)
+
+	go cancel()
+
+	_, err := contextConnection.Do("BLPOP", "key", 0)
+	assert.Equal(t, err, context.Canceled)
+	assert.Nil(t, contextConnection.Close())
+
+	select {
+	case <-connection.closed:
+		t.Fatal("connection closed while a call is still in flight")
+	default:
+	}
+
+	close(connection.release)
+	<-connection.closed
+}
+
+func TestContextConnection_Interrupt(t *testing.T) {
+	connection := newBlockingConnection()
+	pool := &redis.Pool{
+		Dial: interruptibleDial(func() (redis.Conn, error) {
+			return connection, nil
+		}),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	client := (&Client{writePool: pool, readPool: pool, interruptible: true}).WithContext(ctx)
+
+	pooled := client.GetConnection()
+	defer pooled.Close()
+
+	go cancel()
+
+	_, err := pooled.Do("BLPOP", "key", 0)
+	assert.Equal(t, err, context.Canceled)
+
+	select {
+	case <-connection.closed:
+	case <-time.After(time.Second):
+		t.Fatal("connection not closed when the context was done")
+	}
+}
+
+func TestInterruptibleDial(t *testing.T) {
+	connection := redigomock.NewConn()
+	dial := interruptibleDial(interruptibleDial(func() (redis.Conn, error) {
+		return connection, nil
+	}))
+
+	interruptible, err := dial()
+	assert.Equal(t, interruptible, &interruptibleConnection{Conn: connection})
+	assert.Nil(t, err)
+	assert.Equal(t, interruptibleOf(interruptible), interruptible)
+	assert.Nil(t, interruptibleDial(nil))
+}
+
+func TestNewClient_KeepsPool(t *testing.T) {
+	connection := redigomock.NewConn()
+	lookup := connection.Command(interruptibleCommand).Expect("OK")
+	connection.Command("PING").Expect("PONG")
+
+	pool := &redis.Pool{
+		Dial: func() (redis.Conn, error) {
+			return connection, nil
+		},
+	}
+	client := NewClient(pool).WithContext(context.Background())
+
+	dialed, err := pool.Dial()
+	assert.Equal(t, dialed, connection)
+	assert.Nil(t, err)
+
+	pong, err := client.Ping()
+	assert.Equal(t, pong, "PONG")
+	assert.Nil(t, err)
+	assert.Equal(t, connection.Stats(lookup), 0)
+}
+
+func TestErrorConnection(t *testing.T) {
+	oops := errors.New("Oops")
+	connection := errorConnection{err: oops}
+
+	_, err := connection.Do("PING")
+	assert.Equal(t, err, oops)
+	_, err = connection.Receive()
+	assert.Equal(t, err, oops)
+	assert.Equal(t, connection.Send("PING"), oops)
+	assert.Equal(t, connection.Flush(), oops)
+	assert.Equal(t, connection.Err(), oops)
+	assert.Nil(t, connection.Close())
+}
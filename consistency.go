@@ -0,0 +1,419 @@
+package xredis
+
+import (
+	"github.com/garyburd/redigo/redis"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	waitCommand = "WAIT"
+
+	defaultWaitReplicas = 1
+	defaultWaitTimeout  = time.Second
+)
+
+// Consistency determines what a session's reads see of its own writes
+type Consistency int
+
+const (
+	// EventualConsistency lets reads go to any replica, which may not have received the session's writes yet
+	EventualConsistency Consistency = iota
+	// MasterConsistency sends the session's reads to the master once the session has written
+	MasterConsistency
+	// OffsetConsistency records the master's replication offset after every write and sends the session's reads
+	// to a replica only if its offset caught up, or to the master otherwise
+	OffsetConsistency
+	// WaitConsistency waits for replicas to acknowledge every write with WAIT right after it and sends the session's reads
+	// to the master while a write is not acknowledged by enough replicas
+	WaitConsistency
+)
+
+// ConsistencyOptions determines how a session keeps its reads consistent with its writes.
+// WaitReplicas and WaitTimeout use the default unless positive, since zero would acknowledge writes no replica received
+// or wait indefinitely
+type ConsistencyOptions struct {
+	Consistency  Consistency
+	WaitReplicas int
+	WaitTimeout  time.Duration
+}
+
+// GetConsistency returns consistency
+func (o *ConsistencyOptions) GetConsistency() Consistency {
+	return o.Consistency
+}
+
+// GetWaitReplicas returns the number of replicas that must acknowledge a write
+func (o *ConsistencyOptions) GetWaitReplicas() int {
+	if o.WaitReplicas <= 0 {
+		return defaultWaitReplicas
+	}
+	return o.WaitReplicas
+}
+
+// GetWaitTimeout returns how long to wait for the replicas to acknowledge a write
+func (o *ConsistencyOptions) GetWaitTimeout() time.Duration {
+	if o.WaitTimeout <= 0 {
+		return defaultWaitTimeout
+	}
+	return o.WaitTimeout
+}
+
+// WithConsistency returns a shallow copy of the client that starts a session whose reads see its writes
+// according to the options. A session is meant to be short-lived, such as a request or a user's session
+func (c *Client) WithConsistency(options *ConsistencyOptions) *Client {
+	client := *c
+	client.session = nil
+	if options.GetConsistency() != EventualConsistency {
+		client.session = &session{options: options}
+	}
+	return &client
+}
+
+// ReadFromMaster returns a shallow copy of the client whose reads go to the master
+func (c *Client) ReadFromMaster() *Client {
+	client := *c
+	client.readFromMaster = true
+	return &client
+}
+
+// readOnlyCommands are the commands that do not modify the data set, so they do not count as a session's writes.
+// Any other command counts as a write, so it lists every read command the package sends and the ones callers may send
+// through GetConnection
+var readOnlyCommands = map[string]bool{
+	pingCommand:         true,
+	echoCommand:         true,
+	infoCommand:         true,
+	authCommand:         true,
+	selectCommand:       true,
+	clientCommand:       true,
+	configCommand:       true,
+	slowLogCommand:      true,
+	latencyCommand:      true,
+	memoryCommand:       true,
+	dbSizeCommand:       true,
+	lastSaveCommand:     true,
+	bgSaveCommand:       true,
+	bgRewriteAofCommand: true,
+	timeCommand:         true,
+	roleCommand:         true,
+	waitCommand:         true,
+	multiCommand:        true,
+	execCommand:         true,
+	discardCommand:      true,
+	watchCommand:        true,
+	unwatchCommand:      true,
+	scriptCommand:       true,
+	publishCommand:      true,
+	clusterCommand:      true,
+	readOnlyCommand:     true,
+	askingCommand:       true,
+	getCommand:          true,
+	mGetCommand:         true,
+	getRangeCommand:     true,
+	existsCommand:       true,
+	dumpCommand:         true,
+	keysCommand:         true,
+	randomKeyCommand:    true,
+	scanCommand:         true,
+	ttlCommand:          true,
+	pTTLCommand:         true,
+	typeCommand:         true,
+	objectCommand:       true,
+	touchCommand:        true,
+	hGetCommand:         true,
+	hMGetCommand:        true,
+	hGetAllCommand:      true,
+	hKeysCommand:        true,
+	hExistsCommand:      true,
+	hScanCommand:        true,
+	lIndexCommand:       true,
+	lLenCommand:         true,
+	lRangeCommand:       true,
+	sCardCommand:        true,
+	sDiffCommand:        true,
+	sInterCommand:       true,
+	sUnionCommand:       true,
+	sIsMemberCommand:    true,
+	sMIsMemberCommand:   true,
+	sMembersCommand:     true,
+	sRandMemberCommand:  true,
+	sScanCommand:        true,
+	zCardCommand:        true,
+	zCountCommand:       true,
+	zRangeCommand:       true,
+	zRankCommand:        true,
+	zRevRankCommand:     true,
+	zScoreCommand:       true,
+	zScanCommand:        true,
+	xInfoCommand:        true,
+	xLenCommand:         true,
+	xPendingCommand:     true,
+	xRangeCommand:       true,
+	xRevRangeCommand:    true,
+	xReadCommand:        true,
+
+	"STRLEN":               true,
+	"SUBSTR":               true,
+	"LCS":                  true,
+	"GETBIT":               true,
+	"BITCOUNT":             true,
+	"BITPOS":               true,
+	"BITFIELD_RO":          true,
+	"EXPIRETIME":           true,
+	"PEXPIRETIME":          true,
+	"SORT_RO":              true,
+	"EVAL_RO":              true,
+	"EVALSHA_RO":           true,
+	"FCALL_RO":             true,
+	"HLEN":                 true,
+	"HSTRLEN":              true,
+	"HVALS":                true,
+	"HRANDFIELD":           true,
+	"LPOS":                 true,
+	"SINTERCARD":           true,
+	"ZRANGEBYSCORE":        true,
+	"ZRANGEBYLEX":          true,
+	"ZREVRANGE":            true,
+	"ZREVRANGEBYSCORE":     true,
+	"ZREVRANGEBYLEX":       true,
+	"ZLEXCOUNT":            true,
+	"ZMSCORE":              true,
+	"ZRANDMEMBER":          true,
+	"ZDIFF":                true,
+	"ZINTER":               true,
+	"ZUNION":               true,
+	"ZINTERCARD":           true,
+	"GEOPOS":               true,
+	"GEODIST":              true,
+	"GEOHASH":              true,
+	"GEOSEARCH":            true,
+	"GEORADIUS_RO":         true,
+	"GEORADIUSBYMEMBER_RO": true,
+	"COMMAND":              true,
+	"READWRITE":            true,
+}
+
+// session tracks a client's writes to route its reads. Since replicas acknowledge the replication stream in order,
+// waiting for a write also acknowledges the writes that completed before it was sent
+type session struct {
+	options      *ConsistencyOptions
+	mutex        sync.Mutex
+	master       bool
+	offset       int64
+	completed    uint64
+	acknowledged uint64
+}
+
+// update sets whether reads must go to the master, which cannot be undone, and raises the offset reads must see
+func (s *session) update(master bool, offset int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.master = s.master || master
+	if offset > s.offset {
+		s.offset = offset
+	}
+}
+
+// completions returns the number of writes that completed
+func (s *session) completions() uint64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.completed
+}
+
+// complete counts writes whose replies were received and returns the number of writes that completed
+func (s *session) complete(writes int) uint64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.completed += uint64(writes)
+	return s.completed
+}
+
+// acknowledge records that the first completed writes were acknowledged by enough replicas
+func (s *session) acknowledge(writes uint64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if writes > s.acknowledged {
+		s.acknowledged = writes
+	}
+}
+
+// state returns whether reads must go to the master, which they must while a completed write is not acknowledged,
+// and the offset reads must see
+func (s *session) state() (bool, int64) {
+	if s == nil {
+		return false, 0
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.master || s.acknowledged < s.completed, s.offset
+}
+
+// caughtUp checks whether the server reached by a read connection has the replication offset
+func caughtUp(connection redis.Conn, offset int64) bool {
+	info, err := redis.String(connection.Do(infoCommand, replicationSection))
+	if err != nil {
+		return false
+	}
+
+	replication := ParseInfo(info).Replication
+	if replication.Role == masterRole {
+		return true
+	}
+	return replication.ReplicaReplOffset >= offset
+}
+
+// sessionConnection records the session's writes. Right after writes, once their replies are received and outside
+// of a transaction, it records the master's offset or waits for the replicas to acknowledge them.
+// Cluster connections can reach several masters, so their sessions read from the masters after writing
+type sessionConnection struct {
+	redis.Conn
+	session     *session
+	cluster     bool
+	multi       bool
+	outstanding int
+	written     int
+	preceding   uint64
+}
+
+func (c *sessionConnection) Do(commandName string, args ...interface{}) (interface{}, error) {
+	if err := c.Conn.Err(); err != nil {
+		return nil, err
+	}
+
+	c.track(commandName)
+	reply, err := c.Conn.Do(commandName, args...)
+	c.outstanding = 0
+	c.settle()
+	return reply, err
+}
+
+func (c *sessionConnection) DoWithTimeout(timeout time.Duration, commandName string, args ...interface{}) (interface{}, error) {
+	if err := c.Conn.Err(); err != nil {
+		return nil, err
+	}
+
+	c.track(commandName)
+	reply, err := redis.DoWithTimeout(c.Conn, timeout, commandName, args...)
+	c.outstanding = 0
+	c.settle()
+	return reply, err
+}
+
+func (c *sessionConnection) Send(commandName string, args ...interface{}) error {
+	c.track(commandName)
+	c.outstanding++
+	return c.Conn.Send(commandName, args...)
+}
+
+func (c *sessionConnection) Receive() (interface{}, error) {
+	reply, err := c.Conn.Receive()
+	c.received()
+	return reply, err
+}
+
+func (c *sessionConnection) ReceiveWithTimeout(timeout time.Duration) (interface{}, error) {
+	reply, err := redis.ReceiveWithTimeout(c.Conn, timeout)
+	c.received()
+	return reply, err
+}
+
+// Close counts the writes whose replies were not all received as not acknowledged
+func (c *sessionConnection) Close() error {
+	if c.written > 0 {
+		c.unsettled()
+	}
+	return c.Conn.Close()
+}
+
+func (c *sessionConnection) received() {
+	if c.outstanding > 0 {
+		c.outstanding--
+	}
+	c.settle()
+}
+
+// track follows transactions and records a write, sending the reads of master and cluster sessions to the master
+// right away
+func (c *sessionConnection) track(commandName string) {
+	name := strings.ToUpper(commandName)
+	switch name {
+	case multiCommand:
+		c.multi = true
+	case execCommand, discardCommand:
+		c.multi = false
+	}
+
+	if len(name) == 0 || readOnlyCommands[name] {
+		return
+	}
+
+	if c.cluster || c.session.options.GetConsistency() == MasterConsistency {
+		c.session.update(true, 0)
+		return
+	}
+
+	if c.written == 0 {
+		c.preceding = c.session.completions()
+	}
+	c.written++
+}
+
+// settle records the master's offset, or waits for the replicas to acknowledge the writes,
+// once no reply is outstanding and no transaction is open
+func (c *sessionConnection) settle() {
+	if c.written == 0 || c.outstanding > 0 || c.multi {
+		return
+	}
+	if c.Conn.Err() != nil {
+		c.unsettled()
+		return
+	}
+
+	written := c.written
+	c.written = 0
+
+	switch c.session.options.GetConsistency() {
+	case OffsetConsistency:
+		info, err := redis.String(c.Conn.Do(infoCommand, replicationSection))
+		if err != nil {
+			c.session.update(true, 0)
+			return
+		}
+		c.session.update(false, ParseInfo(info).Replication.MasterReplOffset)
+	case WaitConsistency:
+		completed := c.session.complete(written)
+		replicas := c.session.options.GetWaitReplicas()
+		timeout := c.session.options.GetWaitTimeout()
+
+		acknowledged, err := redis.Int(doBlocking(c.Conn, timeout, waitCommand, replicas, timeout.Milliseconds()))
+		if err != nil || acknowledged < replicas {
+			return
+		}
+
+		// Writes of other connections that completed while these were sent may not be acknowledged
+		if completed-uint64(written) == c.preceding {
+			c.session.acknowledge(completed)
+		} else {
+			c.session.acknowledge(c.preceding)
+		}
+	}
+}
+
+// unsettled makes sure writes that could not be settled are not taken for acknowledged
+func (c *sessionConnection) unsettled() {
+	if c.session.options.GetConsistency() == WaitConsistency {
+		c.session.complete(c.written)
+	} else {
+		c.session.update(true, 0)
+	}
+	c.written = 0
+}
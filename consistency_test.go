@@ -0,0 +1,355 @@
+package xredis
+
+import (
+	"context"
+	"github.com/garyburd/redigo/redis"
+	"github.com/rafaeljusto/redigomock"
+	"github.com/stretchr/testify/assert"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mockReplicatedClient(master *redigomock.Conn, replica *redigomock.Conn) *Client {
+	writePool := &redis.Pool{
+		Dial: func() (redis.Conn, error) {
+			return timeoutConnection{Conn: master}, nil
+		},
+	}
+	readPool := &redis.Pool{
+		Dial: func() (redis.Conn, error) {
+			return replica, nil
+		},
+	}
+	return &Client{writePool: writePool, readPool: readPool}
+}
+
+func mockReplicatedServers() (*redigomock.Conn, *redigomock.Conn) {
+	master := redigomock.NewConn()
+	master.Command("SET", "name", "Raed Shomali").Expect("OK")
+	master.Command("GET", "name").Expect("Raed Shomali")
+
+	replica := redigomock.NewConn()
+	replica.Command("GET", "name").Expect("Shomali")
+	return master, replica
+}
+
+func TestConsistencyOptions_GetConsistency(t *testing.T) {
+	options := ConsistencyOptions{}
+	assert.Equal(t, options.GetConsistency(), EventualConsistency)
+
+	options = ConsistencyOptions{Consistency: OffsetConsistency}
+	assert.Equal(t, options.GetConsistency(), OffsetConsistency)
+}
+
+func TestConsistencyOptions_GetWaitReplicas(t *testing.T) {
+	options := ConsistencyOptions{}
+	assert.Equal(t, options.GetWaitReplicas(), defaultWaitReplicas)
+
+	options = ConsistencyOptions{WaitReplicas: 2}
+	assert.Equal(t, options.GetWaitReplicas(), 2)
+}
+
+func TestConsistencyOptions_GetWaitTimeout(t *testing.T) {
+	options := ConsistencyOptions{}
+	assert.Equal(t, options.GetWaitTimeout(), defaultWaitTimeout)
+
+	options = ConsistencyOptions{WaitTimeout: 50 * time.Millisecond}
+	assert.Equal(t, options.GetWaitTimeout(), 50*time.Millisecond)
+}
+
+func TestClient_ReadFromMaster(t *testing.T) {
+	master, replica := mockReplicatedServers()
+	client := mockReplicatedClient(master, replica)
+
+	value, found, err := client.Get("name")
+	assert.Equal(t, value, "Shomali")
+	assert.True(t, found)
+	assert.Nil(t, err)
+
+	value, found, err = client.ReadFromMaster().Get("name")
+	assert.Equal(t, value, "Raed Shomali")
+	assert.True(t, found)
+	assert.Nil(t, err)
+}
+
+func TestClient_WithConsistency_Eventual(t *testing.T) {
+	master, replica := mockReplicatedServers()
+	client := mockReplicatedClient(master, replica).WithConsistency(&ConsistencyOptions{})
+	assert.Nil(t, client.session)
+
+	_, err := client.Set("name", "Raed Shomali")
+	assert.Nil(t, err)
+
+	value, _, _ := client.Get("name")
+	assert.Equal(t, value, "Shomali")
+}
+
+func TestClient_WithConsistency_Master(t *testing.T) {
+	master, replica := mockReplicatedServers()
+	client := mockReplicatedClient(master, replica)
+	session := client.WithConsistency(&ConsistencyOptions{Consistency: MasterConsistency})
+
+	value, _, _ := session.Get("name")
+	assert.Equal(t, value, "Shomali")
+
+	_, err := session.Set("name", "Raed Shomali")
+	assert.Nil(t, err)
+
+	value, _, _ = session.Get("name")
+	assert.Equal(t, value, "Raed Shomali")
+
+	value, _, _ = client.Get("name")
+	assert.Equal(t, value, "Shomali")
+
+	value, _, _ = client.WithConsistency(&ConsistencyOptions{Consistency: MasterConsistency}).Get("name")
+	assert.Equal(t, value, "Shomali")
+}
+
+func TestClient_WithConsistency_Offset(t *testing.T) {
+	master, replica := mockReplicatedServers()
+	master.Command("INFO", "replication").Expect("# Replication\r\nrole:master\r\nmaster_repl_offset:100\r\n")
+	replica.Command("INFO", "replication").Expect("# Replication\r\nrole:slave\r\nslave_repl_offset:90\r\n")
+
+	client := mockReplicatedClient(master, replica).WithConsistency(&ConsistencyOptions{Consistency: OffsetConsistency})
+
+	_, err := client.Set("name", "Raed Shomali")
+	assert.Nil(t, err)
+
+	value, _, _ := client.Get("name")
+	assert.Equal(t, value, "Raed Shomali")
+
+	replica.Command("INFO", "replication").Expect("# Replication\r\nrole:slave\r\nslave_repl_offset:100\r\n")
+
+	value, _, _ = client.Get("name")
+	assert.Equal(t, value, "Shomali")
+}
+
+func TestClient_WithConsistency_OffsetUnknown(t *testing.T) {
+	master, replica := mockReplicatedServers()
+	master.Command("INFO", "replication").ExpectError(redis.Error("ERR unknown command"))
+
+	client := mockReplicatedClient(master, replica).WithConsistency(&ConsistencyOptions{Consistency: OffsetConsistency})
+
+	_, err := client.Set("name", "Raed Shomali")
+	assert.Nil(t, err)
+
+	value, _, _ := client.Get("name")
+	assert.Equal(t, value, "Raed Shomali")
+}
+
+func TestClient_WithConsistency_Wait(t *testing.T) {
+	master, replica := mockReplicatedServers()
+	master.Command("WAIT", 2, int64(50)).Expect(int64(1))
+
+	options := &ConsistencyOptions{Consistency: WaitConsistency, WaitReplicas: 2, WaitTimeout: 50 * time.Millisecond}
+	client := mockReplicatedClient(master, replica).WithConsistency(options)
+
+	_, err := client.Set("name", "Raed Shomali")
+	assert.Nil(t, err)
+
+	value, _, _ := client.Get("name")
+	assert.Equal(t, value, "Raed Shomali")
+
+	master.Command("WAIT", 2, int64(50)).Expect(int64(2))
+
+	_, err = client.Set("name", "Raed Shomali")
+	assert.Nil(t, err)
+
+	value, _, _ = client.Get("name")
+	assert.Equal(t, value, "Shomali")
+}
+
+func TestClient_WithConsistency_Cluster(t *testing.T) {
+	a := redigomock.NewConn()
+	a.Command("SET", "bar", "1").Expect("OK")
+	a.Command("GET", "bar").Expect("1")
+	c := redigomock.NewConn()
+	c.Command("GET", "bar").Expect("0")
+
+	client := mockClusterClient(&ClusterOptions{ReadFromReplicas: true}, map[string]*redigomock.Conn{"a:1": a, "c:3": c})
+	session := client.WithConsistency(&ConsistencyOptions{Consistency: OffsetConsistency})
+
+	value, _, _ := session.Get("bar")
+	assert.Equal(t, value, "0")
+
+	value, _, _ = session.ReadFromMaster().Get("bar")
+	assert.Equal(t, value, "1")
+
+	_, err := session.Set("bar", "1")
+	assert.Nil(t, err)
+
+	value, _, _ = session.Get("bar")
+	assert.Equal(t, value, "1")
+
+	value, _, _ = client.Get("bar")
+	assert.Equal(t, value, "0")
+}
+
+func TestClient_WithConsistency_ReadsOnMaster(t *testing.T) {
+	master, replica := mockReplicatedServers()
+	master.Command("PING").Expect("PONG")
+
+	client := mockReplicatedClient(master, replica).WithConsistency(&ConsistencyOptions{Consistency: MasterConsistency})
+
+	_, err := client.Ping()
+	assert.Nil(t, err)
+
+	value, _, _ := client.Get("name")
+	assert.Equal(t, value, "Shomali")
+}
+
+func TestSessionConnection_WaitAfterWrite(t *testing.T) {
+	master := redigomock.NewConn()
+	master.Command("SET", "name", "Raed Shomali").Expect("OK")
+	master.Command("GET", "name").Expect("Raed Shomali")
+	master.Command("MULTI").Expect("OK")
+	master.Command("EXEC").Expect([]interface{}{"OK"})
+	wait := master.Command("WAIT", 1, int64(1000)).Expect(int64(1))
+
+	session := &session{options: &ConsistencyOptions{Consistency: WaitConsistency}}
+	connection := &sessionConnection{Conn: timeoutConnection{Conn: master}, session: session}
+
+	_, err := connection.Do("GET", "name")
+	assert.Nil(t, err)
+	assert.Equal(t, master.Stats(wait), 0)
+
+	_, err = connection.Do("SET", "name", "Raed Shomali")
+	assert.Nil(t, err)
+	assert.Equal(t, master.Stats(wait), 1)
+
+	assert.Nil(t, connection.Send("MULTI"))
+	assert.Nil(t, connection.Send("SET", "name", "Raed Shomali"))
+	assert.Nil(t, connection.Send("EXEC"))
+	assert.Nil(t, connection.Flush())
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, master.Stats(wait), 1)
+
+		_, err = connection.Receive()
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, master.Stats(wait), 2)
+	assert.Nil(t, connection.Close())
+
+	reads, _ := session.state()
+	assert.False(t, reads)
+}
+
+func TestSessionConnection_WaitInterleaved(t *testing.T) {
+	first := redigomock.NewConn()
+	first.Command("SET", "first", "1").Expect("OK")
+	first.Command("WAIT", 2, int64(50)).Expect(int64(1))
+
+	second := redigomock.NewConn()
+	second.Command("SET", "second", "2").Expect("OK")
+	second.Command("WAIT", 2, int64(50)).Expect(int64(2))
+
+	session := &session{options: &ConsistencyOptions{Consistency: WaitConsistency, WaitReplicas: 2, WaitTimeout: 50 * time.Millisecond}}
+	a := &sessionConnection{Conn: timeoutConnection{Conn: first}, session: session}
+	b := &sessionConnection{Conn: timeoutConnection{Conn: second}, session: session}
+
+	assert.Nil(t, a.Send("SET", "first", "1"))
+	assert.Nil(t, b.Send("SET", "second", "2"))
+
+	_, err := a.Receive()
+	assert.Nil(t, err)
+
+	_, err = b.Receive()
+	assert.Nil(t, err)
+
+	master, _ := session.state()
+	assert.True(t, master)
+
+	c := &sessionConnection{Conn: timeoutConnection{Conn: second}, session: session}
+	_, err = c.Do("SET", "second", "2")
+	assert.Nil(t, err)
+
+	master, _ = session.state()
+	assert.False(t, master)
+}
+
+func TestSessionConnection_DoWithTimeoutError(t *testing.T) {
+	master, replica := mockReplicatedServers()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	client := mockReplicatedClient(master, replica).WithContext(ctx).WithConsistency(&ConsistencyOptions{Consistency: WaitConsistency})
+
+	connection := client.getWriteConnection()
+	defer connection.Close()
+
+	_, err := redis.DoWithTimeout(connection, time.Second, "SET", "name", "Raed Shomali")
+	assert.Equal(t, err, context.Canceled)
+}
+
+func TestSessionConnection_DoError(t *testing.T) {
+	master, replica := mockReplicatedServers()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	client := mockReplicatedClient(master, replica).WithContext(ctx).WithConsistency(&ConsistencyOptions{Consistency: WaitConsistency})
+
+	connection := client.getWriteConnection()
+	_, err := connection.Do("SET", "name", "Raed Shomali")
+	assert.Equal(t, err, context.Canceled)
+	assert.Nil(t, connection.Close())
+
+	reads, _ := client.session.state()
+	assert.False(t, reads)
+}
+
+// TestReadOnlyCommands walks the commands the package defines, each of which must be listed as a read or a write
+func TestReadOnlyCommands(t *testing.T) {
+	writes := map[string]bool{
+		"APPEND": true, "BLMOVE": true, "BLPOP": true, "BRPOP": true, "BZPOPMAX": true, "BZPOPMIN": true, "COPY": true,
+		"DEL": true, "EVAL": true, "EVALSHA": true, "EXPIRE": true, "EXPIREAT": true, "FLUSHALL": true, "FLUSHDB": true,
+		"HDEL": true, "HINCRBY": true, "HINCRBYFLOAT": true, "HSET": true, "INCRBY": true, "INCRBYFLOAT": true,
+		"LINSERT": true, "LMOVE": true, "LPOP": true, "LPUSH": true, "LPUSHX": true, "LREM": true, "LSET": true,
+		"LTRIM": true, "MOVE": true, "MSET": true, "MSETNX": true, "PERSIST": true, "PEXPIRE": true, "PEXPIREAT": true,
+		"RENAME": true, "RENAMENX": true, "RESTORE": true, "RPOP": true, "RPUSH": true, "RPUSHX": true, "SADD": true,
+		"SDIFFSTORE": true, "SET": true, "SETRANGE": true, "SINTERSTORE": true, "SMOVE": true, "SPOP": true, "SREM": true,
+		"SUNIONSTORE": true, "UNLINK": true, "XACK": true, "XADD": true, "XAUTOCLAIM": true, "XCLAIM": true, "XDEL": true,
+		"XGROUP": true, "XREADGROUP": true, "ZADD": true, "ZINCRBY": true, "ZPOPMAX": true, "ZPOPMIN": true, "ZREM": true,
+		"ZREMRANGEBYLEX": true, "ZREMRANGEBYRANK": true, "ZREMRANGEBYSCORE": true,
+		interruptibleCommand: true,
+	}
+
+	files, err := filepath.Glob("*.go")
+	assert.Nil(t, err)
+
+	commands := 0
+	fileSet := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+
+		parsed, err := parser.ParseFile(fileSet, file, nil, 0)
+		assert.Nil(t, err)
+
+		for _, object := range parsed.Scope.Objects {
+			if object.Kind != ast.Con || !strings.HasSuffix(object.Name, "Command") {
+				continue
+			}
+
+			value, ok := object.Decl.(*ast.ValueSpec).Values[0].(*ast.BasicLit)
+			if !ok || value.Kind != token.STRING {
+				continue
+			}
+
+			name, err := strconv.Unquote(value.Value)
+			assert.Nil(t, err)
+			assert.True(t, readOnlyCommands[name] != writes[name], object.Name+" must be listed as either a read or a write")
+			commands++
+		}
+	}
+	assert.True(t, commands > len(writes))
+}
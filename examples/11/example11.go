@@ -0,0 +1,25 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/shomali11/xredis"
+	"time"
+)
+
+func main() {
+	client := xredis.DefaultClient()
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	contextClient := client.WithContext(ctx)
+
+	fmt.Println(contextClient.Set("name", "Raed Shomali"))
+	fmt.Println(contextClient.Get("name"))
+
+	cancel()
+
+	fmt.Println(contextClient.Get("name"))
+}
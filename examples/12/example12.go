@@ -0,0 +1,26 @@
+package main
+
+import (
+	"fmt"
+	"github.com/shomali11/xredis"
+)
+
+func main() {
+	client := xredis.DefaultClient()
+	defer client.Close()
+
+	pipeline := client.Pipeline()
+	set := pipeline.Set("name", "Raed Shomali")
+	get := pipeline.Get("name")
+	hSet := pipeline.HSet("hash", "sport", "Football")
+	incr := pipeline.Incr("counter")
+	del := pipeline.Del("name", "hash", "counter")
+
+	fmt.Println(pipeline.Exec())
+
+	fmt.Println(set.Result())
+	fmt.Println(get.Result())
+	fmt.Println(hSet.Result())
+	fmt.Println(incr.Result())
+	fmt.Println(del.Result())
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"github.com/shomali11/xredis"
+	"strconv"
+)
+
+func main() {
+	client := xredis.DefaultClient()
+	defer client.Close()
+
+	fmt.Println(client.Set("counter", "10"))
+
+	err := client.Transaction(func(tx *xredis.Tx) error {
+		value, _, err := tx.Get("counter")
+		if err != nil {
+			return err
+		}
+
+		counter, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+
+		tx.Multi().Set("counter", strconv.Itoa(counter*2))
+		return nil
+	}, "counter")
+
+	fmt.Println(err)
+	fmt.Println(client.Get("counter"))
+	fmt.Println(client.Del("counter"))
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"fmt"
+	"github.com/shomali11/xredis"
+	"time"
+)
+
+func main() {
+	client := xredis.DefaultClient()
+	defer client.Close()
+
+	fmt.Println(client.RPush("queue", "a", "b", "c"))
+	fmt.Println(client.LPush("queue", "z"))
+	fmt.Println(client.LRange("queue", 0, -1))
+	fmt.Println(client.LLen("queue"))
+	fmt.Println(client.LPop("queue"))
+	fmt.Println(client.RPop("queue"))
+	fmt.Println(client.LMove("queue", "processing", xredis.ListLeft, xredis.ListRight))
+	fmt.Println(client.BLPop(time.Second, "queue"))
+	fmt.Println(client.BLPop(time.Second, "queue"))
+	fmt.Println(client.Del("processing"))
+}
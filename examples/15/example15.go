@@ -0,0 +1,23 @@
+package main
+
+import (
+	"fmt"
+	"github.com/shomali11/xredis"
+)
+
+func main() {
+	client := xredis.DefaultClient()
+	defer client.Close()
+
+	fmt.Println(client.SAdd("tags:1", "go", "redis", "cache"))
+	fmt.Println(client.SAdd("tags:2", "go", "http"))
+	fmt.Println(client.SIsMember("tags:1", "go"))
+	fmt.Println(client.SMIsMember("tags:1", "go", "http"))
+	fmt.Println(client.SCard("tags:1"))
+	fmt.Println(client.SInter("tags:1", "tags:2"))
+	fmt.Println(client.SUnionStore("tags:all", "tags:1", "tags:2"))
+	fmt.Println(client.SDiff("tags:1", "tags:2"))
+	fmt.Println(client.SRem("tags:1", "cache"))
+	fmt.Println(client.SScan("tags:1", 0, "*"))
+	fmt.Println(client.Del("tags:1", "tags:2", "tags:all"))
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"fmt"
+	"github.com/shomali11/xredis"
+)
+
+func main() {
+	client := xredis.DefaultClient()
+	defer client.Close()
+
+	fmt.Println(client.ZAdd("board", xredis.ZMember{Member: "alice", Score: 10}, xredis.ZMember{Member: "bob", Score: 20}))
+	fmt.Println(client.ZAddWithOptions("board", xredis.ZAddOptions{GT: true, CH: true}, xredis.ZMember{Member: "alice", Score: 30}))
+	fmt.Println(client.ZIncrBy("board", 5, "bob"))
+	fmt.Println(client.ZScore("board", "alice"))
+	fmt.Println(client.ZRevRank("board", "alice"))
+	fmt.Println(client.ZRevRange("board", 0, 9))
+	fmt.Println(client.ZRangeByScore("board", "(10", "+inf", xredis.ZRangeOptions{Count: 1}))
+	fmt.Println(client.ZCount("board", "-inf", "+inf"))
+	fmt.Println(client.ZPopMin("board", 1))
+	fmt.Println(client.Del("board"))
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/shomali11/xredis"
+	"time"
+)
+
+func main() {
+	client := xredis.DefaultClient()
+	defer client.Close()
+
+	fmt.Println(client.XAdd("events", map[string]string{"type": "signup", "user": "1"}))
+	fmt.Println(client.XAddWithOptions("events", xredis.XAddOptions{MaxLen: 1000, Approximate: true}, map[string]string{"type": "login", "user": "1"}))
+	fmt.Println(client.XLen("events"))
+	fmt.Println(client.XRange("events", "-", "+", 10))
+
+	options := &xredis.StreamConsumerOptions{
+		Stream:        "events",
+		Group:         "workers",
+		Consumer:      "worker-1",
+		StartID:       "0",
+		Count:         10,
+		Block:         5 * time.Second,
+		MinIdle:       time.Minute,
+		ClaimInterval: 30 * time.Second,
+	}
+
+	consumer := client.NewStreamConsumer(options, func(message xredis.StreamMessage) error {
+		fmt.Println(message.ID, message.Values)
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	fmt.Println(consumer.Run(ctx))
+	fmt.Println(client.Del("events"))
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"fmt"
+	"github.com/shomali11/xredis"
+	"time"
+)
+
+func main() {
+	client := xredis.DefaultClient()
+	defer client.Close()
+
+	subscriber := client.NewSubscriber(&xredis.SubscriberOptions{})
+	defer subscriber.Close()
+
+	fmt.Println(subscriber.Subscribe("chat"))
+	fmt.Println(subscriber.PSubscribe("news.*"))
+
+	time.Sleep(100 * time.Millisecond)
+
+	fmt.Println(client.Publish("chat", "hello"))
+	fmt.Println(client.Publish("news.sport", "goal"))
+
+	fmt.Println(<-subscriber.Messages())
+	fmt.Println(<-subscriber.Messages())
+}
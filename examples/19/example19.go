@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"github.com/garyburd/redigo/redis"
+	"github.com/shomali11/xredis"
+)
+
+func main() {
+	registry := xredis.NewScriptRegistry()
+	transfer := registry.Register(`
+		local balance = tonumber(redis.call('GET', KEYS[1]) or '0')
+		local amount = tonumber(ARGV[1])
+		if balance < amount then
+			return 0
+		end
+		redis.call('DECRBY', KEYS[1], amount)
+		redis.call('INCRBY', KEYS[2], amount)
+		return 1
+	`)
+
+	client := xredis.SetupClient(&xredis.Options{ScriptRegistry: registry})
+	defer client.Close()
+
+	fmt.Println(client.Set("alice", "100"))
+	fmt.Println(redis.Int(transfer.Run(client, []string{"alice", "bob"}, 30)))
+	fmt.Println(redis.Int(transfer.Run(client, []string{"alice", "bob"}, 300)))
+	fmt.Println(client.Get("bob"))
+
+	fmt.Println(redis.String(client.Eval("return ARGV[1]", nil, "hello")))
+	fmt.Println(client.ScriptExists(transfer.Sha()))
+	fmt.Println(client.Del("alice", "bob"))
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"fmt"
+	"github.com/shomali11/xredis"
+)
+
+func main() {
+	options := &xredis.ClusterOptions{
+		Addresses:        []string{"localhost:7000", "localhost:7001", "localhost:7002"},
+		ReadFromReplicas: true,
+		MaxRedirects:     5,
+	}
+
+	client, err := xredis.SetupClusterClient(options)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer client.Close()
+
+	fmt.Println(client.Set("{user:1}:name", "Raed"))
+	fmt.Println(client.Set("{user:1}:city", "Seattle"))
+	fmt.Println(client.Get("{user:1}:name"))
+	fmt.Println(client.Del("{user:1}:name", "{user:1}:city"))
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"fmt"
+	"github.com/shomali11/xredis"
+)
+
+func main() {
+	client := xredis.DefaultClient()
+	defer client.Close()
+
+	payload := []byte{0x0a, 0x04, 0x52, 0x61, 0x65, 0x64}
+
+	fmt.Println(client.SetBytes("user:1", payload))
+	fmt.Println(client.GetBytes("user:1"))
+	fmt.Println(client.MGetBytes("user:1", "user:2"))
+
+	fmt.Println(client.HSetBytes("users", "1", payload))
+	fmt.Println(client.HGetBytes("users", "1"))
+	fmt.Println(client.HGetAllBytes("users"))
+
+	fmt.Println(client.Del("user:1", "users"))
+}
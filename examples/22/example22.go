@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+	"github.com/shomali11/xredis"
+	"time"
+)
+
+type User struct {
+	Name string
+	Age  int
+}
+
+func main() {
+	client := xredis.DefaultClient()
+	defer client.Close()
+
+	fmt.Println(xredis.SetAs(client, "user:1", User{Name: "Raed", Age: 30}, time.Hour))
+	fmt.Println(xredis.GetAs[User](client, "user:1"))
+
+	gobClient := client.WithCodec(xredis.GobCodec{})
+	fmt.Println(xredis.HSetAs(gobClient, "users", "1", User{Name: "Raed", Age: 30}))
+	fmt.Println(xredis.HGetAs[User](gobClient, "users", "1"))
+	fmt.Println(xredis.HGetAllAs[User](gobClient, "users"))
+
+	fmt.Println(client.Del("user:1", "users"))
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"github.com/shomali11/xredis"
+	"time"
+)
+
+type User struct {
+	Name      string            `redis:"name"`
+	Age       int               `redis:"age"`
+	Active    bool              `redis:"active"`
+	CreatedAt time.Time         `redis:"created_at"`
+	Settings  map[string]string `redis:"settings"`
+	Password  string            `redis:"-"`
+}
+
+func main() {
+	client := xredis.DefaultClient()
+	defer client.Close()
+
+	user := User{
+		Name:      "Raed",
+		Age:       30,
+		Active:    true,
+		CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Settings:  map[string]string{"theme": "dark"},
+	}
+
+	fmt.Println(client.HSetStruct("user:1", user))
+
+	var loaded User
+	fmt.Println(client.HGetStruct("user:1", &loaded))
+	fmt.Println(loaded)
+
+	var partial User
+	fmt.Println(client.HGetStruct("user:1", &partial, "name", "age"))
+	fmt.Println(partial)
+
+	fmt.Println(client.Del("user:1"))
+}
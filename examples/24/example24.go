@@ -0,0 +1,26 @@
+package main
+
+import (
+	"fmt"
+	"github.com/shomali11/xredis"
+	"time"
+)
+
+func main() {
+	client := xredis.DefaultClient()
+	defer client.Close()
+
+	fmt.Println(client.SetWithOptions("name", "Raed", xredis.SetOptions{TTL: 1500 * time.Millisecond}))
+	fmt.Println(client.SetWithOptions("name", "Shomali", xredis.SetOptions{XX: true, Get: true, KeepTTL: true}))
+	fmt.Println(client.SetWithOptions("name", "Raed", xredis.SetOptions{NX: true}))
+	fmt.Println(client.PTTL("name"))
+
+	fmt.Println(client.ExpireDuration("name", time.Minute))
+	fmt.Println(client.TTL("name"))
+	fmt.Println(client.ExpireAt("name", time.Now().Add(time.Hour)))
+	fmt.Println(client.Persist("name"))
+	fmt.Println(client.TTL("name"))
+
+	fmt.Println(client.Del("name"))
+	fmt.Println(client.TTL("name"))
+}
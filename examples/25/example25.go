@@ -0,0 +1,20 @@
+package main
+
+import (
+	"fmt"
+	"github.com/shomali11/xredis"
+)
+
+func main() {
+	client := xredis.DefaultClient()
+	defer client.Close()
+
+	fmt.Println(client.MSet(map[string]string{"a": "1", "b": "2"}))
+	fmt.Println(client.MSetNx(map[string]string{"b": "3", "c": "4"}))
+	fmt.Println(client.MGet("a", "b", "c"))
+
+	fmt.Println(client.HSetMulti("hash", map[string]string{"name": "Raed", "city": "Seattle"}))
+	fmt.Println(client.HMGet("hash", "name", "age"))
+
+	fmt.Println(client.Del("a", "b", "hash"))
+}
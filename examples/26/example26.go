@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"github.com/shomali11/xredis"
+)
+
+func main() {
+	client := xredis.DefaultClient()
+	defer client.Close()
+
+	client.Set("user:1", "Raed")
+	client.Set("user:2", "Shomali")
+	client.HSet("hash", "name", "Raed")
+	client.HSet("hash", "city", "Seattle")
+
+	iterator := client.ScanIterator(xredis.ScanOptions{Match: "user:*", Count: 100, Type: "string"})
+	for iterator.Next() {
+		fmt.Println(iterator.Key())
+	}
+	fmt.Println(iterator.Err())
+
+	hashIterator := client.HScanIterator("hash", xredis.ScanOptions{})
+	for field, value := range hashIterator.All() {
+		fmt.Println(field, value)
+	}
+	fmt.Println(hashIterator.Err())
+
+	fmt.Println(client.Del("user:1", "user:2", "hash"))
+}
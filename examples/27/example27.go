@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"github.com/garyburd/redigo/redis"
+	"github.com/shomali11/xredis"
+	"time"
+)
+
+func main() {
+	client := xredis.DefaultClient()
+	defer client.Close()
+
+	client.Set("name", "Raed")
+
+	fmt.Println(client.Type("name"))
+	fmt.Println(client.ObjectEncoding("name"))
+	fmt.Println(client.Rename("name", "first"))
+	fmt.Println(client.RenameNx("first", "first"))
+	fmt.Println(client.Copy("first", "second", false))
+	fmt.Println(client.Touch("first", "second", "third"))
+
+	dump, found, err := client.Dump("first")
+	fmt.Println(found, err)
+	fmt.Println(client.Restore("third", dump, xredis.RestoreOptions{TTL: time.Minute}))
+	fmt.Println(client.Get("third"))
+
+	fmt.Println(client.Move("second", 1))
+	fmt.Println(client.InDatabase(1, func(connection redis.Conn) error {
+		value, err := redis.String(connection.Do("GET", "second"))
+		fmt.Println(value)
+		return err
+	}))
+
+	fmt.Println(client.Unlink("first", "third"))
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"fmt"
+	"github.com/shomali11/xredis"
+)
+
+func main() {
+	client := xredis.DefaultClient()
+	defer client.Close()
+
+	info, err := client.InfoSection()
+	fmt.Println(err)
+
+	fmt.Println(info.Server.Version)
+	fmt.Println(info.Clients.Connected)
+	fmt.Println(info.Memory.MaxMemoryPolicy)
+	fmt.Println(info.Replication.Role)
+	fmt.Println(info.Keyspace[0].Keys)
+	fmt.Println(info.Fields["redis_git_sha1"])
+
+	memory, err := client.InfoSection("memory")
+	fmt.Println(memory.Memory.Used > 0, err)
+}
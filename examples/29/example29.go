@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"github.com/shomali11/xredis"
+	"time"
+)
+
+func main() {
+	client := xredis.DefaultClient()
+	defer client.Close()
+
+	fmt.Println(client.ConfigSet("slowlog-log-slower-than", "0"))
+	fmt.Println(client.ConfigGet("slowlog-*"))
+	fmt.Println(client.Set("name", "Raed"))
+
+	entries, err := client.SlowLogGet(1)
+	fmt.Println(entries[0].Args, err)
+
+	clients, err := client.ClientList()
+	fmt.Println(len(clients), err)
+
+	role, err := client.Role()
+	fmt.Println(role.Role, err)
+
+	fmt.Println(client.DBSize())
+	fmt.Println(client.MemoryUsage("name", 0))
+	fmt.Println(client.LatencyLatest())
+
+	serverTime, err := client.Time()
+	fmt.Println(time.Since(serverTime) < time.Minute, err)
+
+	fmt.Println(client.SlowLogReset())
+	fmt.Println(client.ConfigResetStat())
+}
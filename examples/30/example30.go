@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+	"github.com/shomali11/xredis"
+)
+
+func main() {
+	options := &xredis.SentinelOptions{
+		Addresses:             []string{"localhost:26379"},
+		MasterName:            "master",
+		ClientName:            "billing",
+		WriteClientNameSuffix: ":write",
+		ReadClientNameSuffix:  ":read",
+		LibraryInfo:           true,
+	}
+
+	client := xredis.SetupSentinelClient(options)
+	defer client.Close()
+
+	fmt.Println(client.ClientGetName())
+
+	clients, err := client.ClientList()
+	fmt.Println(err)
+
+	for _, info := range clients {
+		fmt.Println(info.Name, info.Fields["lib-name"])
+	}
+}
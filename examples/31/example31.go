@@ -0,0 +1,25 @@
+package main
+
+import (
+	"fmt"
+	"github.com/shomali11/xredis"
+	"time"
+)
+
+func main() {
+	options := &xredis.SentinelOptions{
+		Addresses:  []string{"localhost:26379", "localhost:26380", "localhost:26381"},
+		MasterName: "master",
+	}
+
+	client := xredis.SetupSentinelClient(options)
+	defer client.Close()
+
+	fmt.Println(client.Set("name", "Raed"))
+
+	// SENTINEL FAILOVER master is run meanwhile
+	time.Sleep(10 * time.Second)
+
+	fmt.Println(client.Set("name", "Shomali"))
+	fmt.Println(client.Get("name"))
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"github.com/shomali11/xredis"
+)
+
+func main() {
+	options := &xredis.SentinelOptions{
+		Addresses:         []string{"localhost:26379"},
+		MasterName:        "master",
+		Password:          "server-secret",
+		SentinelUsername:  "sentinel-user",
+		SentinelPassword:  "sentinel-secret",
+		SentinelTlsConfig: &tls.Config{ServerName: "sentinel.local"},
+	}
+
+	client := xredis.SetupSentinelClient(options)
+	defer client.Close()
+
+	fmt.Println(client.Ping())
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"github.com/shomali11/xredis"
+)
+
+func main() {
+	options := &xredis.Options{
+		Host:     "localhost",
+		Port:     6379,
+		Username: "app",
+		Password: "secret",
+	}
+
+	client := xredis.SetupClient(options)
+	defer client.Close()
+
+	fmt.Println(client.Ping())
+
+	options = &xredis.Options{
+		Host: "localhost",
+		Port: 6379,
+		CredentialsProvider: func() (string, string, error) {
+			return "app", "rotated-secret", nil
+		},
+	}
+
+	rotatingClient := xredis.SetupClient(options)
+	defer rotatingClient.Close()
+
+	fmt.Println(rotatingClient.Ping())
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"github.com/shomali11/xredis"
+	"time"
+)
+
+func main() {
+	options := &xredis.SentinelOptions{
+		Addresses:  []string{"localhost:26379"},
+		MasterName: "master",
+		ReplicaSelector: xredis.HealthySelector{
+			MaxLag:       5 * time.Second,
+			MaxOffsetLag: 1024 * 1024,
+			Next: xredis.SameZoneSelector{
+				Zone: "us-east-1a",
+				Zones: map[string]string{
+					"10.0.0.2:6379": "us-east-1a",
+					"10.0.1.2:6379": "us-east-1b",
+				},
+				Next: &xredis.RoundRobinSelector{},
+			},
+		},
+	}
+
+	client := xredis.SetupSentinelClient(options)
+	defer client.Close()
+
+	fmt.Println(client.Set("name", "Raed Shomali"))
+	fmt.Println(client.Get("name"))
+
+	options = &xredis.SentinelOptions{
+		Addresses:       []string{"localhost:26379"},
+		MasterName:      "master",
+		ReplicaSelector: xredis.LeastLatencySelector{},
+	}
+
+	nearestClient := xredis.SetupSentinelClient(options)
+	defer nearestClient.Close()
+
+	fmt.Println(nearestClient.Get("name"))
+}
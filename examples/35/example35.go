@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"github.com/shomali11/xredis"
+	"time"
+)
+
+func main() {
+	options := &xredis.SentinelOptions{
+		Addresses:  []string{"localhost:26379"},
+		MasterName: "master",
+	}
+
+	client := xredis.SetupSentinelClient(options)
+	defer client.Close()
+
+	session := client.WithConsistency(&xredis.ConsistencyOptions{Consistency: xredis.OffsetConsistency})
+
+	fmt.Println(session.Set("name", "Raed Shomali"))
+	fmt.Println(session.Get("name"))
+
+	session = client.WithConsistency(&xredis.ConsistencyOptions{
+		Consistency:  xredis.WaitConsistency,
+		WaitReplicas: 2,
+		WaitTimeout:  100 * time.Millisecond,
+	})
+
+	fmt.Println(session.Set("name", "Shomali"))
+	fmt.Println(session.Get("name"))
+
+	fmt.Println(client.ReadFromMaster().Get("name"))
+}
@@ -1,10 +1,15 @@
 module github.com/shomali11/xredis
 
+go 1.23
+
 require (
 	github.com/FZambia/go-sentinel v0.0.0-20171204085413-76bd05e8e22f
-	github.com/davecgh/go-spew v1.1.0 // indirect
 	github.com/garyburd/redigo v1.6.0
-	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/rafaeljusto/redigomock v0.0.0-20170720131524-7ae0511314e9
 	github.com/stretchr/testify v1.2.1
 )
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+)
@@ -0,0 +1,316 @@
+package xredis
+
+import (
+	"errors"
+	"github.com/garyburd/redigo/redis"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	keyspaceSection   = "keyspace"
+	databasePrefix    = "db"
+	replicaPrefix     = "slave"
+	sectionPrefix     = "#"
+	infoSeparator     = ":"
+	propertySeparator = ","
+	valueSeparator    = "="
+)
+
+// ServerInfo contains the parsed sections of the server's INFO.
+// Fields contains every field as text, including the ones that are not parsed, and Sections the fields of each section
+type ServerInfo struct {
+	Server      InfoServer
+	Clients     InfoClients
+	Memory      InfoMemory
+	Persistence InfoPersistence
+	Stats       InfoStats
+	Replication InfoReplication
+	Keyspace    map[int]InfoKeyspace
+	Fields      map[string]string
+	Sections    map[string]map[string]string
+}
+
+// InfoServer contains the server section's information
+type InfoServer struct {
+	Version    string
+	Mode       string
+	OS         string
+	ProcessID  int64
+	RunID      string
+	TCPPort    int64
+	Uptime     time.Duration
+	ConfigFile string
+}
+
+// InfoClients contains the clients section's information
+type InfoClients struct {
+	Connected  int64
+	Blocked    int64
+	Tracking   int64
+	MaxClients int64
+}
+
+// InfoMemory contains the memory section's information
+type InfoMemory struct {
+	Used               int64
+	UsedRSS            int64
+	UsedPeak           int64
+	UsedLua            int64
+	MaxMemory          int64
+	MaxMemoryPolicy    string
+	FragmentationRatio float64
+}
+
+// InfoPersistence contains the persistence section's information
+type InfoPersistence struct {
+	Loading                 bool
+	RDBChangesSinceLastSave int64
+	RDBSaveInProgress       bool
+	RDBLastSave             time.Time
+	RDBLastSaveStatus       string
+	AOFEnabled              bool
+	AOFRewriteInProgress    bool
+	AOFLastRewriteStatus    string
+}
+
+// InfoStats contains the stats section's information
+type InfoStats struct {
+	TotalConnectionsReceived int64
+	TotalCommandsProcessed   int64
+	OpsPerSecond             int64
+	TotalNetInputBytes       int64
+	TotalNetOutputBytes      int64
+	RejectedConnections      int64
+	ExpiredKeys              int64
+	EvictedKeys              int64
+	KeyspaceHits             int64
+	KeyspaceMisses           int64
+	PubSubChannels           int64
+	PubSubPatterns           int64
+}
+
+// InfoReplication contains the replication section's information
+type InfoReplication struct {
+	Role                 string
+	ConnectedReplicas    int64
+	Replicas             []InfoReplica
+	MasterHost           string
+	MasterPort           int64
+	MasterLinkStatus     string
+	MasterLastIO         time.Duration
+	MasterSyncInProgress bool
+	MasterReplID         string
+	MasterReplOffset     int64
+	ReplicaReplOffset    int64
+}
+
+// InfoReplica contains a connected replica's information as seen by its master
+type InfoReplica struct {
+	Address string
+	State   string
+	Offset  int64
+	Lag     time.Duration
+}
+
+// InfoKeyspace contains a database's number of keys and of keys with an expiration
+type InfoKeyspace struct {
+	Keys       int64
+	Expires    int64
+	AverageTTL time.Duration
+}
+
+// InfoSection returns the server's information parsed. If section names are provided, only those are returned.
+// It is not supported on a cluster, whose nodes each have their own
+func (c *Client) InfoSection(sections ...string) (*ServerInfo, error) {
+	if c.cluster != nil {
+		return nil, errors.New(clusterUnsupportedError)
+	}
+
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	info, err := redis.String(connection.Do(infoCommand, toInterfaces(sections)...))
+	if err != nil {
+		return nil, err
+	}
+	return ParseInfo(info), nil
+}
+
+// ParseInfo parses the text returned by INFO
+func ParseInfo(info string) *ServerInfo {
+	serverInfo := &ServerInfo{
+		Keyspace: map[int]InfoKeyspace{},
+		Fields:   map[string]string{},
+		Sections: map[string]map[string]string{},
+	}
+
+	section := ""
+	for _, line := range strings.Split(info, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+
+		if strings.HasPrefix(line, sectionPrefix) {
+			section = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(line, sectionPrefix)))
+			continue
+		}
+
+		name, value, ok := strings.Cut(line, infoSeparator)
+		if !ok {
+			continue
+		}
+
+		fields, ok := serverInfo.Sections[section]
+		if !ok {
+			fields = map[string]string{}
+			serverInfo.Sections[section] = fields
+		}
+		fields[name] = value
+		serverInfo.Fields[name] = value
+
+		if section == keyspaceSection && strings.HasPrefix(name, databasePrefix) {
+			database, err := strconv.Atoi(strings.TrimPrefix(name, databasePrefix))
+			if err == nil {
+				serverInfo.Keyspace[database] = parseKeyspace(value)
+			}
+		}
+	}
+
+	fields := infoFields(serverInfo.Fields)
+	serverInfo.Server = InfoServer{
+		Version:    fields.string("redis_version"),
+		Mode:       fields.string("redis_mode"),
+		OS:         fields.string("os"),
+		ProcessID:  fields.int("process_id"),
+		RunID:      fields.string("run_id"),
+		TCPPort:    fields.int("tcp_port"),
+		Uptime:     time.Duration(fields.int("uptime_in_seconds")) * time.Second,
+		ConfigFile: fields.string("config_file"),
+	}
+	serverInfo.Clients = InfoClients{
+		Connected:  fields.int("connected_clients"),
+		Blocked:    fields.int("blocked_clients"),
+		Tracking:   fields.int("tracking_clients"),
+		MaxClients: fields.int("maxclients"),
+	}
+	serverInfo.Memory = InfoMemory{
+		Used:               fields.int("used_memory"),
+		UsedRSS:            fields.int("used_memory_rss"),
+		UsedPeak:           fields.int("used_memory_peak"),
+		UsedLua:            fields.int("used_memory_lua"),
+		MaxMemory:          fields.int("maxmemory"),
+		MaxMemoryPolicy:    fields.string("maxmemory_policy"),
+		FragmentationRatio: fields.float("mem_fragmentation_ratio"),
+	}
+	serverInfo.Persistence = InfoPersistence{
+		Loading:                 fields.bool("loading"),
+		RDBChangesSinceLastSave: fields.int("rdb_changes_since_last_save"),
+		RDBSaveInProgress:       fields.bool("rdb_bgsave_in_progress"),
+		RDBLastSave:             fields.time("rdb_last_save_time"),
+		RDBLastSaveStatus:       fields.string("rdb_last_bgsave_status"),
+		AOFEnabled:              fields.bool("aof_enabled"),
+		AOFRewriteInProgress:    fields.bool("aof_rewrite_in_progress"),
+		AOFLastRewriteStatus:    fields.string("aof_last_bgrewrite_status"),
+	}
+	serverInfo.Stats = InfoStats{
+		TotalConnectionsReceived: fields.int("total_connections_received"),
+		TotalCommandsProcessed:   fields.int("total_commands_processed"),
+		OpsPerSecond:             fields.int("instantaneous_ops_per_sec"),
+		TotalNetInputBytes:       fields.int("total_net_input_bytes"),
+		TotalNetOutputBytes:      fields.int("total_net_output_bytes"),
+		RejectedConnections:      fields.int("rejected_connections"),
+		ExpiredKeys:              fields.int("expired_keys"),
+		EvictedKeys:              fields.int("evicted_keys"),
+		KeyspaceHits:             fields.int("keyspace_hits"),
+		KeyspaceMisses:           fields.int("keyspace_misses"),
+		PubSubChannels:           fields.int("pubsub_channels"),
+		PubSubPatterns:           fields.int("pubsub_patterns"),
+	}
+	serverInfo.Replication = InfoReplication{
+		Role:                 fields.string("role"),
+		ConnectedReplicas:    fields.int("connected_slaves"),
+		Replicas:             parseReplicas(serverInfo.Fields),
+		MasterHost:           fields.string("master_host"),
+		MasterPort:           fields.int("master_port"),
+		MasterLinkStatus:     fields.string("master_link_status"),
+		MasterLastIO:         time.Duration(fields.int("master_last_io_seconds_ago")) * time.Second,
+		MasterSyncInProgress: fields.bool("master_sync_in_progress"),
+		MasterReplID:         fields.string("master_replid"),
+		MasterReplOffset:     fields.int("master_repl_offset"),
+		ReplicaReplOffset:    fields.int("slave_repl_offset"),
+	}
+	return serverInfo
+}
+
+// parseReplicas parses the replicas listed by a master such as "slave0:ip=10.0.0.2,port=6379,state=online,offset=42,lag=0"
+func parseReplicas(fields map[string]string) []InfoReplica {
+	var replicas []InfoReplica
+	for i := 0; ; i++ {
+		value, ok := fields[replicaPrefix+strconv.Itoa(i)]
+		if !ok {
+			return replicas
+		}
+
+		properties := parseProperties(value)
+		replicas = append(replicas, InfoReplica{
+			Address: net.JoinHostPort(properties.string("ip"), properties.string("port")),
+			State:   properties.string("state"),
+			Offset:  properties.int("offset"),
+			Lag:     time.Duration(properties.int("lag")) * time.Second,
+		})
+	}
+}
+
+// parseKeyspace parses a database's keyspace such as "keys=10,expires=2,avg_ttl=3000"
+func parseKeyspace(value string) InfoKeyspace {
+	properties := parseProperties(value)
+	return InfoKeyspace{
+		Keys:       properties.int("keys"),
+		Expires:    properties.int("expires"),
+		AverageTTL: time.Duration(properties.int("avg_ttl")) * time.Millisecond,
+	}
+}
+
+func parseProperties(value string) infoFields {
+	properties := infoFields{}
+	for _, property := range strings.Split(value, propertySeparator) {
+		name, value, ok := strings.Cut(property, valueSeparator)
+		if ok {
+			properties[name] = value
+		}
+	}
+	return properties
+}
+
+// infoFields reads typed values from INFO fields, using the zero value for missing or invalid ones
+type infoFields map[string]string
+
+func (f infoFields) string(name string) string {
+	return f[name]
+}
+
+func (f infoFields) int(name string) int64 {
+	value, _ := strconv.ParseInt(f[name], 10, 64)
+	return value
+}
+
+func (f infoFields) float(name string) float64 {
+	value, _ := strconv.ParseFloat(f[name], 64)
+	return value
+}
+
+func (f infoFields) bool(name string) bool {
+	return f[name] == "1"
+}
+
+func (f infoFields) time(name string) time.Time {
+	seconds := f.int(name)
+	if seconds <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(seconds, 0)
+}
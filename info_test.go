@@ -0,0 +1,164 @@
+package xredis
+
+import (
+	"errors"
+	"github.com/rafaeljusto/redigomock"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testInfo = strings.Join([]string{
+	"# Server",
+	"redis_version:7.2.4",
+	"redis_mode:standalone",
+	"os:Linux 6.1.0 x86_64",
+	"process_id:42",
+	"run_id:abc",
+	"tcp_port:6379",
+	"uptime_in_seconds:120",
+	"config_file:/etc/redis.conf",
+	"",
+	"# Clients",
+	"connected_clients:3",
+	"blocked_clients:1",
+	"tracking_clients:0",
+	"maxclients:10000",
+	"",
+	"# Memory",
+	"used_memory:1024",
+	"used_memory_rss:4096",
+	"used_memory_peak:2048",
+	"used_memory_lua:31744",
+	"maxmemory:0",
+	"maxmemory_policy:noeviction",
+	"mem_fragmentation_ratio:4.00",
+	"",
+	"# Persistence",
+	"loading:0",
+	"rdb_changes_since_last_save:7",
+	"rdb_bgsave_in_progress:1",
+	"rdb_last_save_time:1700000000",
+	"rdb_last_bgsave_status:ok",
+	"aof_enabled:1",
+	"aof_rewrite_in_progress:0",
+	"aof_last_bgrewrite_status:ok",
+	"",
+	"# Stats",
+	"total_connections_received:10",
+	"total_commands_processed:100",
+	"instantaneous_ops_per_sec:5",
+	"total_net_input_bytes:300",
+	"total_net_output_bytes:400",
+	"rejected_connections:0",
+	"expired_keys:2",
+	"evicted_keys:1",
+	"keyspace_hits:60",
+	"keyspace_misses:40",
+	"pubsub_channels:1",
+	"pubsub_patterns:0",
+	"",
+	"# Replication",
+	"role:master",
+	"connected_slaves:2",
+	"slave0:ip=10.0.0.2,port=6379,state=online,offset=42,lag=0",
+	"slave1:ip=10.0.0.3,port=6380,state=wait_bgsave,offset=0,lag=3",
+	"master_replid:def",
+	"master_repl_offset:42",
+	"",
+	"# Keyspace",
+	"db0:keys=10,expires=2,avg_ttl=3000",
+	"db3:keys=1,expires=0,avg_ttl=0",
+	"",
+}, "\r\n")
+
+func TestClient_InfoSection(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("INFO").Expect(testInfo)
+	connection.Command("INFO", "memory", "keyspace").Expect("# Memory\r\nused_memory:1024\r\n\r\n# Keyspace\r\n")
+	connection.Command("INFO", "unknown").ExpectError(errors.New("error"))
+
+	client := mockClient(connection)
+
+	info, err := client.InfoSection()
+	assert.Nil(t, err)
+	assert.Equal(t, info.Server.Version, "7.2.4")
+	assert.Equal(t, info.Stats.KeyspaceHits, int64(60))
+
+	info, err = client.InfoSection("memory", "keyspace")
+	assert.Nil(t, err)
+	assert.Equal(t, info.Memory.Used, int64(1024))
+	assert.Equal(t, info.Keyspace, map[int]InfoKeyspace{})
+
+	info, err = client.InfoSection("unknown")
+	assert.Nil(t, info)
+	assert.NotNil(t, err)
+}
+
+func TestParseInfo(t *testing.T) {
+	info := ParseInfo(testInfo)
+
+	assert.Equal(t, info.Server, InfoServer{
+		Version:    "7.2.4",
+		Mode:       "standalone",
+		OS:         "Linux 6.1.0 x86_64",
+		ProcessID:  42,
+		RunID:      "abc",
+		TCPPort:    6379,
+		Uptime:     2 * time.Minute,
+		ConfigFile: "/etc/redis.conf",
+	})
+	assert.Equal(t, info.Clients, InfoClients{Connected: 3, Blocked: 1, MaxClients: 10000})
+	assert.Equal(t, info.Memory, InfoMemory{
+		Used:               1024,
+		UsedRSS:            4096,
+		UsedPeak:           2048,
+		UsedLua:            31744,
+		MaxMemoryPolicy:    "noeviction",
+		FragmentationRatio: 4,
+	})
+	assert.Equal(t, info.Persistence, InfoPersistence{
+		RDBChangesSinceLastSave: 7,
+		RDBSaveInProgress:       true,
+		RDBLastSave:             time.Unix(1700000000, 0),
+		RDBLastSaveStatus:       "ok",
+		AOFEnabled:              true,
+		AOFLastRewriteStatus:    "ok",
+	})
+	assert.Equal(t, info.Stats.TotalCommandsProcessed, int64(100))
+	assert.Equal(t, info.Stats.OpsPerSecond, int64(5))
+	assert.Equal(t, info.Stats.EvictedKeys, int64(1))
+	assert.Equal(t, info.Stats.PubSubChannels, int64(1))
+
+	assert.Equal(t, info.Replication.Role, "master")
+	assert.Equal(t, info.Replication.ConnectedReplicas, int64(2))
+	assert.Equal(t, info.Replication.MasterReplOffset, int64(42))
+	assert.Equal(t, info.Replication.Replicas, []InfoReplica{
+		{Address: "10.0.0.2:6379", State: "online", Offset: 42},
+		{Address: "10.0.0.3:6380", State: "wait_bgsave", Lag: 3 * time.Second},
+	})
+
+	assert.Equal(t, info.Keyspace, map[int]InfoKeyspace{
+		0: {Keys: 10, Expires: 2, AverageTTL: 3 * time.Second},
+		3: {Keys: 1},
+	})
+
+	assert.Equal(t, info.Fields["redis_version"], "7.2.4")
+	assert.Equal(t, info.Sections["memory"]["maxmemory_policy"], "noeviction")
+	assert.Equal(t, len(info.Sections), 7)
+}
+
+func TestParseInfo_Replica(t *testing.T) {
+	info := ParseInfo("# Replication\r\nrole:slave\r\nmaster_host:10.0.0.1\r\nmaster_port:6379\r\nmaster_link_status:up\r\nmaster_last_io_seconds_ago:2\r\nmaster_sync_in_progress:0\r\nslave_repl_offset:40\r\nfuture_field:value\r\n")
+
+	assert.Equal(t, info.Replication, InfoReplication{
+		Role:              "slave",
+		MasterHost:        "10.0.0.1",
+		MasterPort:        6379,
+		MasterLinkStatus:  "up",
+		MasterLastIO:      2 * time.Second,
+		ReplicaReplOffset: 40,
+	})
+	assert.Equal(t, info.Fields["future_field"], "value")
+}
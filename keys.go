@@ -0,0 +1,361 @@
+package xredis
+
+import (
+	"github.com/garyburd/redigo/redis"
+	"math/rand"
+	"time"
+)
+
+const (
+	pExpireCommand   = "PEXPIRE"
+	expireAtCommand  = "EXPIREAT"
+	pExpireAtCommand = "PEXPIREAT"
+	ttlCommand       = "TTL"
+	pTTLCommand      = "PTTL"
+	persistCommand   = "PERSIST"
+	typeCommand      = "TYPE"
+	renameCommand    = "RENAME"
+	renameNxCommand  = "RENAMENX"
+	copyCommand      = "COPY"
+	unlinkCommand    = "UNLINK"
+	touchCommand     = "TOUCH"
+	objectCommand    = "OBJECT"
+	randomKeyCommand = "RANDOMKEY"
+	dumpCommand      = "DUMP"
+	restoreCommand   = "RESTORE"
+	moveCommand      = "MOVE"
+	selectCommand    = "SELECT"
+
+	encodingSubcommand = "ENCODING"
+	idleTimeSubcommand = "IDLETIME"
+	freqSubcommand     = "FREQ"
+
+	dbOption      = "DB"
+	replaceOption = "REPLACE"
+	absTTLOption  = "ABSTTL"
+
+	missingKeyTTL = -2
+)
+
+// SetOptions contains the options of SetWithOptions.
+// ExpireAt takes precedence over TTL, and the expiration is sent in milliseconds only if it is not a whole number of seconds
+type SetOptions struct {
+	TTL      time.Duration
+	ExpireAt time.Time
+	KeepTTL  bool
+	NX       bool
+	XX       bool
+	Get      bool
+}
+
+// SetWithOptions sets a key/value pair and returns whether it was set.
+// If Get is requested, the previous value and whether it existed are returned as well
+func (c *Client) SetWithOptions(key string, value string, options SetOptions) (bool, string, bool, error) {
+	args := []interface{}{key, value}
+	if options.NX {
+		args = append(args, notExistsOption)
+	}
+	if options.XX {
+		args = append(args, existsOption)
+	}
+	if options.Get {
+		args = append(args, getOption)
+	}
+	if options.KeepTTL {
+		args = append(args, keepTTLOption)
+	} else {
+		args = append(args, expirationArgs(options.TTL, options.ExpireAt)...)
+	}
+
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	reply, err := connection.Do(setCommand, args...)
+	if !options.Get {
+		applied, err := toBool(reply, err)
+		return applied, "", false, err
+	}
+
+	previous, found, err := toString(reply, err)
+	if err != nil {
+		return false, "", false, err
+	}
+
+	applied := true
+	if options.NX {
+		applied = !found
+	}
+	if options.XX {
+		applied = found
+	}
+	return applied, previous, found, nil
+}
+
+// ExpireDuration sets a key's timeout, in milliseconds if it is not a whole number of seconds.
+// A positive timeout below a millisecond is rounded up to a millisecond
+func (c *Client) ExpireDuration(key string, timeout time.Duration) (bool, error) {
+	if timeout%time.Second != 0 {
+		return c.PExpire(key, toMilliseconds(timeout))
+	}
+	return c.Expire(key, int64(timeout/time.Second))
+}
+
+// PExpire sets a key's timeout in milliseconds
+func (c *Client) PExpire(key string, timeout int64) (bool, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return toPositive(connection.Do(pExpireCommand, key, timeout))
+}
+
+// ExpireAt sets the time a key expires at, in milliseconds if it is not a whole number of seconds
+func (c *Client) ExpireAt(key string, at time.Time) (bool, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	if at.Nanosecond() != 0 {
+		return toPositive(connection.Do(pExpireAtCommand, key, at.UnixMilli()))
+	}
+	return toPositive(connection.Do(expireAtCommand, key, at.Unix()))
+}
+
+// TTL returns a key's remaining time to live in seconds, negative if it has no timeout, and whether the key exists
+func (c *Client) TTL(key string) (time.Duration, bool, error) {
+	connection := c.getReadConnection()
+	defer connection.Close()
+
+	reply, err := connection.Do(ttlCommand, key)
+	return toTTL(reply, err, time.Second)
+}
+
+// PTTL returns a key's remaining time to live in milliseconds, negative if it has no timeout, and whether the key exists
+func (c *Client) PTTL(key string) (time.Duration, bool, error) {
+	connection := c.getReadConnection()
+	defer connection.Close()
+
+	reply, err := connection.Do(pTTLCommand, key)
+	return toTTL(reply, err, time.Millisecond)
+}
+
+// Persist removes a key's timeout
+func (c *Client) Persist(key string) (bool, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return toPositive(connection.Do(persistCommand, key))
+}
+
+// expirationArgs returns the SET arguments expiring a key at a time or after a ttl
+func expirationArgs(ttl time.Duration, at time.Time) []interface{} {
+	if !at.IsZero() {
+		if at.Nanosecond() != 0 {
+			return []interface{}{expireAtMillisecondsOption, at.UnixMilli()}
+		}
+		return []interface{}{expireAtOption, at.Unix()}
+	}
+
+	if ttl <= 0 {
+		return nil
+	}
+	if ttl%time.Second != 0 {
+		return []interface{}{expireMillisecondsOption, toMilliseconds(ttl)}
+	}
+	return []interface{}{expireOption, int64(ttl / time.Second)}
+}
+
+// toMilliseconds converts a duration to milliseconds, rounding a positive duration below a millisecond up to one
+// so that it does not become zero, which Redis either rejects or treats as no timeout at all
+func toMilliseconds(duration time.Duration) int64 {
+	if duration > 0 && duration < time.Millisecond {
+		return 1
+	}
+	return duration.Milliseconds()
+}
+
+func toTTL(reply interface{}, err error, unit time.Duration) (time.Duration, bool, error) {
+	value, err := redis.Int64(reply, err)
+	if err != nil {
+		return 0, false, err
+	}
+	if value == missingKeyTTL {
+		return 0, false, nil
+	}
+	if value < 0 {
+		return -1, true, nil
+	}
+	return time.Duration(value) * unit, true, nil
+}
+
+// Type returns the type of the value stored at a key, "none" if the key does not exist
+func (c *Client) Type(key string) (string, error) {
+	connection := c.getReadConnection()
+	defer connection.Close()
+
+	return redis.String(connection.Do(typeCommand, key))
+}
+
+// Rename renames a key, overwriting the new key if it exists
+func (c *Client) Rename(key string, newKey string) error {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return toError(connection.Do(renameCommand, key, newKey))
+}
+
+// RenameNx renames a key if the new key does not exist
+func (c *Client) RenameNx(key string, newKey string) (bool, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return toPositive(connection.Do(renameNxCommand, key, newKey))
+}
+
+// Copy copies a key's value to the destination key, replacing it if requested
+func (c *Client) Copy(source string, destination string, replace bool) (bool, error) {
+	args := []interface{}{source, destination}
+	if replace {
+		args = append(args, replaceOption)
+	}
+
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return toPositive(connection.Do(copyCommand, args...))
+}
+
+// CopyToDatabase copies a key's value to the destination key of another database, replacing it if requested
+func (c *Client) CopyToDatabase(source string, destination string, database int, replace bool) (bool, error) {
+	args := []interface{}{source, destination, dbOption, database}
+	if replace {
+		args = append(args, replaceOption)
+	}
+
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return toPositive(connection.Do(copyCommand, args...))
+}
+
+// Unlink deletes keys, reclaiming their memory in the background
+func (c *Client) Unlink(keys ...string) (int64, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return redis.Int64(connection.Do(unlinkCommand, toInterfaces(keys)...))
+}
+
+// Touch updates the keys' last access time and returns how many exist
+func (c *Client) Touch(keys ...string) (int64, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return redis.Int64(connection.Do(touchCommand, toInterfaces(keys)...))
+}
+
+// ObjectEncoding returns the internal encoding of a key's value
+func (c *Client) ObjectEncoding(key string) (string, bool, error) {
+	connection := c.getReadConnection()
+	defer connection.Close()
+
+	return toString(connection.Do(objectCommand, encodingSubcommand, key))
+}
+
+// ObjectIdleTime returns how long a key has not been accessed
+func (c *Client) ObjectIdleTime(key string) (time.Duration, bool, error) {
+	connection := c.getReadConnection()
+	defer connection.Close()
+
+	seconds, found, err := toInt(connection.Do(objectCommand, idleTimeSubcommand, key))
+	return time.Duration(seconds) * time.Second, found, err
+}
+
+// ObjectFreq returns a key's access frequency, available if the eviction policy is LFU
+func (c *Client) ObjectFreq(key string) (int64, bool, error) {
+	connection := c.getReadConnection()
+	defer connection.Close()
+
+	return toInt(connection.Do(objectCommand, freqSubcommand, key))
+}
+
+// RandomKey returns a random key and whether the database has any key. On a cluster, the key comes from a random master that has any
+func (c *Client) RandomKey() (string, bool, error) {
+	if c.cluster != nil {
+		addresses := c.nodeAddresses()
+		for _, index := range rand.Perm(len(addresses)) {
+			var key string
+			var found bool
+			err := c.onNode(addresses[index], func(connection redis.Conn) error {
+				var err error
+				key, found, err = toString(connection.Do(randomKeyCommand))
+				return err
+			})
+			if err != nil || found {
+				return key, found, err
+			}
+		}
+		return "", false, nil
+	}
+
+	connection := c.getReadConnection()
+	defer connection.Close()
+
+	return toString(connection.Do(randomKeyCommand))
+}
+
+// Dump serializes a key's value in the format RESTORE expects
+func (c *Client) Dump(key string) ([]byte, bool, error) {
+	connection := c.getReadConnection()
+	defer connection.Close()
+
+	return toBytes(connection.Do(dumpCommand, key))
+}
+
+// RestoreOptions contains the options of Restore. ExpireAt takes precedence over TTL
+type RestoreOptions struct {
+	TTL      time.Duration
+	ExpireAt time.Time
+	Replace  bool
+}
+
+// Restore creates a key from a value serialized by Dump
+func (c *Client) Restore(key string, serialized []byte, options RestoreOptions) error {
+	args := []interface{}{key, toMilliseconds(options.TTL), serialized}
+	if !options.ExpireAt.IsZero() {
+		args[1] = options.ExpireAt.UnixMilli()
+	}
+	if options.Replace {
+		args = append(args, replaceOption)
+	}
+	if !options.ExpireAt.IsZero() {
+		args = append(args, absTTLOption)
+	}
+
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return toError(connection.Do(restoreCommand, args...))
+}
+
+// Move moves a key to another database
+func (c *Client) Move(key string, database int) (bool, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return toPositive(connection.Do(moveCommand, key, database))
+}
+
+// InDatabase runs the function on a dedicated connection that selected the database.
+// The connection is closed afterwards so that the pools' connections keep using the configured database
+func (c *Client) InDatabase(database int, fn func(connection redis.Conn) error) error {
+	connection, err := c.dial()
+	if err != nil {
+		return err
+	}
+	defer connection.Close()
+
+	_, err = connection.Do(selectCommand, database)
+	if err != nil {
+		return err
+	}
+	return fn(connection)
+}
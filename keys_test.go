@@ -0,0 +1,358 @@
+package xredis
+
+import (
+	"errors"
+	"github.com/garyburd/redigo/redis"
+	"github.com/rafaeljusto/redigomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestClient_SetWithOptions(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("SET", "key", "value").Expect("OK")
+	connection.Command("SET", "key", "value", "NX", "EX", int64(10)).Expect(nil)
+	connection.Command("SET", "key", "value", "XX", "PX", int64(1500)).Expect("OK")
+	connection.Command("SET", "key", "value", "PX", int64(1)).Expect("OK")
+	connection.Command("SET", "key", "value", "KEEPTTL").Expect("OK")
+	connection.Command("SET", "key", "value", "EXAT", int64(1700000000)).Expect("OK")
+	connection.Command("SET", "key", "value", "PXAT", int64(1700000000500)).Expect("OK")
+
+	client := mockClient(connection)
+
+	applied, _, _, err := client.SetWithOptions("key", "value", SetOptions{})
+	assert.True(t, applied)
+	assert.Nil(t, err)
+
+	applied, _, _, err = client.SetWithOptions("key", "value", SetOptions{NX: true, TTL: 10 * time.Second})
+	assert.False(t, applied)
+	assert.Nil(t, err)
+
+	applied, _, _, err = client.SetWithOptions("key", "value", SetOptions{XX: true, TTL: 1500 * time.Millisecond})
+	assert.True(t, applied)
+	assert.Nil(t, err)
+
+	applied, _, _, err = client.SetWithOptions("key", "value", SetOptions{TTL: 500 * time.Microsecond})
+	assert.True(t, applied)
+	assert.Nil(t, err)
+
+	applied, _, _, err = client.SetWithOptions("key", "value", SetOptions{KeepTTL: true, TTL: time.Second})
+	assert.True(t, applied)
+	assert.Nil(t, err)
+
+	applied, _, _, err = client.SetWithOptions("key", "value", SetOptions{ExpireAt: time.Unix(1700000000, 0), TTL: time.Second})
+	assert.True(t, applied)
+	assert.Nil(t, err)
+
+	applied, _, _, err = client.SetWithOptions("key", "value", SetOptions{ExpireAt: time.UnixMilli(1700000000500)})
+	assert.True(t, applied)
+	assert.Nil(t, err)
+}
+
+func TestClient_SetWithOptions_Get(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("SET", "key", "value", "GET").Expect("previous")
+	connection.Command("SET", "new", "value", "NX", "GET").Expect(nil)
+	connection.Command("SET", "old", "value", "NX", "GET").Expect("previous")
+	connection.Command("SET", "new", "value", "XX", "GET").Expect(nil)
+	connection.Command("SET", "error", "value", "GET").ExpectError(errors.New("Oops"))
+
+	client := mockClient(connection)
+
+	applied, previous, found, err := client.SetWithOptions("key", "value", SetOptions{Get: true})
+	assert.True(t, applied)
+	assert.Equal(t, previous, "previous")
+	assert.True(t, found)
+	assert.Nil(t, err)
+
+	applied, previous, found, err = client.SetWithOptions("new", "value", SetOptions{NX: true, Get: true})
+	assert.True(t, applied)
+	assert.Equal(t, previous, "")
+	assert.False(t, found)
+	assert.Nil(t, err)
+
+	applied, previous, found, err = client.SetWithOptions("old", "value", SetOptions{NX: true, Get: true})
+	assert.False(t, applied)
+	assert.Equal(t, previous, "previous")
+	assert.True(t, found)
+	assert.Nil(t, err)
+
+	applied, _, found, err = client.SetWithOptions("new", "value", SetOptions{XX: true, Get: true})
+	assert.False(t, applied)
+	assert.False(t, found)
+	assert.Nil(t, err)
+
+	applied, _, _, err = client.SetWithOptions("error", "value", SetOptions{Get: true})
+	assert.False(t, applied)
+	assert.NotNil(t, err)
+}
+
+func TestClient_ExpireDuration(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("EXPIRE", "key", int64(2)).Expect(int64(1))
+	connection.Command("PEXPIRE", "key", int64(1500)).Expect(int64(0))
+	connection.Command("PEXPIRE", "key", int64(1)).Expect(int64(1))
+
+	client := mockClient(connection)
+
+	ok, err := client.ExpireDuration("key", 2*time.Second)
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	ok, err = client.ExpireDuration("key", 1500*time.Millisecond)
+	assert.False(t, ok)
+	assert.Nil(t, err)
+
+	ok, err = client.ExpireDuration("key", 500*time.Microsecond)
+	assert.True(t, ok)
+	assert.Nil(t, err)
+}
+
+func TestClient_PExpire(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("PEXPIRE", "key", int64(100)).Expect(int64(1))
+
+	client := mockClient(connection)
+
+	ok, err := client.PExpire("key", 100)
+	assert.True(t, ok)
+	assert.Nil(t, err)
+}
+
+func TestClient_ExpireAt(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("EXPIREAT", "key", int64(1700000000)).Expect(int64(1))
+	connection.Command("PEXPIREAT", "key", int64(1700000000500)).Expect(int64(1))
+
+	client := mockClient(connection)
+
+	ok, err := client.ExpireAt("key", time.Unix(1700000000, 0))
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	ok, err = client.ExpireAt("key", time.UnixMilli(1700000000500))
+	assert.True(t, ok)
+	assert.Nil(t, err)
+}
+
+func TestClient_TTL(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("TTL", "key").Expect(int64(10))
+	connection.Command("TTL", "persistent").Expect(int64(-1))
+	connection.Command("TTL", "missing").Expect(int64(-2))
+
+	client := mockClient(connection)
+
+	ttl, found, err := client.TTL("key")
+	assert.Equal(t, ttl, 10*time.Second)
+	assert.True(t, found)
+	assert.Nil(t, err)
+
+	ttl, found, err = client.TTL("persistent")
+	assert.Equal(t, ttl, time.Duration(-1))
+	assert.True(t, found)
+	assert.Nil(t, err)
+
+	ttl, found, err = client.TTL("missing")
+	assert.Equal(t, ttl, time.Duration(0))
+	assert.False(t, found)
+	assert.Nil(t, err)
+}
+
+func TestClient_PTTL(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("PTTL", "key").Expect(int64(1500))
+	connection.Command("PTTL", "error").ExpectError(errors.New("Oops"))
+
+	client := mockClient(connection)
+
+	ttl, found, err := client.PTTL("key")
+	assert.Equal(t, ttl, 1500*time.Millisecond)
+	assert.True(t, found)
+	assert.Nil(t, err)
+
+	_, found, err = client.PTTL("error")
+	assert.False(t, found)
+	assert.NotNil(t, err)
+}
+
+func TestClient_Persist(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("PERSIST", "key").Expect(int64(1))
+
+	client := mockClient(connection)
+
+	ok, err := client.Persist("key")
+	assert.True(t, ok)
+	assert.Nil(t, err)
+}
+
+func TestClient_Type(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("TYPE", "key").Expect("string")
+
+	client := mockClient(connection)
+
+	keyType, err := client.Type("key")
+	assert.Equal(t, keyType, "string")
+	assert.Nil(t, err)
+}
+
+func TestClient_Rename(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("RENAME", "key", "new").Expect("OK")
+	connection.Command("RENAME", "missing", "new").ExpectError(redis.Error("ERR no such key"))
+	connection.Command("RENAMENX", "key", "new").Expect(int64(0))
+
+	client := mockClient(connection)
+
+	assert.Nil(t, client.Rename("key", "new"))
+	assert.Equal(t, client.Rename("missing", "new"), redis.Error("ERR no such key"))
+
+	ok, err := client.RenameNx("key", "new")
+	assert.False(t, ok)
+	assert.Nil(t, err)
+}
+
+func TestClient_Copy(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("COPY", "source", "destination").Expect(int64(1))
+	connection.Command("COPY", "source", "destination", "REPLACE").Expect(int64(1))
+	connection.Command("COPY", "source", "destination", "DB", 2, "REPLACE").Expect(int64(0))
+
+	client := mockClient(connection)
+
+	ok, err := client.Copy("source", "destination", false)
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	ok, err = client.Copy("source", "destination", true)
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	ok, err = client.CopyToDatabase("source", "destination", 2, true)
+	assert.False(t, ok)
+	assert.Nil(t, err)
+}
+
+func TestClient_Unlink(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("UNLINK", "a", "b").Expect(int64(2))
+	connection.Command("TOUCH", "a", "b").Expect(int64(1))
+
+	client := mockClient(connection)
+
+	count, err := client.Unlink("a", "b")
+	assert.Equal(t, count, int64(2))
+	assert.Nil(t, err)
+
+	count, err = client.Touch("a", "b")
+	assert.Equal(t, count, int64(1))
+	assert.Nil(t, err)
+}
+
+func TestClient_Object(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("OBJECT", "ENCODING", "key").Expect("listpack")
+	connection.Command("OBJECT", "ENCODING", "missing").Expect(nil)
+	connection.Command("OBJECT", "IDLETIME", "key").Expect(int64(30))
+	connection.Command("OBJECT", "FREQ", "key").Expect(int64(5))
+
+	client := mockClient(connection)
+
+	encoding, found, err := client.ObjectEncoding("key")
+	assert.Equal(t, encoding, "listpack")
+	assert.True(t, found)
+	assert.Nil(t, err)
+
+	encoding, found, err = client.ObjectEncoding("missing")
+	assert.Equal(t, encoding, "")
+	assert.False(t, found)
+	assert.Nil(t, err)
+
+	idle, found, err := client.ObjectIdleTime("key")
+	assert.Equal(t, idle, 30*time.Second)
+	assert.True(t, found)
+	assert.Nil(t, err)
+
+	freq, found, err := client.ObjectFreq("key")
+	assert.Equal(t, freq, int64(5))
+	assert.True(t, found)
+	assert.Nil(t, err)
+}
+
+func TestClient_RandomKey(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("RANDOMKEY").Expect("key")
+
+	client := mockClient(connection)
+
+	key, found, err := client.RandomKey()
+	assert.Equal(t, key, "key")
+	assert.True(t, found)
+	assert.Nil(t, err)
+}
+
+func TestClient_DumpRestore(t *testing.T) {
+	serialized := []byte{0x00, 0x03, 0x61}
+
+	connection := redigomock.NewConn()
+	connection.Command("DUMP", "key").Expect(serialized)
+	connection.Command("RESTORE", "key", int64(0), serialized).Expect("OK")
+	connection.Command("RESTORE", "key", int64(1500), serialized, "REPLACE").Expect("OK")
+	connection.Command("RESTORE", "key", int64(1), serialized).Expect("OK")
+	connection.Command("RESTORE", "key", int64(1700000000000), serialized, "ABSTTL").Expect("OK")
+	connection.Command("RESTORE", "exists", int64(0), serialized).ExpectError(redis.Error("BUSYKEY Target key name already exists."))
+
+	client := mockClient(connection)
+
+	value, found, err := client.Dump("key")
+	assert.Equal(t, value, serialized)
+	assert.True(t, found)
+	assert.Nil(t, err)
+
+	assert.Nil(t, client.Restore("key", serialized, RestoreOptions{}))
+	assert.Nil(t, client.Restore("key", serialized, RestoreOptions{TTL: 1500 * time.Millisecond, Replace: true}))
+	assert.Nil(t, client.Restore("key", serialized, RestoreOptions{TTL: 500 * time.Microsecond}))
+	assert.Nil(t, client.Restore("key", serialized, RestoreOptions{TTL: time.Second, ExpireAt: time.UnixMilli(1700000000000)}))
+	assert.NotNil(t, client.Restore("exists", serialized, RestoreOptions{}))
+}
+
+func TestClient_Move(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("MOVE", "key", 1).Expect(int64(1))
+
+	client := mockClient(connection)
+
+	ok, err := client.Move("key", 1)
+	assert.True(t, ok)
+	assert.Nil(t, err)
+}
+
+func TestClient_InDatabase(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("SELECT", 2).Expect("OK")
+	connection.Command("DBSIZE").Expect(int64(10))
+
+	client := mockClient(connection)
+
+	var size int64
+	err := client.InDatabase(2, func(connection redis.Conn) error {
+		var err error
+		size, err = redis.Int64(connection.Do("DBSIZE"))
+		return err
+	})
+	assert.Equal(t, size, int64(10))
+	assert.Nil(t, err)
+
+	connection = redigomock.NewConn()
+	connection.Command("SELECT", 20).ExpectError(redis.Error("ERR DB index is out of range"))
+
+	client = mockClient(connection)
+
+	err = client.InDatabase(20, func(connection redis.Conn) error {
+		return errors.New("not called")
+	})
+	assert.Equal(t, err, redis.Error("ERR DB index is out of range"))
+}
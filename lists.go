@@ -0,0 +1,201 @@
+package xredis
+
+import (
+	"github.com/garyburd/redigo/redis"
+	"time"
+)
+
+const (
+	beforeOption = "BEFORE"
+	afterOption  = "AFTER"
+
+	lPushCommand   = "LPUSH"
+	rPushCommand   = "RPUSH"
+	lPushXCommand  = "LPUSHX"
+	rPushXCommand  = "RPUSHX"
+	lPopCommand    = "LPOP"
+	rPopCommand    = "RPOP"
+	lLenCommand    = "LLEN"
+	lIndexCommand  = "LINDEX"
+	lSetCommand    = "LSET"
+	lInsertCommand = "LINSERT"
+	lRangeCommand  = "LRANGE"
+	lTrimCommand   = "LTRIM"
+	lRemCommand    = "LREM"
+	lMoveCommand   = "LMOVE"
+	bLPopCommand   = "BLPOP"
+	bRPopCommand   = "BRPOP"
+	bLMoveCommand  = "BLMOVE"
+)
+
+// ListDirection determines which end of a list to use
+type ListDirection string
+
+const (
+	// ListLeft is the head of a list
+	ListLeft ListDirection = "LEFT"
+
+	// ListRight is the tail of a list
+	ListRight ListDirection = "RIGHT"
+)
+
+// LPush prepends values to a list
+func (c *Client) LPush(key string, values ...string) (int64, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return redis.Int64(connection.Do(lPushCommand, prepend(key, values)...))
+}
+
+// RPush appends values to a list
+func (c *Client) RPush(key string, values ...string) (int64, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return redis.Int64(connection.Do(rPushCommand, prepend(key, values)...))
+}
+
+// LPushX prepends values to a list only if it exists
+func (c *Client) LPushX(key string, values ...string) (int64, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return redis.Int64(connection.Do(lPushXCommand, prepend(key, values)...))
+}
+
+// RPushX appends values to a list only if it exists
+func (c *Client) RPushX(key string, values ...string) (int64, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return redis.Int64(connection.Do(rPushXCommand, prepend(key, values)...))
+}
+
+// LPop removes and returns the first element of a list
+func (c *Client) LPop(key string) (string, bool, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return toString(connection.Do(lPopCommand, key))
+}
+
+// RPop removes and returns the last element of a list
+func (c *Client) RPop(key string) (string, bool, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return toString(connection.Do(rPopCommand, key))
+}
+
+// LLen returns the length of a list
+func (c *Client) LLen(key string) (int64, error) {
+	connection := c.getReadConnection()
+	defer connection.Close()
+
+	return redis.Int64(connection.Do(lLenCommand, key))
+}
+
+// LIndex returns the element at an index of a list
+func (c *Client) LIndex(key string, index int64) (string, bool, error) {
+	connection := c.getReadConnection()
+	defer connection.Close()
+
+	return toString(connection.Do(lIndexCommand, key, index))
+}
+
+// LSet sets the element at an index of a list
+func (c *Client) LSet(key string, index int64, value string) error {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return toError(connection.Do(lSetCommand, key, index, value))
+}
+
+// LInsertBefore inserts a value before the pivot and returns the list's length, or -1 if the pivot was not found
+func (c *Client) LInsertBefore(key string, pivot string, value string) (int64, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return redis.Int64(connection.Do(lInsertCommand, key, beforeOption, pivot, value))
+}
+
+// LInsertAfter inserts a value after the pivot and returns the list's length, or -1 if the pivot was not found
+func (c *Client) LInsertAfter(key string, pivot string, value string) (int64, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return redis.Int64(connection.Do(lInsertCommand, key, afterOption, pivot, value))
+}
+
+// LRange returns the elements of a list between start and stop inclusive
+func (c *Client) LRange(key string, start int64, stop int64) ([]string, error) {
+	connection := c.getReadConnection()
+	defer connection.Close()
+
+	return redis.Strings(connection.Do(lRangeCommand, key, start, stop))
+}
+
+// LTrim trims a list to the elements between start and stop inclusive
+func (c *Client) LTrim(key string, start int64, stop int64) error {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return toError(connection.Do(lTrimCommand, key, start, stop))
+}
+
+// LRem removes count occurrences of a value from a list
+func (c *Client) LRem(key string, count int64, value string) (int64, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return redis.Int64(connection.Do(lRemCommand, key, count, value))
+}
+
+// LMove atomically moves an element from one list to another
+func (c *Client) LMove(source string, destination string, from ListDirection, to ListDirection) (string, bool, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return toString(connection.Do(lMoveCommand, source, destination, string(from), string(to)))
+}
+
+// BLPop removes and returns the first element of the first non empty list, blocking up to the timeout.
+// A timeout of zero blocks indefinitely
+func (c *Client) BLPop(timeout time.Duration, keys ...string) (string, string, bool, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return toKeyValue(doBlocking(connection, timeout, bLPopCommand, append(toInterfaces(keys), timeout.Seconds())...))
+}
+
+// BRPop removes and returns the last element of the first non empty list, blocking up to the timeout.
+// A timeout of zero blocks indefinitely
+func (c *Client) BRPop(timeout time.Duration, keys ...string) (string, string, bool, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return toKeyValue(doBlocking(connection, timeout, bRPopCommand, append(toInterfaces(keys), timeout.Seconds())...))
+}
+
+// BLMove atomically moves an element from one list to another, blocking up to the timeout.
+// A timeout of zero blocks indefinitely
+func (c *Client) BLMove(source string, destination string, from ListDirection, to ListDirection, timeout time.Duration) (string, bool, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return toString(doBlocking(connection, timeout, bLMoveCommand, source, destination, string(from), string(to), timeout.Seconds()))
+}
+
+func toKeyValue(reply interface{}, err error) (string, string, bool, error) {
+	values, e := redis.Strings(reply, err)
+	if e == redis.ErrNil {
+		return "", "", false, nil
+	}
+	if e != nil {
+		return "", "", false, e
+	}
+	if len(values) != 2 {
+		return "", "", false, nil
+	}
+	return values[0], values[1], true, nil
+}
@@ -0,0 +1,152 @@
+package xredis
+
+import (
+	"errors"
+	"github.com/garyburd/redigo/redis"
+	"github.com/rafaeljusto/redigomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestClient_LPush(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("LPUSH", "list", "a", "b").Expect(int64(2))
+	connection.Command("RPUSH", "list", "c").Expect(int64(3))
+	connection.Command("LPUSHX", "unknown", "a").Expect(int64(0))
+	connection.Command("RPUSHX", "unknown", "a").Expect(int64(0))
+
+	client := mockClient(connection)
+
+	length, err := client.LPush("list", "a", "b")
+	assert.Equal(t, length, int64(2))
+	assert.Nil(t, err)
+
+	length, err = client.RPush("list", "c")
+	assert.Equal(t, length, int64(3))
+	assert.Nil(t, err)
+
+	length, err = client.LPushX("unknown", "a")
+	assert.Equal(t, length, int64(0))
+	assert.Nil(t, err)
+
+	length, err = client.RPushX("unknown", "a")
+	assert.Equal(t, length, int64(0))
+	assert.Nil(t, err)
+}
+
+func TestClient_LPop(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("LPOP", "list").Expect("a")
+	connection.Command("RPOP", "list").Expect("b")
+	connection.Command("LPOP", "unknown").ExpectError(redis.ErrNil)
+
+	client := mockClient(connection)
+
+	value, ok, err := client.LPop("list")
+	assert.Equal(t, value, "a")
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	value, ok, err = client.RPop("list")
+	assert.Equal(t, value, "b")
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	value, ok, err = client.LPop("unknown")
+	assert.Equal(t, value, "")
+	assert.False(t, ok)
+	assert.Nil(t, err)
+}
+
+func TestClient_LRange(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("LRANGE", "list", int64(0), int64(-1)).Expect([]interface{}{[]byte("a"), []byte("b")})
+	connection.Command("LLEN", "list").Expect(int64(2))
+	connection.Command("LINDEX", "list", int64(1)).Expect([]byte("b"))
+	connection.Command("LTRIM", "list", int64(0), int64(0)).Expect("OK")
+
+	client := mockClient(connection)
+
+	values, err := client.LRange("list", 0, -1)
+	assert.Equal(t, values, []string{"a", "b"})
+	assert.Nil(t, err)
+
+	length, err := client.LLen("list")
+	assert.Equal(t, length, int64(2))
+	assert.Nil(t, err)
+
+	value, ok, err := client.LIndex("list", 1)
+	assert.Equal(t, value, "b")
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	assert.Nil(t, client.LTrim("list", 0, 0))
+}
+
+func TestClient_LSet(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("LSET", "list", int64(0), "x").Expect("OK")
+	connection.Command("LINSERT", "list", "BEFORE", "x", "w").Expect(int64(2))
+	connection.Command("LINSERT", "list", "AFTER", "unknown", "y").Expect(int64(-1))
+	connection.Command("LREM", "list", int64(0), "w").Expect(int64(1))
+
+	client := mockClient(connection)
+
+	assert.Nil(t, client.LSet("list", 0, "x"))
+
+	length, err := client.LInsertBefore("list", "x", "w")
+	assert.Equal(t, length, int64(2))
+	assert.Nil(t, err)
+
+	length, err = client.LInsertAfter("list", "unknown", "y")
+	assert.Equal(t, length, int64(-1))
+	assert.Nil(t, err)
+
+	count, err := client.LRem("list", 0, "w")
+	assert.Equal(t, count, int64(1))
+	assert.Nil(t, err)
+}
+
+func TestClient_LMove(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("LMOVE", "source", "destination", "LEFT", "RIGHT").Expect([]byte("a"))
+	connection.Command("BLMOVE", "source", "destination", "RIGHT", "LEFT", float64(1)).Expect(nil)
+
+	client := mockBlockingClient(connection)
+
+	value, ok, err := client.LMove("source", "destination", ListLeft, ListRight)
+	assert.Equal(t, value, "a")
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	value, ok, err = client.BLMove("source", "destination", ListRight, ListLeft, time.Second)
+	assert.Equal(t, value, "")
+	assert.False(t, ok)
+	assert.Nil(t, err)
+}
+
+func TestClient_BLPop(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("BLPOP", "a", "b", 0.5).Expect([]interface{}{[]byte("b"), []byte("value")})
+	connection.Command("BRPOP", "a", float64(0)).Expect(nil)
+	connection.Command("BRPOP", "b", float64(0)).ExpectError(errors.New("Oops"))
+
+	client := mockBlockingClient(connection)
+
+	key, value, ok, err := client.BLPop(500*time.Millisecond, "a", "b")
+	assert.Equal(t, key, "b")
+	assert.Equal(t, value, "value")
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	key, value, ok, err = client.BRPop(0, "a")
+	assert.Equal(t, key, "")
+	assert.Equal(t, value, "")
+	assert.False(t, ok)
+	assert.Nil(t, err)
+
+	_, _, ok, err = client.BRPop(0, "b")
+	assert.False(t, ok)
+	assert.NotNil(t, err)
+}
@@ -22,12 +22,18 @@ const (
 	defaultTestOnBorrowTimeout   = time.Minute
 
 	addressFormat = "%s:%d"
+
+	authCommand = "AUTH"
 )
 
+// CredentialsProvider returns the username and password a new connection authenticates with
+type CredentialsProvider func() (username string, password string, err error)
+
 // Options contains redis options
 type Options struct {
 	Host                  string
 	Port                  int
+	Username              string
 	Password              string
 	Database              int
 	Network               string
@@ -41,6 +47,10 @@ type Options struct {
 	TlsConfig             *tls.Config
 	TlsSkipVerify         bool
 	TestOnBorrowPeriod    time.Duration
+	ScriptRegistry        *ScriptRegistry
+	ClientName            string
+	LibraryInfo           bool
+	CredentialsProvider   CredentialsProvider
 }
 
 // GetAddress returns address
@@ -64,6 +74,11 @@ func (o *Options) GetPort() int {
 	return o.Port
 }
 
+// GetUsername returns the ACL username
+func (o *Options) GetUsername() string {
+	return o.Username
+}
+
 // GetPassword returns password
 func (o *Options) GetPassword() string {
 	if len(o.Password) == 0 {
@@ -159,6 +174,29 @@ func (o *Options) GetTestOnBorrowPeriod() time.Duration {
 	return o.TestOnBorrowPeriod
 }
 
+// GetScriptRegistry returns the registry of scripts loaded on every new connection
+func (o *Options) GetScriptRegistry() *ScriptRegistry {
+	return o.ScriptRegistry
+}
+
+// GetClientName returns the name given to every new connection
+func (o *Options) GetClientName() string {
+	return o.ClientName
+}
+
+// GetLibraryInfo returns whether new connections report the library's name and version
+func (o *Options) GetLibraryInfo() bool {
+	return o.LibraryInfo
+}
+
+// GetCredentialsProvider returns the provider of every new connection's credentials, the username and password by default
+func (o *Options) GetCredentialsProvider() CredentialsProvider {
+	if o.CredentialsProvider != nil {
+		return o.CredentialsProvider
+	}
+	return staticCredentials(o.GetUsername(), o.GetPassword())
+}
+
 func newServerPool(options *Options) *redis.Pool {
 	connectionIdleTimeout := options.GetConnectionIdleTimeout()
 	connectionMaxActive := options.GetConnectionMaxActive()
@@ -170,7 +208,7 @@ func newServerPool(options *Options) *redis.Pool {
 		MaxActive:    connectionMaxActive,
 		MaxIdle:      connectionMaxIdle,
 		Wait:         connectionWait,
-		Dial:         serverDial(options),
+		Dial:         interruptibleDial(serverDial(options)),
 		TestOnBorrow: serverTestOnBorrow(options),
 	}
 }
@@ -178,22 +216,108 @@ func newServerPool(options *Options) *redis.Pool {
 func serverDial(options *Options) func() (redis.Conn, error) {
 	network := options.GetNetwork()
 	address := options.GetAddress()
+	setup := connectionSetup{
+		credentials:    options.GetCredentialsProvider(),
+		database:       options.GetDatabase(),
+		clientName:     options.GetClientName(),
+		libraryInfo:    options.GetLibraryInfo(),
+		scriptRegistry: options.GetScriptRegistry(),
+	}
 
-	dialOptions := make([]redis.DialOption, 7)
-	dialOptions[0] = redis.DialPassword(options.GetPassword())
-	dialOptions[1] = redis.DialDatabase(options.GetDatabase())
-	dialOptions[2] = redis.DialConnectTimeout(options.GetConnectTimeout())
-	dialOptions[3] = redis.DialWriteTimeout(options.GetWriteTimeout())
-	dialOptions[4] = redis.DialReadTimeout(options.GetReadTimeout())
-	dialOptions[5] = redis.DialTLSSkipVerify(options.GetTlsSkipVerify())
-	dialOptions[6] = redis.DialTLSConfig(options.GetTlsConfig())
+	dialOptions := make([]redis.DialOption, 5)
+	dialOptions[0] = redis.DialConnectTimeout(options.GetConnectTimeout())
+	dialOptions[1] = redis.DialWriteTimeout(options.GetWriteTimeout())
+	dialOptions[2] = redis.DialReadTimeout(options.GetReadTimeout())
+	dialOptions[3] = redis.DialTLSSkipVerify(options.GetTlsSkipVerify())
+	dialOptions[4] = redis.DialTLSConfig(options.GetTlsConfig())
 
 	return func() (redis.Conn, error) {
 		connection, err := redis.Dial(network, address, dialOptions...)
 		if err != nil {
 			return nil, err
 		}
-		return connection, nil
+		return setup.prepare(connection)
+	}
+}
+
+// connectionSetup contains the steps a new connection goes through before it is used
+type connectionSetup struct {
+	credentials    CredentialsProvider
+	database       int
+	clientName     string
+	libraryInfo    bool
+	readOnly       bool
+	scriptRegistry *ScriptRegistry
+}
+
+// prepare logs in, identifies, marks read only if asked to and loads the scripts on a new connection,
+// closing it if any step fails
+func (s connectionSetup) prepare(connection redis.Conn) (redis.Conn, error) {
+	err := s.run(connection)
+	if err != nil {
+		connection.Close()
+		return nil, err
+	}
+	return connection, nil
+}
+
+func (s connectionSetup) run(connection redis.Conn) error {
+	err := login(connection, s.credentials, s.database)
+	if err != nil {
+		return err
+	}
+
+	err = identify(connection, s.clientName, s.libraryInfo)
+	if err != nil {
+		return err
+	}
+
+	if s.readOnly {
+		_, err = connection.Do(readOnlyCommand)
+		if err != nil {
+			return err
+		}
+	}
+	return s.scriptRegistry.load(connection)
+}
+
+// login authenticates a new connection with the provided credentials and selects the database
+func login(connection redis.Conn, credentials CredentialsProvider, database int) error {
+	username, password, err := credentials()
+	if err != nil {
+		return err
+	}
+
+	err = authenticate(connection, username, password)
+	if err != nil {
+		return err
+	}
+
+	if database == 0 {
+		return nil
+	}
+
+	_, err = connection.Do(selectCommand, database)
+	return err
+}
+
+// authenticate authenticates a new connection as the username if one is provided, even with an empty password,
+// or else with the password if one is provided
+func authenticate(connection redis.Conn, username string, password string) error {
+	args := []interface{}{password}
+	if len(username) > 0 {
+		args = []interface{}{username, password}
+	} else if len(password) == 0 {
+		return nil
+	}
+
+	_, err := connection.Do(authCommand, args...)
+	return err
+}
+
+func staticCredentials(username string, password string) CredentialsProvider {
+	return func() (string, string, error) {
+		return username, password, nil
 	}
 }
 
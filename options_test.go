@@ -2,6 +2,9 @@ package xredis
 
 import (
 	"crypto/tls"
+	"errors"
+	"github.com/garyburd/redigo/redis"
+	"github.com/rafaeljusto/redigomock"
 	"github.com/stretchr/testify/assert"
 	"testing"
 	"time"
@@ -149,3 +152,159 @@ func TestOptions_GetTestOnBorrowPeriod(t *testing.T) {
 	options = Options{TestOnBorrowPeriod: -1}
 	assert.Equal(t, options.GetTestOnBorrowPeriod(), defaultTestOnBorrowTimeout)
 }
+
+func TestOptions_GetScriptRegistry(t *testing.T) {
+	options := Options{}
+	assert.Nil(t, options.GetScriptRegistry())
+
+	registry := NewScriptRegistry()
+	options = Options{ScriptRegistry: registry}
+	assert.Equal(t, options.GetScriptRegistry(), registry)
+}
+
+func TestOptions_GetClientName(t *testing.T) {
+	options := Options{}
+	assert.Equal(t, options.GetClientName(), "")
+	assert.False(t, options.GetLibraryInfo())
+
+	options = Options{ClientName: "worker", LibraryInfo: true}
+	assert.Equal(t, options.GetClientName(), "worker")
+	assert.True(t, options.GetLibraryInfo())
+}
+
+func TestAuthenticate(t *testing.T) {
+	connection := redigomock.NewConn()
+	assert.Nil(t, authenticate(connection, "", ""))
+
+	connection = redigomock.NewConn()
+	auth := connection.Command("AUTH", "user", "").Expect("OK")
+	assert.Nil(t, authenticate(connection, "user", ""))
+	assert.Equal(t, connection.Stats(auth), 1)
+
+	connection = redigomock.NewConn()
+	auth = connection.Command("AUTH", "secret").Expect("OK")
+	assert.Nil(t, authenticate(connection, "", "secret"))
+	assert.Equal(t, connection.Stats(auth), 1)
+
+	connection = redigomock.NewConn()
+	auth = connection.Command("AUTH", "user", "secret").Expect("OK")
+	assert.Nil(t, authenticate(connection, "user", "secret"))
+	assert.Equal(t, connection.Stats(auth), 1)
+
+	connection = redigomock.NewConn()
+	connection.Command("AUTH", "user", "wrong").ExpectError(redis.Error("WRONGPASS invalid username-password pair or user is disabled."))
+	assert.NotNil(t, authenticate(connection, "user", "wrong"))
+}
+
+func TestOptions_GetCredentialsProvider(t *testing.T) {
+	options := Options{}
+	assert.Equal(t, options.GetUsername(), "")
+
+	username, password, err := options.GetCredentialsProvider()()
+	assert.Equal(t, username, "")
+	assert.Equal(t, password, defaultPassword)
+	assert.Nil(t, err)
+
+	options = Options{Username: "user", Password: "secret"}
+	assert.Equal(t, options.GetUsername(), "user")
+
+	username, password, err = options.GetCredentialsProvider()()
+	assert.Equal(t, username, "user")
+	assert.Equal(t, password, "secret")
+	assert.Nil(t, err)
+
+	rotations := 0
+	options = Options{Username: "user", Password: "secret", CredentialsProvider: func() (string, string, error) {
+		rotations++
+		return "rotated", "token", nil
+	}}
+
+	username, password, err = options.GetCredentialsProvider()()
+	assert.Equal(t, username, "rotated")
+	assert.Equal(t, password, "token")
+	assert.Nil(t, err)
+	assert.Equal(t, rotations, 1)
+}
+
+type closeRecordingConnection struct {
+	redis.Conn
+	closed bool
+}
+
+func (c *closeRecordingConnection) Close() error {
+	c.closed = true
+	return c.Conn.Close()
+}
+
+func TestConnectionSetup_Prepare(t *testing.T) {
+	registry := NewScriptRegistry()
+	registry.Register("return 1")
+
+	setup := connectionSetup{
+		credentials:    staticCredentials("user", "secret"),
+		database:       2,
+		clientName:     "worker",
+		readOnly:       true,
+		scriptRegistry: registry,
+	}
+
+	mock := redigomock.NewConn()
+	auth := mock.Command("AUTH", "user", "secret").Expect("OK")
+	selection := mock.Command("SELECT", 2).Expect("OK")
+	name := mock.Command("CLIENT", "SETNAME", "worker").Expect("OK")
+	readOnly := mock.Command("READONLY").Expect("OK")
+	load := mock.Command("SCRIPT", "LOAD", "return 1").Expect("e0e1f9fabfc9d4800c877a703b823ac0578ff8db")
+
+	connection := &closeRecordingConnection{Conn: mock}
+	prepared, err := setup.prepare(connection)
+	assert.Nil(t, err)
+	assert.Equal(t, prepared, redis.Conn(connection))
+	assert.False(t, connection.closed)
+	for _, command := range []*redigomock.Cmd{auth, selection, name, readOnly, load} {
+		assert.Equal(t, mock.Stats(command), 1)
+	}
+
+	mock = redigomock.NewConn()
+	mock.Command("AUTH", "user", "secret").Expect("OK")
+	mock.Command("SELECT", 2).Expect("OK")
+	mock.Command("CLIENT", "SETNAME", "worker").ExpectError(errors.New("connection reset"))
+	readOnly = mock.Command("READONLY").Expect("OK")
+
+	connection = &closeRecordingConnection{Conn: mock}
+	prepared, err = setup.prepare(connection)
+	assert.Nil(t, prepared)
+	assert.Equal(t, err, errors.New("connection reset"))
+	assert.True(t, connection.closed)
+	assert.Equal(t, mock.Stats(readOnly), 0)
+}
+
+func TestLogin(t *testing.T) {
+	connection := redigomock.NewConn()
+	auth := connection.Command("AUTH", "user", "secret").Expect("OK")
+	selection := connection.Command("SELECT", 2).Expect("OK")
+	assert.Nil(t, login(connection, staticCredentials("user", "secret"), 2))
+	assert.Equal(t, connection.Stats(auth), 1)
+	assert.Equal(t, connection.Stats(selection), 1)
+
+	connection = redigomock.NewConn()
+	assert.Nil(t, login(connection, staticCredentials("", ""), 0))
+
+	connection = redigomock.NewConn()
+	auth = connection.Command("AUTH", "nopass", "").Expect("OK")
+	assert.Nil(t, login(connection, staticCredentials("nopass", ""), 0))
+	assert.Equal(t, connection.Stats(auth), 1)
+
+	connection = redigomock.NewConn()
+	auth = connection.GenericCommand("AUTH").Expect("OK")
+	selection = connection.GenericCommand("SELECT").Expect("OK")
+	err := login(connection, func() (string, string, error) {
+		return "", "", errors.New("secrets agent unavailable")
+	}, 2)
+	assert.Equal(t, err, errors.New("secrets agent unavailable"))
+	assert.Equal(t, connection.Stats(auth), 0)
+	assert.Equal(t, connection.Stats(selection), 0)
+
+	connection = redigomock.NewConn()
+	connection.Command("AUTH", "user", "expired").ExpectError(redis.Error("WRONGPASS invalid username-password pair or user is disabled."))
+	assert.NotNil(t, login(connection, staticCredentials("user", "expired"), 2))
+}
@@ -0,0 +1,351 @@
+package xredis
+
+import (
+	"github.com/garyburd/redigo/redis"
+)
+
+// Pipeline queues commands and sends them over a single connection in one round trip
+type Pipeline struct {
+	client   *Client
+	commands []*pipelineCommand
+}
+
+type pipelineCommand struct {
+	name   string
+	args   []interface{}
+	result pipelineResult
+}
+
+type pipelineResult interface {
+	set(reply interface{}, err error)
+}
+
+// Pipeline returns a new pipeline
+func (c *Client) Pipeline() *Pipeline {
+	return &Pipeline{client: c}
+}
+
+// Len returns the number of queued commands
+func (p *Pipeline) Len() int {
+	return len(p.commands)
+}
+
+// Discard drops the queued commands
+func (p *Pipeline) Discard() {
+	p.commands = nil
+}
+
+// Exec sends the queued commands and reads their replies into their results.
+// The returned error is a transport error, command errors are reported by each result
+func (p *Pipeline) Exec() error {
+	commands := p.commands
+	p.commands = nil
+
+	if len(commands) == 0 {
+		return nil
+	}
+
+	connection := p.client.getWriteConnection()
+	defer connection.Close()
+
+	return execCommands(connection, commands)
+}
+
+// Ping queues a ping
+func (p *Pipeline) Ping() *StringResult {
+	return p.queueString(pingCommand)
+}
+
+// Set queues setting a key/value pair
+func (p *Pipeline) Set(key string, value string) *BoolResult {
+	return p.queueBool(toBool, setCommand, key, value)
+}
+
+// SetNx queues setting a key/value pair if the key does not exist
+func (p *Pipeline) SetNx(key string, value string) *BoolResult {
+	return p.queueBool(toBool, setCommand, key, value, notExistsOption)
+}
+
+// SetEx queues setting a key/value pair with a timeout in seconds
+func (p *Pipeline) SetEx(key string, value string, timeout int64) *BoolResult {
+	return p.queueBool(toBool, setCommand, key, value, expireOption, timeout)
+}
+
+// Get queues retrieving a key's value
+func (p *Pipeline) Get(key string) *StringResult {
+	return p.queueString(getCommand, key)
+}
+
+// Append queues appending to a key's value
+func (p *Pipeline) Append(key string, value string) *IntResult {
+	return p.queueInt(appendCommand, key, value)
+}
+
+// Expire queues setting a key's timeout in seconds
+func (p *Pipeline) Expire(key string, timeout int64) *BoolResult {
+	return p.queueBool(toPositive, expireCommand, key, timeout)
+}
+
+// Exists queues checking whether keys exist
+func (p *Pipeline) Exists(keys ...string) *BoolResult {
+	return p.queueBool(toPositive, existsCommand, toInterfaces(keys)...)
+}
+
+// Del queues deleting keys
+func (p *Pipeline) Del(keys ...string) *IntResult {
+	return p.queueInt(delCommand, toInterfaces(keys)...)
+}
+
+// Incr queues incrementing the key's value
+func (p *Pipeline) Incr(key string) *IntResult {
+	return p.IncrBy(key, 1)
+}
+
+// IncrBy queues incrementing the key's value by the increment provided
+func (p *Pipeline) IncrBy(key string, increment int64) *IntResult {
+	return p.queueInt(incrByCommand, key, increment)
+}
+
+// IncrByFloat queues incrementing the key's value by the increment provided
+func (p *Pipeline) IncrByFloat(key string, increment float64) *FloatResult {
+	return p.queueFloat(incrByFloatCommand, key, increment)
+}
+
+// Decr queues decrementing the key's value
+func (p *Pipeline) Decr(key string) *IntResult {
+	return p.IncrBy(key, -1)
+}
+
+// DecrBy queues decrementing the key's value by the decrement provided
+func (p *Pipeline) DecrBy(key string, decrement int64) *IntResult {
+	return p.IncrBy(key, -decrement)
+}
+
+// HSet queues setting a key's field/value pair
+func (p *Pipeline) HSet(key string, field string, value string) *BoolResult {
+	return p.queueBool(toPositive, hSetCommand, key, field, value)
+}
+
+// HGet queues retrieving a key's field's value
+func (p *Pipeline) HGet(key string, field string) *StringResult {
+	return p.queueString(hGetCommand, key, field)
+}
+
+// HGetAll queues retrieving a key's fields and values
+func (p *Pipeline) HGetAll(key string) *StringMapResult {
+	result := &StringMapResult{}
+	p.queue(result, hGetAllCommand, key)
+	return result
+}
+
+// HExists queues determining a key's field's existence
+func (p *Pipeline) HExists(key string, field string) *BoolResult {
+	return p.queueBool(redis.Bool, hExistsCommand, key, field)
+}
+
+// HDel queues deleting a key's fields
+func (p *Pipeline) HDel(key string, fields ...string) *IntResult {
+	return p.queueInt(hDelCommand, prepend(key, fields)...)
+}
+
+// HIncrBy queues incrementing the key's field's value by the increment provided
+func (p *Pipeline) HIncrBy(key string, field string, increment int64) *IntResult {
+	return p.queueInt(hIncrByCommand, key, field, increment)
+}
+
+// HIncrByFloat queues incrementing the key's field's value by the increment provided
+func (p *Pipeline) HIncrByFloat(key string, field string, increment float64) *FloatResult {
+	return p.queueFloat(hIncrByFloatCommand, key, field, increment)
+}
+
+// Do queues an arbitrary command
+func (p *Pipeline) Do(commandName string, args ...interface{}) *Result {
+	result := &Result{}
+	p.queue(result, commandName, args...)
+	return result
+}
+
+func (p *Pipeline) queue(result pipelineResult, commandName string, args ...interface{}) {
+	p.commands = append(p.commands, &pipelineCommand{name: commandName, args: args, result: result})
+}
+
+func (p *Pipeline) queueString(commandName string, args ...interface{}) *StringResult {
+	result := &StringResult{}
+	p.queue(result, commandName, args...)
+	return result
+}
+
+func (p *Pipeline) queueBool(convert func(interface{}, error) (bool, error), commandName string, args ...interface{}) *BoolResult {
+	result := &BoolResult{convert: convert}
+	p.queue(result, commandName, args...)
+	return result
+}
+
+func (p *Pipeline) queueInt(commandName string, args ...interface{}) *IntResult {
+	result := &IntResult{}
+	p.queue(result, commandName, args...)
+	return result
+}
+
+func (p *Pipeline) queueFloat(commandName string, args ...interface{}) *FloatResult {
+	result := &FloatResult{}
+	p.queue(result, commandName, args...)
+	return result
+}
+
+func execCommands(connection redis.Conn, commands []*pipelineCommand) error {
+	for _, command := range commands {
+		err := connection.Send(command.name, command.args...)
+		if err != nil {
+			return failCommands(commands, err)
+		}
+	}
+
+	err := connection.Flush()
+	if err != nil {
+		return failCommands(commands, err)
+	}
+
+	for index, command := range commands {
+		reply, err := connection.Receive()
+		if err != nil && !isCommandError(err) {
+			return failCommands(commands[index:], err)
+		}
+		command.result.set(reply, err)
+	}
+	return nil
+}
+
+func failCommands(commands []*pipelineCommand, err error) error {
+	for _, command := range commands {
+		command.result.set(nil, err)
+	}
+	return err
+}
+
+func isCommandError(err error) bool {
+	_, ok := err.(redis.Error)
+	return ok
+}
+
+// Result holds the raw reply of a pipelined command
+type Result struct {
+	value interface{}
+	err   error
+}
+
+// Result returns the raw reply
+func (r *Result) Result() (interface{}, error) {
+	return r.value, r.err
+}
+
+// Err returns the command's error
+func (r *Result) Err() error {
+	return r.err
+}
+
+func (r *Result) set(reply interface{}, err error) {
+	r.value, r.err = reply, err
+}
+
+// StringResult holds the reply of a pipelined command returning a string
+type StringResult struct {
+	value string
+	found bool
+	err   error
+}
+
+// Result returns the value, whether it exists and the command's error
+func (r *StringResult) Result() (string, bool, error) {
+	return r.value, r.found, r.err
+}
+
+// Err returns the command's error
+func (r *StringResult) Err() error {
+	return r.err
+}
+
+func (r *StringResult) set(reply interface{}, err error) {
+	r.value, r.found, r.err = toString(reply, err)
+}
+
+// BoolResult holds the reply of a pipelined command returning a boolean
+type BoolResult struct {
+	value   bool
+	err     error
+	convert func(interface{}, error) (bool, error)
+}
+
+// Result returns the value and the command's error
+func (r *BoolResult) Result() (bool, error) {
+	return r.value, r.err
+}
+
+// Err returns the command's error
+func (r *BoolResult) Err() error {
+	return r.err
+}
+
+func (r *BoolResult) set(reply interface{}, err error) {
+	r.value, r.err = r.convert(reply, err)
+}
+
+// IntResult holds the reply of a pipelined command returning an integer
+type IntResult struct {
+	value int64
+	err   error
+}
+
+// Result returns the value and the command's error
+func (r *IntResult) Result() (int64, error) {
+	return r.value, r.err
+}
+
+// Err returns the command's error
+func (r *IntResult) Err() error {
+	return r.err
+}
+
+func (r *IntResult) set(reply interface{}, err error) {
+	r.value, r.err = redis.Int64(reply, err)
+}
+
+// FloatResult holds the reply of a pipelined command returning a float
+type FloatResult struct {
+	value float64
+	err   error
+}
+
+// Result returns the value and the command's error
+func (r *FloatResult) Result() (float64, error) {
+	return r.value, r.err
+}
+
+// Err returns the command's error
+func (r *FloatResult) Err() error {
+	return r.err
+}
+
+func (r *FloatResult) set(reply interface{}, err error) {
+	r.value, r.err = redis.Float64(reply, err)
+}
+
+// StringMapResult holds the reply of a pipelined command returning a map
+type StringMapResult struct {
+	value map[string]string
+	err   error
+}
+
+// Result returns the value and the command's error
+func (r *StringMapResult) Result() (map[string]string, error) {
+	return r.value, r.err
+}
+
+// Err returns the command's error
+func (r *StringMapResult) Err() error {
+	return r.err
+}
+
+func (r *StringMapResult) set(reply interface{}, err error) {
+	r.value, r.err = redis.StringMap(reply, err)
+}
@@ -0,0 +1,124 @@
+package xredis
+
+import (
+	"errors"
+	"github.com/garyburd/redigo/redis"
+	"github.com/rafaeljusto/redigomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPipeline_Exec(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("SET", "key", "value").Expect("OK")
+	connection.Command("GET", "key").Expect("value")
+	connection.Command("GET", "unknown").Expect(nil)
+	connection.Command("INCRBY", "counter", int64(1)).Expect(int64(1))
+	connection.Command("INCRBYFLOAT", "float", 5.5).Expect([]byte("5.5"))
+	connection.Command("HSET", "hash", "field", "value").Expect(int64(1))
+	connection.Command("HGETALL", "hash").ExpectMap(map[string]string{"field": "value"})
+	connection.Command("EXPIRE", "key", int64(10)).Expect(int64(1))
+	connection.Command("DEL", "key", "hash").Expect(int64(2))
+
+	client := mockClient(connection)
+
+	pipeline := client.Pipeline()
+	set := pipeline.Set("key", "value")
+	get := pipeline.Get("key")
+	unknown := pipeline.Get("unknown")
+	incr := pipeline.Incr("counter")
+	incrByFloat := pipeline.IncrByFloat("float", 5.5)
+	hSet := pipeline.HSet("hash", "field", "value")
+	hGetAll := pipeline.HGetAll("hash")
+	expire := pipeline.Expire("key", 10)
+	del := pipeline.Del("key", "hash")
+	assert.Equal(t, pipeline.Len(), 9)
+
+	assert.Nil(t, pipeline.Exec())
+	assert.Equal(t, pipeline.Len(), 0)
+
+	ok, err := set.Result()
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	value, found, err := get.Result()
+	assert.Equal(t, value, "value")
+	assert.True(t, found)
+	assert.Nil(t, err)
+
+	value, found, err = unknown.Result()
+	assert.Equal(t, value, "")
+	assert.False(t, found)
+	assert.Nil(t, err)
+
+	number, err := incr.Result()
+	assert.Equal(t, number, int64(1))
+	assert.Nil(t, err)
+
+	float, err := incrByFloat.Result()
+	assert.Equal(t, float, 5.5)
+	assert.Nil(t, err)
+
+	ok, err = hSet.Result()
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	hash, err := hGetAll.Result()
+	assert.Equal(t, hash["field"], "value")
+	assert.Nil(t, err)
+
+	ok, err = expire.Result()
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	number, err = del.Result()
+	assert.Equal(t, number, int64(2))
+	assert.Nil(t, err)
+}
+
+func TestPipeline_CommandError(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("INCRBY", "name", int64(1)).ExpectError(redis.Error("ERR value is not an integer or out of range"))
+	connection.Command("GET", "name").Expect("Raed")
+
+	client := mockClient(connection)
+
+	pipeline := client.Pipeline()
+	incr := pipeline.Incr("name")
+	get := pipeline.Get("name")
+
+	assert.Nil(t, pipeline.Exec())
+	assert.NotNil(t, incr.Err())
+
+	value, found, err := get.Result()
+	assert.Equal(t, value, "Raed")
+	assert.True(t, found)
+	assert.Nil(t, err)
+}
+
+func TestPipeline_TransportError(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.FlushMock = func() error {
+		return errors.New("Oops")
+	}
+
+	client := mockClient(connection)
+
+	pipeline := client.Pipeline()
+	get := pipeline.Get("key")
+	raw := pipeline.Do("PING")
+
+	assert.NotNil(t, pipeline.Exec())
+	assert.NotNil(t, get.Err())
+	assert.NotNil(t, raw.Err())
+}
+
+func TestPipeline_Empty(t *testing.T) {
+	client := mockClient(redigomock.NewConn())
+
+	pipeline := client.Pipeline()
+	pipeline.Ping()
+	pipeline.Discard()
+
+	assert.Nil(t, pipeline.Exec())
+}
@@ -0,0 +1,328 @@
+package xredis
+
+import (
+	"errors"
+	"github.com/garyburd/redigo/redis"
+	"sync"
+	"time"
+)
+
+const (
+	publishCommand = "PUBLISH"
+
+	subscriberClosedError = "subscriber closed"
+
+	defaultSubscriberPingInterval = 30 * time.Second
+	defaultSubscriberRetryBackoff = time.Second
+	defaultSubscriberBufferSize   = 100
+)
+
+// ErrSubscriberClosed is returned when using a closed subscriber
+var ErrSubscriberClosed = errors.New(subscriberClosedError)
+
+// Message is a message received on a channel, the pattern is set if it matched a pattern subscription
+type Message struct {
+	Channel string
+	Pattern string
+	Data    string
+}
+
+// SubscriberOptions contains subscriber options.
+// A negative BufferSize uses the default, while zero leaves the messages channel unbuffered.
+// PingInterval and RetryBackoff use the default unless positive, since zero would ping or reconnect without pausing
+type SubscriberOptions struct {
+	PingInterval time.Duration
+	RetryBackoff time.Duration
+	BufferSize   int
+	ErrorHandler func(err error)
+}
+
+// GetPingInterval returns how often the connection is pinged to detect failures
+func (o *SubscriberOptions) GetPingInterval() time.Duration {
+	if o.PingInterval <= 0 {
+		return defaultSubscriberPingInterval
+	}
+	return o.PingInterval
+}
+
+// GetRetryBackoff returns how long to wait before reconnecting
+func (o *SubscriberOptions) GetRetryBackoff() time.Duration {
+	if o.RetryBackoff <= 0 {
+		return defaultSubscriberRetryBackoff
+	}
+	return o.RetryBackoff
+}
+
+// GetBufferSize returns the size of the messages channel's buffer
+func (o *SubscriberOptions) GetBufferSize() int {
+	if o.BufferSize < 0 {
+		return defaultSubscriberBufferSize
+	}
+	return o.BufferSize
+}
+
+// Publish posts a message to a channel and returns the number of subscribers that received it
+func (c *Client) Publish(channel string, message string) (int64, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return redis.Int64(connection.Do(publishCommand, channel, message))
+}
+
+// Subscriber receives the messages of channels and patterns on a dedicated connection.
+// It reconnects and resubscribes after a connection failure or a master switch
+type Subscriber struct {
+	client     *Client
+	options    *SubscriberOptions
+	messages   chan Message
+	done       chan struct{}
+	waitGroup  sync.WaitGroup
+	mutex      sync.Mutex
+	connection *redis.PubSubConn
+	channels   map[string]bool
+	patterns   map[string]bool
+	closed     bool
+}
+
+// NewSubscriber returns a subscriber that starts listening in the background
+func (c *Client) NewSubscriber(options *SubscriberOptions) *Subscriber {
+	subscriber := &Subscriber{
+		client:   c,
+		options:  options,
+		messages: make(chan Message, options.GetBufferSize()),
+		done:     make(chan struct{}),
+		channels: map[string]bool{},
+		patterns: map[string]bool{},
+	}
+
+	subscriber.waitGroup.Add(1)
+	go subscriber.run()
+	return subscriber
+}
+
+// Messages returns the channel messages are delivered on. It is closed when the subscriber is closed
+func (s *Subscriber) Messages() <-chan Message {
+	return s.messages
+}
+
+// Subscribe subscribes to channels
+func (s *Subscriber) Subscribe(channels ...string) error {
+	return s.update(s.channels, true, channels, func(connection *redis.PubSubConn, args ...interface{}) error {
+		return connection.Subscribe(args...)
+	})
+}
+
+// PSubscribe subscribes to patterns
+func (s *Subscriber) PSubscribe(patterns ...string) error {
+	return s.update(s.patterns, true, patterns, func(connection *redis.PubSubConn, args ...interface{}) error {
+		return connection.PSubscribe(args...)
+	})
+}
+
+// Unsubscribe unsubscribes from channels
+func (s *Subscriber) Unsubscribe(channels ...string) error {
+	return s.update(s.channels, false, channels, func(connection *redis.PubSubConn, args ...interface{}) error {
+		return connection.Unsubscribe(args...)
+	})
+}
+
+// PUnsubscribe unsubscribes from patterns
+func (s *Subscriber) PUnsubscribe(patterns ...string) error {
+	return s.update(s.patterns, false, patterns, func(connection *redis.PubSubConn, args ...interface{}) error {
+		return connection.PUnsubscribe(args...)
+	})
+}
+
+// Close stops listening and closes the messages channel
+func (s *Subscriber) Close() error {
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		return nil
+	}
+
+	s.closed = true
+	close(s.done)
+	if s.connection != nil {
+		s.connection.Close()
+	}
+	s.mutex.Unlock()
+
+	s.waitGroup.Wait()
+	close(s.messages)
+	return nil
+}
+
+func (s *Subscriber) update(names map[string]bool, subscribe bool, values []string, command func(*redis.PubSubConn, ...interface{}) error) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed {
+		return ErrSubscriberClosed
+	}
+
+	for _, value := range values {
+		if subscribe {
+			names[value] = true
+		} else {
+			delete(names, value)
+		}
+	}
+
+	if s.connection == nil || len(values) == 0 {
+		return nil
+	}
+	return command(s.connection, toInterfaces(values)...)
+}
+
+func (s *Subscriber) run() {
+	defer s.waitGroup.Done()
+
+	for {
+		connection, address, err := s.connect()
+		if err == nil {
+			err = s.listen(connection, address)
+		}
+
+		select {
+		case <-s.done:
+			return
+		default:
+		}
+
+		s.report(err)
+
+		select {
+		case <-s.done:
+			return
+		case <-time.After(s.options.GetRetryBackoff()):
+		}
+	}
+}
+
+func (s *Subscriber) connect() (*redis.PubSubConn, string, error) {
+	address, err := s.client.masterAddress()
+	if err != nil {
+		return nil, "", err
+	}
+
+	conn, err := s.client.dial()
+	if err != nil {
+		return nil, "", err
+	}
+
+	connection := &redis.PubSubConn{Conn: conn}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed {
+		connection.Close()
+		return nil, "", ErrSubscriberClosed
+	}
+
+	if len(s.channels) > 0 {
+		err = connection.Subscribe(toInterfaces(channelNames(s.channels))...)
+	}
+	if err == nil && len(s.patterns) > 0 {
+		err = connection.PSubscribe(toInterfaces(channelNames(s.patterns))...)
+	}
+	if err != nil {
+		connection.Close()
+		return nil, "", err
+	}
+
+	s.connection = connection
+	return connection, address, nil
+}
+
+func (s *Subscriber) listen(connection *redis.PubSubConn, address string) error {
+	stop := make(chan struct{})
+	defer func() {
+		close(stop)
+
+		s.mutex.Lock()
+		s.connection = nil
+		s.mutex.Unlock()
+
+		connection.Close()
+	}()
+
+	go s.ping(connection, address, stop)
+
+	_, timeouts := connection.Conn.(redis.ConnWithTimeout)
+	readTimeout := s.options.GetPingInterval() + blockingReadTimeoutMargin
+
+	for {
+		var reply interface{}
+		if timeouts {
+			reply = connection.ReceiveWithTimeout(readTimeout)
+		} else {
+			reply = connection.Receive()
+		}
+
+		var message Message
+		switch value := reply.(type) {
+		case redis.Message:
+			message = Message{Channel: value.Channel, Data: string(value.Data)}
+		case redis.PMessage:
+			message = Message{Channel: value.Channel, Pattern: value.Pattern, Data: string(value.Data)}
+		case error:
+			return value
+		default:
+			continue
+		}
+
+		select {
+		case s.messages <- message:
+		case <-s.done:
+			return nil
+		}
+	}
+}
+
+func (s *Subscriber) ping(connection *redis.PubSubConn, address string, stop chan struct{}) {
+	ticker := time.NewTicker(s.options.GetPingInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		if s.masterChanged(address) {
+			connection.Close()
+			return
+		}
+
+		s.mutex.Lock()
+		err := connection.Ping("")
+		s.mutex.Unlock()
+
+		if err != nil {
+			connection.Close()
+			return
+		}
+	}
+}
+
+func (s *Subscriber) masterChanged(address string) bool {
+	current, err := s.client.masterAddress()
+	return err == nil && current != address
+}
+
+func (s *Subscriber) report(err error) {
+	if err != nil && s.options.ErrorHandler != nil {
+		s.options.ErrorHandler(err)
+	}
+}
+
+func channelNames(values map[string]bool) []string {
+	results := make([]string, 0, len(values))
+	for value := range values {
+		results = append(results, value)
+	}
+	return results
+}
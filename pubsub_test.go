@@ -0,0 +1,106 @@
+package xredis
+
+import (
+	"errors"
+	"github.com/garyburd/redigo/redis"
+	"github.com/rafaeljusto/redigomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestSubscriberOptions_Getters(t *testing.T) {
+	options := SubscriberOptions{}
+	assert.Equal(t, options.GetPingInterval(), defaultSubscriberPingInterval)
+	assert.Equal(t, options.GetRetryBackoff(), defaultSubscriberRetryBackoff)
+	assert.Equal(t, options.GetBufferSize(), 0)
+
+	options = SubscriberOptions{BufferSize: -1}
+	assert.Equal(t, options.GetBufferSize(), defaultSubscriberBufferSize)
+
+	options = SubscriberOptions{PingInterval: 1, RetryBackoff: 2, BufferSize: 3}
+	assert.Equal(t, options.GetPingInterval(), time.Duration(1))
+	assert.Equal(t, options.GetRetryBackoff(), time.Duration(2))
+	assert.Equal(t, options.GetBufferSize(), 3)
+}
+
+func TestClient_Publish(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("PUBLISH", "channel", "hello").Expect(int64(2))
+
+	client := mockClient(connection)
+
+	count, err := client.Publish("channel", "hello")
+	assert.Equal(t, count, int64(2))
+	assert.Nil(t, err)
+}
+
+func TestSubscriber(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("SUBSCRIBE", "channel").Expect([]interface{}{[]byte("subscribe"), []byte("channel"), int64(1)})
+	connection.Command("PSUBSCRIBE", "news.*").Expect([]interface{}{[]byte("psubscribe"), []byte("news.*"), int64(2)})
+	connection.AddSubscriptionMessage([]interface{}{[]byte("message"), []byte("channel"), []byte("hello")})
+	connection.AddSubscriptionMessage([]interface{}{[]byte("pmessage"), []byte("news.*"), []byte("news.sport"), []byte("goal")})
+
+	ready := make(chan struct{})
+	pool := &redis.Pool{
+		Dial: func() (redis.Conn, error) {
+			<-ready
+			return connection, nil
+		},
+	}
+
+	client := NewClient(pool)
+
+	subscriber := client.NewSubscriber(&SubscriberOptions{PingInterval: time.Hour, RetryBackoff: time.Hour})
+	assert.Nil(t, subscriber.Subscribe("channel"))
+	assert.Nil(t, subscriber.PSubscribe("news.*"))
+	close(ready)
+
+	assert.Equal(t, <-subscriber.Messages(), Message{Channel: "channel", Data: "hello"})
+	assert.Equal(t, <-subscriber.Messages(), Message{Channel: "news.sport", Pattern: "news.*", Data: "goal"})
+
+	assert.Nil(t, subscriber.Close())
+	assert.Nil(t, subscriber.Close())
+	assert.Equal(t, subscriber.Subscribe("channel"), ErrSubscriberClosed)
+
+	_, ok := <-subscriber.Messages()
+	assert.False(t, ok)
+}
+
+func TestSubscriber_Reconnect(t *testing.T) {
+	dials := 0
+	errs := make(chan error, 10)
+
+	pool := &redis.Pool{
+		Dial: func() (redis.Conn, error) {
+			dials++
+			if dials == 1 {
+				return nil, errors.New("Oops")
+			}
+
+			connection := redigomock.NewConn()
+			connection.Command("SUBSCRIBE", "channel").Expect([]interface{}{[]byte("subscribe"), []byte("channel"), int64(1)})
+			connection.AddSubscriptionMessage([]interface{}{[]byte("message"), []byte("channel"), []byte("hello")})
+			return connection, nil
+		},
+	}
+
+	client := NewClient(pool)
+
+	subscriber := client.NewSubscriber(&SubscriberOptions{
+		PingInterval: time.Hour,
+		RetryBackoff: time.Millisecond,
+		ErrorHandler: func(err error) {
+			select {
+			case errs <- err:
+			default:
+			}
+		},
+	})
+	subscriber.Subscribe("channel")
+
+	assert.NotNil(t, <-errs)
+	assert.Equal(t, <-subscriber.Messages(), Message{Channel: "channel", Data: "hello"})
+	assert.Nil(t, subscriber.Close())
+}
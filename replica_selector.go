@@ -0,0 +1,284 @@
+package xredis
+
+import (
+	"github.com/garyburd/redigo/redis"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	replicationSection = "replication"
+	linkUpStatus       = "up"
+
+	pingProbe = "ping:"
+	infoProbe = "info:"
+
+	defaultReplicaProbeTTL = time.Second
+)
+
+// ReplicaSelector chooses the replica a new read connection goes to among the available ones.
+// If it does not choose any, the read connection goes to the master
+type ReplicaSelector interface {
+	Select(replicas []string, probe *ReplicaProbe) (string, bool)
+}
+
+// ReplicaProbe gives selectors access to the replicas' state. The results of pings and of replication information
+// requests are reused for a second so that every new read connection does not dial and authenticate to each replica
+type ReplicaProbe struct {
+	dial    func(address string) (redis.Conn, error)
+	master  func() (string, error)
+	monitor *sentinelMonitor
+	ttl     time.Duration
+	mutex   sync.Mutex
+	results map[string]probeResult
+}
+
+// probeResult is the outcome of a ping or of a replication information request
+type probeResult struct {
+	latency time.Duration
+	info    *ServerInfo
+	err     error
+	time    time.Time
+}
+
+// Ping returns how long a replica takes to answer a PING on a new connection
+func (p *ReplicaProbe) Ping(address string) (time.Duration, error) {
+	result := p.cached(pingProbe+address, func() probeResult {
+		latency, err := p.ping(address)
+		return probeResult{latency: latency, err: err}
+	})
+	return result.latency, result.err
+}
+
+// Info returns a replica's replication information
+func (p *ReplicaProbe) Info(address string) (*ServerInfo, error) {
+	result := p.cached(infoProbe+address, func() probeResult {
+		info, err := p.info(address)
+		return probeResult{info: info, err: err}
+	})
+	return result.info, result.err
+}
+
+// MasterInfo returns the master's replication information, which lists the replicas it sees and their offsets
+func (p *ReplicaProbe) MasterInfo() (*ServerInfo, error) {
+	address, err := p.master()
+	if err != nil {
+		return nil, err
+	}
+	return p.Info(address)
+}
+
+// Connections returns the number of open pooled connections to a replica, whether idle or in use
+func (p *ReplicaProbe) Connections(address string) int {
+	return p.monitor.connections(address)
+}
+
+// cached returns the result of the probe if it was taken recently, or takes it again otherwise
+func (p *ReplicaProbe) cached(key string, probe func() probeResult) probeResult {
+	p.mutex.Lock()
+	result, ok := p.results[key]
+	p.mutex.Unlock()
+
+	if ok && time.Since(result.time) < p.ttl {
+		return result
+	}
+
+	result = probe()
+	result.time = time.Now()
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.results == nil {
+		p.results = map[string]probeResult{}
+	}
+	p.results[key] = result
+	return result
+}
+
+func (p *ReplicaProbe) ping(address string) (time.Duration, error) {
+	connection, err := p.dial(address)
+	if err != nil {
+		return 0, err
+	}
+	defer connection.Close()
+
+	start := time.Now()
+	_, err = connection.Do(pingCommand)
+	if err != nil {
+		return 0, err
+	}
+	return time.Since(start), nil
+}
+
+func (p *ReplicaProbe) info(address string) (*ServerInfo, error) {
+	connection, err := p.dial(address)
+	if err != nil {
+		return nil, err
+	}
+	defer connection.Close()
+
+	info, err := redis.String(connection.Do(infoCommand, replicationSection))
+	if err != nil {
+		return nil, err
+	}
+	return ParseInfo(info), nil
+}
+
+// RandomSelector chooses a random replica
+type RandomSelector struct{}
+
+// Select chooses a random replica
+func (s RandomSelector) Select(replicas []string, probe *ReplicaProbe) (string, bool) {
+	if len(replicas) == 0 {
+		return "", false
+	}
+	return replicas[rand.Intn(len(replicas))], true
+}
+
+// RoundRobinSelector chooses the replicas in turn
+type RoundRobinSelector struct {
+	next uint64
+}
+
+// Select chooses the replica after the previously chosen one
+func (s *RoundRobinSelector) Select(replicas []string, probe *ReplicaProbe) (string, bool) {
+	if len(replicas) == 0 {
+		return "", false
+	}
+
+	next := atomic.AddUint64(&s.next, 1) - 1
+	return replicas[next%uint64(len(replicas))], true
+}
+
+// LeastLatencySelector chooses the replica that answers a PING the fastest, skipping the ones that do not answer
+type LeastLatencySelector struct{}
+
+// Select pings every replica and chooses the fastest one
+func (s LeastLatencySelector) Select(replicas []string, probe *ReplicaProbe) (string, bool) {
+	var selected string
+	var fastest time.Duration
+	for _, replica := range replicas {
+		latency, err := probe.Ping(replica)
+		if err != nil {
+			continue
+		}
+
+		if len(selected) == 0 || latency < fastest {
+			selected = replica
+			fastest = latency
+		}
+	}
+	return selected, len(selected) > 0
+}
+
+// LeastConnectionsSelector chooses the replica with the fewest open pooled connections.
+// Idle connections count as much as the ones in use, so it balances the connections rather than the requests in flight
+type LeastConnectionsSelector struct{}
+
+// Select chooses the replica with the fewest open pooled connections
+func (s LeastConnectionsSelector) Select(replicas []string, probe *ReplicaProbe) (string, bool) {
+	if len(replicas) == 0 {
+		return "", false
+	}
+
+	selected := replicas[0]
+	fewest := probe.Connections(selected)
+	for _, replica := range replicas[1:] {
+		connections := probe.Connections(replica)
+		if connections < fewest {
+			selected = replica
+			fewest = connections
+		}
+	}
+	return selected, true
+}
+
+// SameZoneSelector prefers the replicas in the zone provided, using the replicas' addresses to find their zones.
+// The next selector, random by default, chooses among the preferred replicas or among all of them if none is in the zone
+type SameZoneSelector struct {
+	Zone  string
+	Zones map[string]string
+	Next  ReplicaSelector
+}
+
+// Select chooses a replica in the same zone if any
+func (s SameZoneSelector) Select(replicas []string, probe *ReplicaProbe) (string, bool) {
+	var local []string
+	for _, replica := range replicas {
+		if zone, ok := s.Zones[replica]; ok && zone == s.Zone {
+			local = append(local, replica)
+		}
+	}
+
+	if len(local) == 0 {
+		local = replicas
+	}
+	return nextSelector(s.Next).Select(local, probe)
+}
+
+// HealthySelector excludes the replicas whose link to the master is down and, if maximums are set, the ones the master
+// last heard from longer than MaxLag ago or whose replication offset is more than MaxOffsetLag bytes behind the master's.
+// Replicas are excluded if a maximum is set but the master cannot be probed.
+// The next selector, random by default, chooses among the remaining replicas
+type HealthySelector struct {
+	MaxLag       time.Duration
+	MaxOffsetLag int64
+	Next         ReplicaSelector
+}
+
+// Select chooses a healthy replica if any
+func (s HealthySelector) Select(replicas []string, probe *ReplicaProbe) (string, bool) {
+	var master *InfoReplication
+	if s.MaxLag > 0 || s.MaxOffsetLag > 0 {
+		info, err := probe.MasterInfo()
+		if err != nil {
+			return nextSelector(s.Next).Select(nil, probe)
+		}
+		master = &info.Replication
+	}
+
+	var healthy []string
+	for _, replica := range replicas {
+		info, err := probe.Info(replica)
+		if err != nil {
+			continue
+		}
+
+		replication := info.Replication
+		if replication.MasterLinkStatus != linkUpStatus {
+			continue
+		}
+		if master != nil && !s.caughtUp(master, replica, replication.ReplicaReplOffset) {
+			continue
+		}
+		healthy = append(healthy, replica)
+	}
+	return nextSelector(s.Next).Select(healthy, probe)
+}
+
+// caughtUp checks the replica's lag as seen by the master and how far its offset is behind the master's
+func (s HealthySelector) caughtUp(master *InfoReplication, replica string, offset int64) bool {
+	if s.MaxOffsetLag > 0 && master.MasterReplOffset-offset > s.MaxOffsetLag {
+		return false
+	}
+	if s.MaxLag <= 0 {
+		return true
+	}
+
+	for _, seen := range master.Replicas {
+		if seen.Address == replica {
+			return seen.Lag <= s.MaxLag
+		}
+	}
+	return false
+}
+
+func nextSelector(selector ReplicaSelector) ReplicaSelector {
+	if selector == nil {
+		return RandomSelector{}
+	}
+	return selector
+}
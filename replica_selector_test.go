@@ -0,0 +1,234 @@
+package xredis
+
+import (
+	"errors"
+	"github.com/garyburd/redigo/redis"
+	"github.com/rafaeljusto/redigomock"
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// slowConnection delays its commands to simulate a distant replica
+type slowConnection struct {
+	redis.Conn
+	delay time.Duration
+}
+
+func (c slowConnection) Do(commandName string, args ...interface{}) (interface{}, error) {
+	time.Sleep(c.delay)
+	return c.Conn.Do(commandName, args...)
+}
+
+func mockProbe(connections map[string]redis.Conn) *ReplicaProbe {
+	return &ReplicaProbe{
+		dial: func(address string) (redis.Conn, error) {
+			connection, ok := connections[address]
+			if !ok {
+				return nil, errors.New("connection refused")
+			}
+			return connection, nil
+		},
+		master: func() (string, error) {
+			return "master:1", nil
+		},
+	}
+}
+
+func replicationInfo(status string, offset int64) redis.Conn {
+	connection := redigomock.NewConn()
+	connection.Command("INFO", "replication").Expect("# Replication\r\nrole:slave\r\nmaster_link_status:" + status + "\r\nslave_repl_offset:" + strconv.FormatInt(offset, 10) + "\r\n")
+	return connection
+}
+
+func masterReplicationInfo(offset int64, replicas ...string) redis.Conn {
+	info := "# Replication\r\nrole:master\r\nmaster_repl_offset:" + strconv.FormatInt(offset, 10) + "\r\n"
+	for i, replica := range replicas {
+		info += "slave" + strconv.Itoa(i) + ":" + replica + "\r\n"
+	}
+
+	connection := redigomock.NewConn()
+	connection.Command("INFO", "replication").Expect(info)
+	return connection
+}
+
+func TestRandomSelector(t *testing.T) {
+	selector := RandomSelector{}
+
+	_, ok := selector.Select(nil, nil)
+	assert.False(t, ok)
+
+	replica, ok := selector.Select([]string{"a:1", "b:1"}, nil)
+	assert.True(t, ok)
+	assert.Contains(t, []string{"a:1", "b:1"}, replica)
+}
+
+func TestRoundRobinSelector(t *testing.T) {
+	selector := &RoundRobinSelector{}
+	replicas := []string{"a:1", "b:1", "c:1"}
+
+	var selected []string
+	for i := 0; i < 4; i++ {
+		replica, ok := selector.Select(replicas, nil)
+		assert.True(t, ok)
+		selected = append(selected, replica)
+	}
+	assert.Equal(t, selected, []string{"a:1", "b:1", "c:1", "a:1"})
+
+	_, ok := selector.Select(nil, nil)
+	assert.False(t, ok)
+}
+
+func TestLeastLatencySelector(t *testing.T) {
+	fast := redigomock.NewConn()
+	fast.Command("PING").Expect("PONG")
+
+	slow := redigomock.NewConn()
+	slow.Command("PING").Expect("PONG")
+
+	failing := redigomock.NewConn()
+	failing.Command("PING").ExpectError(errors.New("timeout"))
+
+	probe := mockProbe(map[string]redis.Conn{
+		"slow:1":    slowConnection{Conn: slow, delay: 20 * time.Millisecond},
+		"fast:1":    fast,
+		"failing:1": failing,
+	})
+
+	replica, ok := LeastLatencySelector{}.Select([]string{"slow:1", "down:1", "failing:1", "fast:1"}, probe)
+	assert.Equal(t, replica, "fast:1")
+	assert.True(t, ok)
+
+	_, ok = LeastLatencySelector{}.Select([]string{"down:1", "failing:1"}, probe)
+	assert.False(t, ok)
+}
+
+func TestLeastConnectionsSelector(t *testing.T) {
+	monitor := newSentinelMonitor(&SentinelOptions{})
+	probe := &ReplicaProbe{monitor: monitor}
+
+	first := monitor.track(redigomock.NewConn(), "a:1", false)
+	monitor.track(redigomock.NewConn(), "a:1", false)
+	monitor.track(redigomock.NewConn(), "b:1", false)
+	assert.Equal(t, probe.Connections("a:1"), 2)
+
+	replica, ok := LeastConnectionsSelector{}.Select([]string{"a:1", "b:1"}, probe)
+	assert.Equal(t, replica, "b:1")
+	assert.True(t, ok)
+
+	first.Close()
+	first.Close()
+	monitor.track(redigomock.NewConn(), "b:1", false)
+	assert.Equal(t, probe.Connections("a:1"), 1)
+
+	replica, ok = LeastConnectionsSelector{}.Select([]string{"a:1", "b:1"}, probe)
+	assert.Equal(t, replica, "a:1")
+	assert.True(t, ok)
+
+	replica, ok = LeastConnectionsSelector{}.Select([]string{"c:1", "a:1"}, probe)
+	assert.Equal(t, replica, "c:1")
+	assert.True(t, ok)
+
+	_, ok = LeastConnectionsSelector{}.Select(nil, probe)
+	assert.False(t, ok)
+}
+
+func TestSameZoneSelector(t *testing.T) {
+	selector := SameZoneSelector{
+		Zone:  "us-east-1a",
+		Zones: map[string]string{"a:1": "us-east-1a", "b:1": "us-east-1b", "c:1": "us-east-1a"},
+		Next:  &RoundRobinSelector{},
+	}
+
+	replica, _ := selector.Select([]string{"a:1", "b:1", "c:1"}, nil)
+	assert.Equal(t, replica, "a:1")
+
+	replica, _ = selector.Select([]string{"a:1", "b:1", "c:1"}, nil)
+	assert.Equal(t, replica, "c:1")
+
+	replica, ok := selector.Select([]string{"b:1", "d:1"}, nil)
+	assert.Equal(t, replica, "b:1")
+	assert.True(t, ok)
+
+	replica, ok = SameZoneSelector{Zone: "us-east-1b", Zones: selector.Zones}.Select([]string{"a:1", "b:1"}, nil)
+	assert.Equal(t, replica, "b:1")
+	assert.True(t, ok)
+}
+
+func TestHealthySelector(t *testing.T) {
+	probe := mockProbe(map[string]redis.Conn{
+		"master:1": masterReplicationInfo(1000,
+			"ip=up,port=1,state=online,offset=990,lag=1",
+			"ip=lagging,port=1,state=online,offset=990,lag=30",
+			"ip=behind,port=1,state=online,offset=10,lag=0",
+			"ip=down,port=1,state=online,offset=0,lag=0"),
+		"up:1":      replicationInfo("up", 990),
+		"lagging:1": replicationInfo("up", 990),
+		"behind:1":  replicationInfo("up", 10),
+		"down:1":    replicationInfo("down", 0),
+		"unknown:1": replicationInfo("up", 1000),
+	})
+
+	replica, ok := HealthySelector{MaxLag: 10 * time.Second}.Select([]string{"down:1", "lagging:1", "unreachable:1", "unknown:1", "up:1"}, probe)
+	assert.Equal(t, replica, "up:1")
+	assert.True(t, ok)
+
+	replica, ok = HealthySelector{MaxOffsetLag: 100}.Select([]string{"down:1", "behind:1", "lagging:1"}, probe)
+	assert.Equal(t, replica, "lagging:1")
+	assert.True(t, ok)
+
+	replica, ok = HealthySelector{Next: &RoundRobinSelector{}}.Select([]string{"down:1", "behind:1", "up:1"}, probe)
+	assert.Equal(t, replica, "behind:1")
+	assert.True(t, ok)
+
+	_, ok = HealthySelector{MaxLag: 10 * time.Second, MaxOffsetLag: 100}.Select([]string{"down:1", "lagging:1", "behind:1"}, probe)
+	assert.False(t, ok)
+
+	probe.master = func() (string, error) {
+		return "", errors.New("no master")
+	}
+
+	_, ok = HealthySelector{MaxLag: 10 * time.Second}.Select([]string{"up:1"}, probe)
+	assert.False(t, ok)
+
+	replica, ok = HealthySelector{}.Select([]string{"up:1"}, probe)
+	assert.Equal(t, replica, "up:1")
+	assert.True(t, ok)
+}
+
+func TestReplicaProbe_Cached(t *testing.T) {
+	connection := redigomock.NewConn()
+	ping := connection.Command("PING").Expect("PONG")
+	info := connection.Command("INFO", "replication").Expect("# Replication\r\nrole:slave\r\n")
+
+	dials := 0
+	probe := mockProbe(map[string]redis.Conn{"a:1": connection})
+	dial := probe.dial
+	probe.dial = func(address string) (redis.Conn, error) {
+		dials++
+		return dial(address)
+	}
+	probe.ttl = time.Minute
+
+	for i := 0; i < 2; i++ {
+		_, err := probe.Ping("a:1")
+		assert.Nil(t, err)
+
+		_, err = probe.Info("a:1")
+		assert.Nil(t, err)
+
+		_, err = probe.Ping("b:1")
+		assert.NotNil(t, err)
+	}
+	assert.Equal(t, dials, 3)
+	assert.Equal(t, connection.Stats(ping), 1)
+	assert.Equal(t, connection.Stats(info), 1)
+
+	probe.ttl = 0
+
+	_, err := probe.Ping("a:1")
+	assert.Nil(t, err)
+	assert.Equal(t, dials, 4)
+	assert.Equal(t, connection.Stats(ping), 2)
+}
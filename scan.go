@@ -0,0 +1,222 @@
+package xredis
+
+import (
+	"errors"
+	"github.com/garyburd/redigo/redis"
+	"iter"
+)
+
+const typeOption = "TYPE"
+
+// ScanOptions contains the options of the scan iterators. Type only applies to keys. The iterators remember every key or
+// field they yield to skip the ones SCAN returns more than once, so their memory grows with the keyspace or the hash.
+// AllowDuplicates turns that off and yields whatever SCAN returns
+type ScanOptions struct {
+	Match           string
+	Count           int64
+	Type            string
+	AllowDuplicates bool
+}
+
+func (o ScanOptions) args(cursor int64, withType bool) []interface{} {
+	args := []interface{}{cursor}
+	if len(o.Match) > 0 {
+		args = append(args, matchOption, o.Match)
+	}
+	if o.Count > 0 {
+		args = append(args, countOption, o.Count)
+	}
+	if withType && len(o.Type) > 0 {
+		args = append(args, typeOption, o.Type)
+	}
+	return args
+}
+
+func (o ScanOptions) seen() map[string]bool {
+	if o.AllowDuplicates {
+		return nil
+	}
+	return map[string]bool{}
+}
+
+// ScanIterator iterates over the keys using SCAN, looping the cursor and skipping keys returned more than once unless
+// duplicates are allowed. On a cluster, the keys of every master are iterated
+type ScanIterator struct {
+	client    *Client
+	options   ScanOptions
+	addresses []string
+	node      int
+	cursor    int64
+	buffer    []string
+	seen      map[string]bool
+	key       string
+	err       error
+}
+
+// ScanIterator returns an iterator over the keys
+func (c *Client) ScanIterator(options ScanOptions) *ScanIterator {
+	return &ScanIterator{client: c, options: options, addresses: c.nodeAddresses(), seen: options.seen()}
+}
+
+// Next advances to the next key and returns false when there are no more keys or an error occurred
+func (it *ScanIterator) Next() bool {
+	for it.err == nil {
+		for len(it.buffer) > 0 {
+			key := it.buffer[0]
+			it.buffer = it.buffer[1:]
+			if it.seen != nil {
+				if it.seen[key] {
+					continue
+				}
+				it.seen[key] = true
+			}
+
+			it.key = key
+			return true
+		}
+
+		if it.node >= len(it.addresses) {
+			return false
+		}
+		it.err = it.fetch()
+	}
+	return false
+}
+
+// Key returns the current key
+func (it *ScanIterator) Key() string {
+	return it.key
+}
+
+// Err returns the error that stopped the iteration
+func (it *ScanIterator) Err() error {
+	return it.err
+}
+
+// All returns the remaining keys as a sequence, Err should be checked once it is done
+func (it *ScanIterator) All() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for it.Next() {
+			if !yield(it.key) {
+				return
+			}
+		}
+	}
+}
+
+func (it *ScanIterator) fetch() error {
+	connection := it.client.nodeConnection(it.addresses[it.node])
+	defer connection.Close()
+
+	results, err := redis.Values(connection.Do(scanCommand, it.options.args(it.cursor, true)...))
+	if err != nil {
+		return err
+	}
+
+	cursor, keys, err := parseScanResults(results)
+	if err != nil {
+		return err
+	}
+
+	it.buffer = keys
+	it.cursor = cursor
+	if cursor == 0 {
+		it.node++
+	}
+	return nil
+}
+
+// HScanIterator iterates over a hash's field/value pairs using HSCAN, looping the cursor and skipping fields returned more
+// than once unless duplicates are allowed
+type HScanIterator struct {
+	client   *Client
+	key      string
+	options  ScanOptions
+	cursor   int64
+	finished bool
+	buffer   []string
+	seen     map[string]bool
+	field    string
+	value    string
+	err      error
+}
+
+// HScanIterator returns an iterator over a hash's field/value pairs
+func (c *Client) HScanIterator(key string, options ScanOptions) *HScanIterator {
+	return &HScanIterator{client: c, key: key, options: options, seen: options.seen()}
+}
+
+// Next advances to the next field/value pair and returns false when there are no more pairs or an error occurred
+func (it *HScanIterator) Next() bool {
+	for it.err == nil {
+		for len(it.buffer) >= 2 {
+			field, value := it.buffer[0], it.buffer[1]
+			it.buffer = it.buffer[2:]
+			if it.seen != nil {
+				if it.seen[field] {
+					continue
+				}
+				it.seen[field] = true
+			}
+
+			it.field, it.value = field, value
+			return true
+		}
+
+		if it.finished {
+			return false
+		}
+		it.err = it.fetch()
+	}
+	return false
+}
+
+// Field returns the current field
+func (it *HScanIterator) Field() string {
+	return it.field
+}
+
+// Value returns the current field's value
+func (it *HScanIterator) Value() string {
+	return it.value
+}
+
+// Err returns the error that stopped the iteration
+func (it *HScanIterator) Err() error {
+	return it.err
+}
+
+// All returns the remaining field/value pairs as a sequence, Err should be checked once it is done
+func (it *HScanIterator) All() iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		for it.Next() {
+			if !yield(it.field, it.value) {
+				return
+			}
+		}
+	}
+}
+
+func (it *HScanIterator) fetch() error {
+	connection := it.client.getWriteConnection()
+	defer connection.Close()
+
+	args := append([]interface{}{it.key}, it.options.args(it.cursor, false)...)
+	results, err := redis.Values(connection.Do(hScanCommand, args...))
+	if err != nil {
+		return err
+	}
+
+	cursor, values, err := parseScanResults(results)
+	if err != nil {
+		return err
+	}
+	if len(values)%2 != 0 {
+		return errors.New(oddRepliesError)
+	}
+
+	it.buffer = values
+	it.cursor = cursor
+	it.finished = cursor == 0
+	return nil
+}
@@ -0,0 +1,170 @@
+package xredis
+
+import (
+	"errors"
+	"github.com/rafaeljusto/redigomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func scanReply(cursor string, values ...string) []interface{} {
+	items := make([]interface{}, len(values))
+	for i, value := range values {
+		items[i] = []byte(value)
+	}
+	return []interface{}{[]byte(cursor), items}
+}
+
+func TestScanOptions_Args(t *testing.T) {
+	assert.Equal(t, ScanOptions{}.args(0, true), []interface{}{int64(0)})
+	assert.Equal(t, ScanOptions{Match: "a*", Count: 10, Type: "hash"}.args(5, true), []interface{}{int64(5), "MATCH", "a*", "COUNT", int64(10), "TYPE", "hash"})
+	assert.Equal(t, ScanOptions{Type: "hash"}.args(5, false), []interface{}{int64(5)})
+}
+
+func TestClient_ScanIterator(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("SCAN", int64(0), "MATCH", "a*", "COUNT", int64(10), "TYPE", "string").Expect(scanReply("5", "a1", "a2"))
+	connection.Command("SCAN", int64(5), "MATCH", "a*", "COUNT", int64(10), "TYPE", "string").Expect(scanReply("0", "a2", "a3"))
+
+	client := mockClient(connection)
+
+	iterator := client.ScanIterator(ScanOptions{Match: "a*", Count: 10, Type: "string"})
+
+	var keys []string
+	for iterator.Next() {
+		keys = append(keys, iterator.Key())
+	}
+	assert.Equal(t, keys, []string{"a1", "a2", "a3"})
+	assert.Nil(t, iterator.Err())
+	assert.False(t, iterator.Next())
+}
+
+func TestClient_ScanIterator_AllowDuplicates(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("SCAN", int64(0)).Expect(scanReply("5", "a1", "a2"))
+	connection.Command("SCAN", int64(5)).Expect(scanReply("0", "a2", "a3"))
+
+	client := mockClient(connection)
+
+	iterator := client.ScanIterator(ScanOptions{AllowDuplicates: true})
+
+	var keys []string
+	for iterator.Next() {
+		keys = append(keys, iterator.Key())
+	}
+	assert.Equal(t, keys, []string{"a1", "a2", "a2", "a3"})
+	assert.Nil(t, iterator.Err())
+	assert.Nil(t, iterator.seen)
+}
+
+func TestClient_ScanIterator_All(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("SCAN", int64(0)).Expect(scanReply("0", "a", "b", "c"))
+
+	client := mockClient(connection)
+
+	iterator := client.ScanIterator(ScanOptions{})
+
+	var keys []string
+	for key := range iterator.All() {
+		keys = append(keys, key)
+		if key == "b" {
+			break
+		}
+	}
+	assert.Equal(t, keys, []string{"a", "b"})
+
+	for key := range iterator.All() {
+		keys = append(keys, key)
+	}
+	assert.Equal(t, keys, []string{"a", "b", "c"})
+	assert.Nil(t, iterator.Err())
+}
+
+func TestClient_ScanIterator_Error(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("SCAN", int64(0)).ExpectError(errors.New("Oops"))
+
+	client := mockClient(connection)
+
+	iterator := client.ScanIterator(ScanOptions{})
+	assert.False(t, iterator.Next())
+	assert.Equal(t, iterator.Err(), errors.New("Oops"))
+}
+
+func TestClient_ScanIterator_Cluster(t *testing.T) {
+	a := redigomock.NewConn()
+	a.Command("SCAN", int64(0)).Expect(scanReply("0", "bar"))
+	b := redigomock.NewConn()
+	b.Command("SCAN", int64(0)).Expect(scanReply("3", "foo"))
+	b.Command("SCAN", int64(3)).Expect(scanReply("0"))
+
+	client := mockClusterClient(&ClusterOptions{}, map[string]*redigomock.Conn{"a:1": a, "b:2": b})
+
+	var keys []string
+	for key := range client.ScanIterator(ScanOptions{}).All() {
+		keys = append(keys, key)
+	}
+	assert.Equal(t, keys, []string{"bar", "foo"})
+}
+
+func TestClient_HScanIterator(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("HSCAN", "hash", int64(0), "MATCH", "f*", "COUNT", int64(2)).Expect(scanReply("7", "f1", "1", "f2", "2"))
+	connection.Command("HSCAN", "hash", int64(7), "MATCH", "f*", "COUNT", int64(2)).Expect(scanReply("0", "f2", "2", "f3", "3"))
+
+	client := mockClient(connection)
+
+	iterator := client.HScanIterator("hash", ScanOptions{Match: "f*", Count: 2, Type: "ignored"})
+
+	values := map[string]string{}
+	var fields []string
+	for iterator.Next() {
+		fields = append(fields, iterator.Field())
+		values[iterator.Field()] = iterator.Value()
+	}
+	assert.Equal(t, fields, []string{"f1", "f2", "f3"})
+	assert.Equal(t, values, map[string]string{"f1": "1", "f2": "2", "f3": "3"})
+	assert.Nil(t, iterator.Err())
+}
+
+func TestClient_HScanIterator_AllowDuplicates(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("HSCAN", "hash", int64(0)).Expect(scanReply("7", "f1", "1", "f2", "2"))
+	connection.Command("HSCAN", "hash", int64(7)).Expect(scanReply("0", "f2", "2"))
+
+	client := mockClient(connection)
+
+	iterator := client.HScanIterator("hash", ScanOptions{AllowDuplicates: true})
+
+	var fields []string
+	for iterator.Next() {
+		fields = append(fields, iterator.Field())
+	}
+	assert.Equal(t, fields, []string{"f1", "f2", "f2"})
+	assert.Nil(t, iterator.Err())
+}
+
+func TestClient_HScanIterator_All(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("HSCAN", "hash", int64(0)).Expect(scanReply("0", "a", "1", "b", "2"))
+
+	client := mockClient(connection)
+
+	values := map[string]string{}
+	for field, value := range client.HScanIterator("hash", ScanOptions{}).All() {
+		values[field] = value
+	}
+	assert.Equal(t, values, map[string]string{"a": "1", "b": "2"})
+}
+
+func TestClient_HScanIterator_Error(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("HSCAN", "hash", int64(0)).Expect(scanReply("0", "a"))
+
+	client := mockClient(connection)
+
+	iterator := client.HScanIterator("hash", ScanOptions{})
+	assert.False(t, iterator.Next())
+	assert.Equal(t, iterator.Err(), errors.New(oddRepliesError))
+}
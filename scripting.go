@@ -0,0 +1,168 @@
+package xredis
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"github.com/garyburd/redigo/redis"
+	"strings"
+	"sync"
+)
+
+const (
+	evalCommand    = "EVAL"
+	evalShaCommand = "EVALSHA"
+	scriptCommand  = "SCRIPT"
+
+	loadSubcommand   = "LOAD"
+	existsSubcommand = "EXISTS"
+	flushSubcommand  = "FLUSH"
+
+	noScriptError = "NOSCRIPT"
+)
+
+// Eval runs a Lua script with the keys and arguments provided
+func (c *Client) Eval(script string, keys []string, args ...interface{}) (interface{}, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return connection.Do(evalCommand, scriptArgs(script, keys, args)...)
+}
+
+// EvalSha runs a cached Lua script by its SHA1 digest with the keys and arguments provided
+func (c *Client) EvalSha(sha string, keys []string, args ...interface{}) (interface{}, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return connection.Do(evalShaCommand, scriptArgs(sha, keys, args)...)
+}
+
+// ScriptLoad caches a Lua script and returns its SHA1 digest
+func (c *Client) ScriptLoad(script string) (string, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return redis.String(connection.Do(scriptCommand, loadSubcommand, script))
+}
+
+// ScriptExists determines whether the scripts are cached by their SHA1 digests
+func (c *Client) ScriptExists(shas ...string) ([]bool, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	values, err := redis.Ints(connection.Do(scriptCommand, prepend(existsSubcommand, shas)...))
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]bool, len(values))
+	for i, value := range values {
+		results[i] = value > 0
+	}
+	return results, nil
+}
+
+// ScriptFlush removes all cached scripts
+func (c *Client) ScriptFlush() error {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return toError(connection.Do(scriptCommand, flushSubcommand))
+}
+
+// Script is a Lua script that is run by its SHA1 digest and sent in full only if the server does not have it cached
+type Script struct {
+	source string
+	sha    string
+}
+
+// NewScript returns a script
+func NewScript(source string) *Script {
+	digest := sha1.Sum([]byte(source))
+	return &Script{source: source, sha: hex.EncodeToString(digest[:])}
+}
+
+// Source returns the script's source
+func (s *Script) Source() string {
+	return s.source
+}
+
+// Sha returns the script's SHA1 digest
+func (s *Script) Sha() string {
+	return s.sha
+}
+
+// Load caches the script on the server
+func (s *Script) Load(client *Client) error {
+	_, err := client.ScriptLoad(s.source)
+	return err
+}
+
+// Run runs the script with EVALSHA and falls back on EVAL if the script is not cached
+func (s *Script) Run(client *Client, keys []string, args ...interface{}) (interface{}, error) {
+	connection := client.getWriteConnection()
+	defer connection.Close()
+
+	reply, err := connection.Do(evalShaCommand, scriptArgs(s.sha, keys, args)...)
+	if !isNoScriptError(err) {
+		return reply, err
+	}
+	return connection.Do(evalCommand, scriptArgs(s.source, keys, args)...)
+}
+
+// ScriptRegistry holds scripts that are loaded on every new connection of the pools it is configured on
+type ScriptRegistry struct {
+	mutex   sync.RWMutex
+	scripts []*Script
+}
+
+// NewScriptRegistry returns a script registry
+func NewScriptRegistry() *ScriptRegistry {
+	return &ScriptRegistry{}
+}
+
+// Register adds a script to the registry and returns it
+func (r *ScriptRegistry) Register(source string) *Script {
+	script := NewScript(source)
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.scripts = append(r.scripts, script)
+	return script
+}
+
+// Scripts returns the registered scripts
+func (r *ScriptRegistry) Scripts() []*Script {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	scripts := make([]*Script, len(r.scripts))
+	copy(scripts, r.scripts)
+	return scripts
+}
+
+func (r *ScriptRegistry) load(connection redis.Conn) error {
+	if r == nil {
+		return nil
+	}
+
+	for _, script := range r.Scripts() {
+		_, err := connection.Do(scriptCommand, loadSubcommand, script.source)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func scriptArgs(script string, keys []string, args []interface{}) []interface{} {
+	results := make([]interface{}, 0, len(keys)+len(args)+2)
+	results = append(results, script, len(keys))
+	results = append(results, toInterfaces(keys)...)
+	return append(results, args...)
+}
+
+func isNoScriptError(err error) bool {
+	e, ok := err.(redis.Error)
+	return ok && strings.HasPrefix(string(e), noScriptError)
+}
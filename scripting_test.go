@@ -0,0 +1,130 @@
+package xredis
+
+import (
+	"github.com/garyburd/redigo/redis"
+	"github.com/rafaeljusto/redigomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const testScript = "return redis.call('GET', KEYS[1])"
+
+func TestClient_Eval(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("EVAL", testScript, 1, "key", "arg").Expect("value")
+
+	client := mockClient(connection)
+
+	value, err := redis.String(client.Eval(testScript, []string{"key"}, "arg"))
+	assert.Equal(t, value, "value")
+	assert.Nil(t, err)
+}
+
+func TestClient_EvalSha(t *testing.T) {
+	script := NewScript(testScript)
+
+	connection := redigomock.NewConn()
+	connection.Command("EVALSHA", script.Sha(), 1, "key").Expect("value")
+
+	client := mockClient(connection)
+
+	value, err := redis.String(client.EvalSha(script.Sha(), []string{"key"}))
+	assert.Equal(t, value, "value")
+	assert.Nil(t, err)
+}
+
+func TestClient_ScriptLoad(t *testing.T) {
+	script := NewScript(testScript)
+
+	connection := redigomock.NewConn()
+	connection.Command("SCRIPT", "LOAD", testScript).Expect(script.Sha())
+
+	client := mockClient(connection)
+
+	sha, err := client.ScriptLoad(testScript)
+	assert.Equal(t, sha, script.Sha())
+	assert.Nil(t, err)
+}
+
+func TestClient_ScriptExists(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("SCRIPT", "EXISTS", "a", "b").Expect([]interface{}{int64(1), int64(0)})
+
+	client := mockClient(connection)
+
+	exists, err := client.ScriptExists("a", "b")
+	assert.Equal(t, exists, []bool{true, false})
+	assert.Nil(t, err)
+}
+
+func TestClient_ScriptFlush(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("SCRIPT", "FLUSH").Expect("OK")
+
+	client := mockClient(connection)
+
+	assert.Nil(t, client.ScriptFlush())
+}
+
+func TestNewScript(t *testing.T) {
+	script := NewScript("return 1")
+	assert.Equal(t, script.Source(), "return 1")
+	assert.Equal(t, script.Sha(), "e0e1f9fabfc9d4800c877a703b823ac0578ff8db")
+}
+
+func TestScript_Load(t *testing.T) {
+	script := NewScript(testScript)
+
+	connection := redigomock.NewConn()
+	connection.Command("SCRIPT", "LOAD", testScript).Expect(script.Sha())
+
+	client := mockClient(connection)
+
+	assert.Nil(t, script.Load(client))
+}
+
+func TestScript_Run(t *testing.T) {
+	script := NewScript(testScript)
+
+	connection := redigomock.NewConn()
+	connection.Command("EVALSHA", script.Sha(), 1, "key").Expect("value")
+
+	client := mockClient(connection)
+
+	value, err := redis.String(script.Run(client, []string{"key"}))
+	assert.Equal(t, value, "value")
+	assert.Nil(t, err)
+
+	connection = redigomock.NewConn()
+	connection.Command("EVALSHA", script.Sha(), 1, "key").ExpectError(redis.Error("NOSCRIPT No matching script"))
+	connection.Command("EVAL", testScript, 1, "key").Expect("value")
+
+	client = mockClient(connection)
+
+	value, err = redis.String(script.Run(client, []string{"key"}))
+	assert.Equal(t, value, "value")
+	assert.Nil(t, err)
+
+	connection = redigomock.NewConn()
+	connection.Command("EVALSHA", script.Sha(), 1, "key").ExpectError(redis.Error("ERR oops"))
+
+	client = mockClient(connection)
+
+	_, err = script.Run(client, []string{"key"})
+	assert.Equal(t, err, redis.Error("ERR oops"))
+}
+
+func TestScriptRegistry(t *testing.T) {
+	registry := NewScriptRegistry()
+	script := registry.Register(testScript)
+	assert.Equal(t, registry.Scripts(), []*Script{script})
+
+	connection := redigomock.NewConn()
+	command := connection.Command("SCRIPT", "LOAD", testScript).Expect(script.Sha())
+
+	assert.Nil(t, registry.load(connection))
+	assert.Equal(t, connection.Stats(command), 1)
+
+	var empty *ScriptRegistry
+	assert.Nil(t, empty.load(connection))
+}
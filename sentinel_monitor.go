@@ -0,0 +1,308 @@
+package xredis
+
+import (
+	"errors"
+	"github.com/FZambia/go-sentinel"
+	"github.com/garyburd/redigo/redis"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	switchMasterEvent   = "+switch-master"
+	subjectiveDownEvent = "+sdown"
+	objectiveDownEvent  = "+odown"
+
+	masterInstance  = "master"
+	replicaInstance = "slave"
+	instanceMarker  = "@"
+
+	staleConnectionError = "connection to a server whose role changed"
+	noSentinelsError     = "no sentinels available"
+)
+
+const (
+	connectionActive int32 = iota
+	connectionIdle
+	connectionClosed
+)
+
+// sentinelMonitor listens to the sentinels' failover events and retires the pooled connections to the servers involved
+// so that they are not borrowed again. Each connection remembers the generation of its server when it was dialed
+// and becomes stale once the generation moves on
+type sentinelMonitor struct {
+	masterName      string
+	mutex           sync.RWMutex
+	writeGeneration uint64
+	generations     map[string]uint64
+	counts          map[string]int
+	tracked         map[*monitoredConnection]bool
+	subscriber      *Subscriber
+	wait            sync.WaitGroup
+}
+
+func newSentinelMonitor(options *SentinelOptions) *sentinelMonitor {
+	return &sentinelMonitor{
+		masterName:  options.GetMasterName(),
+		generations: map[string]uint64{},
+		counts:      map[string]int{},
+		tracked:     map[*monitoredConnection]bool{},
+	}
+}
+
+// start subscribes to the failover events of the sentinels reached through the dial function
+func (m *sentinelMonitor) start(dial func() (redis.Conn, error)) {
+	client := NewClient(&redis.Pool{Dial: dial})
+	m.subscriber = client.NewSubscriber(&SubscriberOptions{})
+	m.subscriber.Subscribe(switchMasterEvent, subjectiveDownEvent, objectiveDownEvent)
+
+	m.wait.Add(1)
+	go func() {
+		defer m.wait.Done()
+
+		for message := range m.subscriber.Messages() {
+			m.handle(message)
+		}
+	}()
+}
+
+func (m *sentinelMonitor) close() error {
+	if m.subscriber == nil {
+		return nil
+	}
+
+	err := m.subscriber.Close()
+	m.wait.Wait()
+	return err
+}
+
+// handle retires the connections affected by an event such as
+// "+switch-master <master name> <old ip> <old port> <new ip> <new port>" or
+// "+sdown <instance type> <name> <ip> <port> @ <master name> <master ip> <master port>".
+// A single sentinel's +sdown of the master does not mean a failover, so only +odown retires the master's connections
+func (m *sentinelMonitor) handle(message Message) {
+	fields := strings.Fields(message.Data)
+
+	switch message.Channel {
+	case switchMasterEvent:
+		if len(fields) < 5 || fields[0] != m.masterName {
+			return
+		}
+
+		m.retire(true, net.JoinHostPort(fields[1], fields[2]), net.JoinHostPort(fields[3], fields[4]))
+	case subjectiveDownEvent, objectiveDownEvent:
+		if len(fields) < 4 {
+			return
+		}
+
+		address := net.JoinHostPort(fields[2], fields[3])
+		switch fields[0] {
+		case masterInstance:
+			if message.Channel == objectiveDownEvent && fields[1] == m.masterName {
+				m.retire(true, address)
+			}
+		case replicaInstance:
+			if len(fields) >= 6 && fields[4] == instanceMarker && fields[5] == m.masterName {
+				m.retire(false, address)
+			}
+		}
+	}
+}
+
+// retire makes the connections to the addresses stale, and all the master's connections if the master is involved,
+// then drains the stale connections
+func (m *sentinelMonitor) retire(master bool, addresses ...string) {
+	m.mutex.Lock()
+	if master {
+		m.writeGeneration++
+	}
+	for _, address := range addresses {
+		m.generations[address]++
+	}
+	m.mutex.Unlock()
+
+	m.drain()
+}
+
+// drain closes the stale connections that are idle in their pools. It never borrows from the pools, which would dial
+// once no idle connection is left. The pools discard the closed connections when they are next borrowed,
+// and the stale connections in use are discarded when they are returned
+func (m *sentinelMonitor) drain() {
+	m.mutex.RLock()
+	var connections []*monitoredConnection
+	for connection := range m.tracked {
+		if m.isStale(connection) {
+			connections = append(connections, connection)
+		}
+	}
+	m.mutex.RUnlock()
+
+	for _, connection := range connections {
+		connection.retire()
+	}
+}
+
+// track wraps a new connection to the address so that it reports an error once it is stale and is counted while open
+func (m *sentinelMonitor) track(connection redis.Conn, address string, write bool) redis.Conn {
+	if m == nil {
+		return connection
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.counts[address]++
+	monitored := &monitoredConnection{
+		Conn:            connection,
+		monitor:         m,
+		address:         address,
+		write:           write,
+		writeGeneration: m.writeGeneration,
+		generation:      m.generations[address],
+	}
+	m.tracked[monitored] = true
+	return monitored
+}
+
+// connections returns the number of open connections to the address
+func (m *sentinelMonitor) connections(address string) int {
+	if m == nil {
+		return 0
+	}
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.counts[address]
+}
+
+func (m *sentinelMonitor) untrack(connection *monitoredConnection) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	delete(m.tracked, connection)
+	m.counts[connection.address]--
+	if m.counts[connection.address] <= 0 {
+		delete(m.counts, connection.address)
+	}
+}
+
+func (m *sentinelMonitor) stale(connection *monitoredConnection) bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.isStale(connection)
+}
+
+func (m *sentinelMonitor) isStale(connection *monitoredConnection) bool {
+	if connection.write && connection.writeGeneration != m.writeGeneration {
+		return true
+	}
+	return connection.generation != m.generations[connection.address]
+}
+
+// borrowMonitored marks a monitored connection, which the pools hide behind an interruptible connection, in use
+// when it is borrowed
+func borrowMonitored(connection redis.Conn) error {
+	if interruptible, ok := connection.(*interruptibleConnection); ok {
+		connection = interruptible.Conn
+	}
+
+	monitored, ok := connection.(*monitoredConnection)
+	if !ok {
+		return nil
+	}
+	return monitored.borrow()
+}
+
+// monitoredConnection is a pooled connection whose error, checked by the pool, reports when it is stale.
+// It is idle from the moment its pool takes it back, which ends with an empty command, until it is borrowed again
+type monitoredConnection struct {
+	redis.Conn
+	monitor         *sentinelMonitor
+	address         string
+	write           bool
+	writeGeneration uint64
+	generation      uint64
+	state           int32
+}
+
+// borrow marks the connection in use, unless it was closed while idle
+func (c *monitoredConnection) borrow() error {
+	if atomic.CompareAndSwapInt32(&c.state, connectionIdle, connectionActive) {
+		return nil
+	}
+	if atomic.LoadInt32(&c.state) == connectionClosed {
+		return errors.New(staleConnectionError)
+	}
+	return nil
+}
+
+// retire closes the connection if it is idle
+func (c *monitoredConnection) retire() {
+	if atomic.CompareAndSwapInt32(&c.state, connectionIdle, connectionClosed) {
+		c.monitor.untrack(c)
+		c.Conn.Close()
+	}
+}
+
+func (c *monitoredConnection) Close() error {
+	if atomic.SwapInt32(&c.state, connectionClosed) == connectionClosed {
+		return nil
+	}
+
+	c.monitor.untrack(c)
+	return c.Conn.Close()
+}
+
+func (c *monitoredConnection) Do(commandName string, args ...interface{}) (interface{}, error) {
+	if len(commandName) == 0 {
+		reply, err := c.Conn.Do(commandName, args...)
+		atomic.CompareAndSwapInt32(&c.state, connectionActive, connectionIdle)
+		return reply, err
+	}
+
+	atomic.CompareAndSwapInt32(&c.state, connectionIdle, connectionActive)
+	return c.Conn.Do(commandName, args...)
+}
+
+func (c *monitoredConnection) Send(commandName string, args ...interface{}) error {
+	atomic.CompareAndSwapInt32(&c.state, connectionIdle, connectionActive)
+	return c.Conn.Send(commandName, args...)
+}
+
+func (c *monitoredConnection) Err() error {
+	if c.monitor.stale(c) {
+		return errors.New(staleConnectionError)
+	}
+	return c.Conn.Err()
+}
+
+func (c *monitoredConnection) DoWithTimeout(timeout time.Duration, commandName string, args ...interface{}) (interface{}, error) {
+	atomic.CompareAndSwapInt32(&c.state, connectionIdle, connectionActive)
+	return redis.DoWithTimeout(c.Conn, timeout, commandName, args...)
+}
+
+func (c *monitoredConnection) ReceiveWithTimeout(timeout time.Duration) (interface{}, error) {
+	return redis.ReceiveWithTimeout(c.Conn, timeout)
+}
+
+// sentinelsDial connects to the first sentinel that answers
+func sentinelsDial(options *SentinelOptions, sentinelDetails *sentinel.Sentinel) func() (redis.Conn, error) {
+	addresses := options.GetAddresses()
+
+	return func() (redis.Conn, error) {
+		err := errors.New(noSentinelsError)
+		for _, address := range addresses {
+			var connection redis.Conn
+			connection, err = sentinelDetails.Dial(address)
+			if err == nil {
+				return connection, nil
+			}
+		}
+		return nil, err
+	}
+}
@@ -0,0 +1,203 @@
+package xredis
+
+import (
+	"errors"
+	"github.com/garyburd/redigo/redis"
+	"github.com/rafaeljusto/redigomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestSentinelMonitor_Handle(t *testing.T) {
+	monitor := newSentinelMonitor(&SentinelOptions{MasterName: "master"})
+
+	write := monitor.track(redigomock.NewConn(), "10.0.0.1:6379", true)
+	read := monitor.track(redigomock.NewConn(), "10.0.0.2:6379", false)
+	other := monitor.track(redigomock.NewConn(), "10.0.0.3:6379", false)
+
+	monitor.handle(Message{Channel: "+switch-master", Data: "other 10.0.0.1 6379 10.0.0.2 6379"})
+	monitor.handle(Message{Channel: "+sdown", Data: "slave 10.0.0.3:6379 10.0.0.3 6379 @ other 10.0.0.1 6379"})
+	monitor.handle(Message{Channel: "+sdown", Data: "sentinel 10.0.0.9:26379 10.0.0.9 26379 @ master 10.0.0.1 6379"})
+	monitor.handle(Message{Channel: "+odown", Data: "master"})
+	assert.Nil(t, write.Err())
+	assert.Nil(t, read.Err())
+	assert.Nil(t, other.Err())
+
+	monitor.handle(Message{Channel: "+sdown", Data: "slave 10.0.0.3:6379 10.0.0.3 6379 @ master 10.0.0.1 6379"})
+	assert.Nil(t, write.Err())
+	assert.Nil(t, read.Err())
+	assert.Equal(t, other.Err().Error(), staleConnectionError)
+
+	monitor.handle(Message{Channel: "+switch-master", Data: "master 10.0.0.1 6379 10.0.0.2 6379"})
+	assert.Equal(t, write.Err().Error(), staleConnectionError)
+	assert.Equal(t, read.Err().Error(), staleConnectionError)
+
+	write = monitor.track(redigomock.NewConn(), "10.0.0.2:6379", true)
+	read = monitor.track(redigomock.NewConn(), "10.0.0.1:6379", false)
+	assert.Nil(t, write.Err())
+	assert.Nil(t, read.Err())
+
+	monitor.handle(Message{Channel: "+sdown", Data: "master master 10.0.0.2 6379"})
+	assert.Nil(t, write.Err())
+	assert.Nil(t, read.Err())
+
+	monitor.handle(Message{Channel: "+odown", Data: "master master 10.0.0.2 6379 #quorum 2/2"})
+	assert.Equal(t, write.Err().Error(), staleConnectionError)
+	assert.Nil(t, read.Err())
+}
+
+func TestSentinelMonitor_Drain(t *testing.T) {
+	monitor := newSentinelMonitor(&SentinelOptions{MasterName: "master"})
+
+	dials := 0
+	pool := &redis.Pool{
+		MaxIdle: 10,
+		Dial: interruptibleDial(func() (redis.Conn, error) {
+			dials++
+			return monitor.track(redigomock.NewConn(), "10.0.0.1:6379", true), nil
+		}),
+		TestOnBorrow: sentinelTestOnBorrow(&SentinelOptions{TestOnBorrowPeriod: time.Minute}),
+	}
+
+	first := pool.Get()
+	second := pool.Get()
+	inUse := pool.Get()
+	first.Close()
+	second.Close()
+	assert.Equal(t, dials, 3)
+	assert.Equal(t, pool.IdleCount(), 2)
+	assert.Equal(t, monitor.connections("10.0.0.1:6379"), 3)
+
+	monitor.handle(Message{Channel: "+switch-master", Data: "master 10.0.0.1 6379 10.0.0.2 6379"})
+	assert.Equal(t, dials, 3)
+	assert.Equal(t, monitor.connections("10.0.0.1:6379"), 1)
+
+	assert.Equal(t, inUse.Err().Error(), staleConnectionError)
+	inUse.Close()
+	assert.Equal(t, monitor.connections("10.0.0.1:6379"), 0)
+	assert.Equal(t, pool.IdleCount(), 2)
+
+	connection := pool.Get()
+	assert.Nil(t, connection.Err())
+	assert.Equal(t, dials, 4)
+	assert.Equal(t, pool.IdleCount(), 0)
+	connection.Close()
+}
+
+func TestSentinelMonitor_DrainEveryIdleConnection(t *testing.T) {
+	monitor := newSentinelMonitor(&SentinelOptions{MasterName: "master"})
+
+	addresses := []string{"10.0.0.3:6379", "10.0.0.3:6379", "10.0.0.2:6379"}
+	dials := 0
+	pool := &redis.Pool{
+		MaxIdle: 10,
+		Dial: interruptibleDial(func() (redis.Conn, error) {
+			address := "10.0.0.2:6379"
+			if dials < len(addresses) {
+				address = addresses[dials]
+			}
+			dials++
+			return monitor.track(redigomock.NewConn(), address, false), nil
+		}),
+		TestOnBorrow: sentinelTestOnBorrow(&SentinelOptions{TestOnBorrowPeriod: time.Minute}),
+	}
+
+	first := pool.Get()
+	second := pool.Get()
+	third := pool.Get()
+	first.Close()
+	second.Close()
+	third.Close()
+	assert.Equal(t, pool.IdleCount(), 3)
+	assert.Equal(t, monitor.connections("10.0.0.3:6379"), 2)
+
+	monitor.handle(Message{Channel: "+sdown", Data: "slave 10.0.0.3:6379 10.0.0.3 6379 @ master 10.0.0.1 6379"})
+	assert.Equal(t, monitor.connections("10.0.0.3:6379"), 0)
+	assert.Equal(t, monitor.connections("10.0.0.2:6379"), 1)
+	assert.Equal(t, dials, 3)
+
+	connection := pool.Get()
+	assert.Nil(t, connection.Err())
+	connection.Close()
+
+	for i := 0; i < 3; i++ {
+		connection = pool.Get()
+		assert.Nil(t, connection.Err())
+		defer connection.Close()
+	}
+	assert.Equal(t, dials, 5)
+	assert.Equal(t, monitor.connections("10.0.0.2:6379"), 3)
+}
+
+func TestMonitoredConnection_Borrow(t *testing.T) {
+	monitor := newSentinelMonitor(&SentinelOptions{MasterName: "master"})
+
+	connection := monitor.track(redigomock.NewConn(), "10.0.0.1:6379", true).(*monitoredConnection)
+	monitor.retire(true)
+	assert.Equal(t, monitor.connections("10.0.0.1:6379"), 1)
+
+	connection.Do("")
+	assert.Nil(t, borrowMonitored(&interruptibleConnection{Conn: connection}))
+	monitor.retire(true)
+	assert.Equal(t, monitor.connections("10.0.0.1:6379"), 1)
+
+	connection.Do("")
+	monitor.retire(true)
+	assert.Equal(t, monitor.connections("10.0.0.1:6379"), 0)
+	assert.Equal(t, borrowMonitored(&interruptibleConnection{Conn: connection}), errors.New(staleConnectionError))
+	assert.Nil(t, connection.Close())
+	assert.Equal(t, monitor.connections("10.0.0.1:6379"), 0)
+}
+
+func TestSentinelMonitor_Start(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.GenericCommand("SUBSCRIBE").Expect([]interface{}{[]byte("subscribe"), []byte("+odown"), int64(3)})
+	connection.AddSubscriptionMessage([]interface{}{[]byte("message"), []byte("+switch-master"), []byte("master 10.0.0.1 6379 10.0.0.2 6379")})
+
+	ready := make(chan struct{})
+	dial := func() (redis.Conn, error) {
+		<-ready
+		return connection, nil
+	}
+
+	monitor := newSentinelMonitor(&SentinelOptions{MasterName: "master"})
+	write := monitor.track(redigomock.NewConn(), "10.0.0.1:6379", true)
+
+	monitor.start(dial)
+	close(ready)
+
+	deadline := time.Now().Add(time.Second)
+	for write.Err() == nil && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, write.Err().Error(), staleConnectionError)
+	assert.Nil(t, monitor.close())
+}
+
+func TestSentinelsDial(t *testing.T) {
+	options := &SentinelOptions{Addresses: []string{"localhost:1", "localhost:2"}}
+	sentinelDetails := createSentinel(options)
+
+	var addresses []string
+	sentinelDetails.Dial = func(address string) (redis.Conn, error) {
+		addresses = append(addresses, address)
+		if address == "localhost:1" {
+			return nil, redis.Error("down")
+		}
+		return redigomock.NewConn(), nil
+	}
+
+	connection, err := sentinelsDial(options, sentinelDetails)()
+	assert.NotNil(t, connection)
+	assert.Nil(t, err)
+	assert.Equal(t, addresses, []string{"localhost:1", "localhost:2"})
+
+	sentinelDetails.Dial = func(address string) (redis.Conn, error) {
+		return nil, redis.Error("down")
+	}
+
+	connection, err = sentinelsDial(options, sentinelDetails)()
+	assert.Nil(t, connection)
+	assert.Equal(t, err, redis.Error("down"))
+}
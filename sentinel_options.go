@@ -5,7 +5,6 @@ import (
 	"errors"
 	"github.com/FZambia/go-sentinel"
 	"github.com/garyburd/redigo/redis"
-	"math/rand"
 	"time"
 )
 
@@ -31,6 +30,7 @@ const (
 type SentinelOptions struct {
 	Addresses             []string
 	MasterName            string
+	Username              string
 	Password              string
 	Database              int
 	Network               string
@@ -44,6 +44,17 @@ type SentinelOptions struct {
 	TlsConfig             *tls.Config
 	TlsSkipVerify         bool
 	TestOnBorrowPeriod    time.Duration
+	ScriptRegistry        *ScriptRegistry
+	ClientName            string
+	WriteClientNameSuffix string
+	ReadClientNameSuffix  string
+	LibraryInfo           bool
+	SentinelUsername      string
+	SentinelPassword      string
+	SentinelTlsConfig     *tls.Config
+	SentinelTlsSkipVerify bool
+	CredentialsProvider   CredentialsProvider
+	ReplicaSelector       ReplicaSelector
 }
 
 // GetAddresses returns sentinel address
@@ -62,6 +73,11 @@ func (o *SentinelOptions) GetMasterName() string {
 	return o.MasterName
 }
 
+// GetUsername returns the ACL username
+func (o *SentinelOptions) GetUsername() string {
+	return o.Username
+}
+
 // GetPassword returns password
 func (o *SentinelOptions) GetPassword() string {
 	if len(o.Password) == 0 {
@@ -157,7 +173,71 @@ func (o *SentinelOptions) GetTestOnBorrowPeriod() time.Duration {
 	return o.TestOnBorrowPeriod
 }
 
-func newWriteSentinelPool(options *SentinelOptions) *redis.Pool {
+// GetScriptRegistry returns the registry of scripts loaded on every new connection
+func (o *SentinelOptions) GetScriptRegistry() *ScriptRegistry {
+	return o.ScriptRegistry
+}
+
+// GetClientName returns the name given to every new connection
+func (o *SentinelOptions) GetClientName() string {
+	return o.ClientName
+}
+
+// GetWriteClientName returns the name given to the master's connections, the client name followed by the write suffix
+func (o *SentinelOptions) GetWriteClientName() string {
+	if len(o.ClientName) == 0 {
+		return ""
+	}
+	return o.ClientName + o.WriteClientNameSuffix
+}
+
+// GetReadClientName returns the name given to the slaves' connections, the client name followed by the read suffix
+func (o *SentinelOptions) GetReadClientName() string {
+	if len(o.ClientName) == 0 {
+		return ""
+	}
+	return o.ClientName + o.ReadClientNameSuffix
+}
+
+// GetLibraryInfo returns whether new connections report the library's name and version
+func (o *SentinelOptions) GetLibraryInfo() bool {
+	return o.LibraryInfo
+}
+
+// GetSentinelUsername returns the ACL username used to authenticate with the sentinels
+func (o *SentinelOptions) GetSentinelUsername() string {
+	return o.SentinelUsername
+}
+
+// GetSentinelPassword returns the password used to authenticate with the sentinels
+func (o *SentinelOptions) GetSentinelPassword() string {
+	return o.SentinelPassword
+}
+
+// GetSentinelTlsConfig returns the tls config used to connect to the sentinels
+func (o *SentinelOptions) GetSentinelTlsConfig() *tls.Config {
+	return o.SentinelTlsConfig
+}
+
+// GetSentinelTlsSkipVerify returns sentinel tls skip verify
+func (o *SentinelOptions) GetSentinelTlsSkipVerify() bool {
+	return o.SentinelTlsSkipVerify
+}
+
+// GetCredentialsProvider returns the provider of every new server connection's credentials, the username and password by default
+func (o *SentinelOptions) GetCredentialsProvider() CredentialsProvider {
+	if o.CredentialsProvider != nil {
+		return o.CredentialsProvider
+	}
+	return staticCredentials(o.GetUsername(), o.GetPassword())
+}
+
+// GetReplicaSelector returns the selector of the replicas read connections go to, random by default
+func (o *SentinelOptions) GetReplicaSelector() ReplicaSelector {
+	return nextSelector(o.ReplicaSelector)
+}
+
+func newWriteSentinelPool(options *SentinelOptions, sentinelDetails *sentinel.Sentinel, monitor *sentinelMonitor) *redis.Pool {
 	connectionIdleTimeout := options.GetConnectionIdleTimeout()
 	connectionMaxActive := options.GetConnectionMaxActive()
 	connectionMaxIdle := options.GetConnectionMaxIdle()
@@ -168,12 +248,12 @@ func newWriteSentinelPool(options *SentinelOptions) *redis.Pool {
 		MaxActive:    connectionMaxActive,
 		MaxIdle:      connectionMaxIdle,
 		Wait:         connectionWait,
-		Dial:         sentinelWriteDial(options),
+		Dial:         interruptibleDial(sentinelWriteDial(options, sentinelDetails, monitor)),
 		TestOnBorrow: sentinelMasterTestOnBorrow(options),
 	}
 }
 
-func newReadSentinelPool(options *SentinelOptions) *redis.Pool {
+func newReadSentinelPool(options *SentinelOptions, sentinelDetails *sentinel.Sentinel, monitor *sentinelMonitor) *redis.Pool {
 	connectionIdleTimeout := options.GetConnectionIdleTimeout()
 	connectionMaxActive := options.GetConnectionMaxActive()
 	connectionMaxIdle := options.GetConnectionMaxIdle()
@@ -184,20 +264,17 @@ func newReadSentinelPool(options *SentinelOptions) *redis.Pool {
 		MaxActive:    connectionMaxActive,
 		MaxIdle:      connectionMaxIdle,
 		Wait:         connectionWait,
-		Dial:         sentinelReadDial(options),
+		Dial:         interruptibleDial(sentinelReadDial(options, sentinelDetails, monitor)),
 		TestOnBorrow: sentinelTestOnBorrow(options),
 	}
 }
 
+// createSentinel connects to the sentinels with their own credentials and tls settings
 func createSentinel(options *SentinelOptions) *sentinel.Sentinel {
 	sentinelNetwork := options.GetNetwork()
-
-	dialSentinelOptions := make([]redis.DialOption, 5)
-	dialSentinelOptions[0] = redis.DialConnectTimeout(options.GetConnectTimeout())
-	dialSentinelOptions[1] = redis.DialWriteTimeout(options.GetWriteTimeout())
-	dialSentinelOptions[2] = redis.DialReadTimeout(options.GetReadTimeout())
-	dialSentinelOptions[3] = redis.DialTLSSkipVerify(options.GetTlsSkipVerify())
-	dialSentinelOptions[4] = redis.DialTLSConfig(options.GetTlsConfig())
+	sentinelUsername := options.GetSentinelUsername()
+	sentinelPassword := options.GetSentinelPassword()
+	dialSentinelOptions := sentinelDialOptions(options)
 
 	return &sentinel.Sentinel{
 		Addrs:      options.GetAddresses(),
@@ -207,24 +284,50 @@ func createSentinel(options *SentinelOptions) *sentinel.Sentinel {
 			if err != nil {
 				return nil, err
 			}
+
+			err = authenticate(connection, sentinelUsername, sentinelPassword)
+			if err != nil {
+				connection.Close()
+				return nil, err
+			}
 			return connection, nil
 		},
 	}
 }
 
-func sentinelWriteDial(options *SentinelOptions) func() (redis.Conn, error) {
-	sentinelDetails := createSentinel(options)
+// sentinelDialOptions returns the options of the connections to the sentinels. Tls is used only if either sentinel tls
+// setting is set, regardless of the servers' tls settings
+func sentinelDialOptions(options *SentinelOptions) []redis.DialOption {
+	dialSentinelOptions := make([]redis.DialOption, 3, 6)
+	dialSentinelOptions[0] = redis.DialConnectTimeout(options.GetConnectTimeout())
+	dialSentinelOptions[1] = redis.DialWriteTimeout(options.GetWriteTimeout())
+	dialSentinelOptions[2] = redis.DialReadTimeout(options.GetReadTimeout())
 
+	if options.GetSentinelTlsConfig() != nil || options.GetSentinelTlsSkipVerify() {
+		dialSentinelOptions = append(dialSentinelOptions,
+			redis.DialUseTLS(true),
+			redis.DialTLSSkipVerify(options.GetSentinelTlsSkipVerify()),
+			redis.DialTLSConfig(options.GetSentinelTlsConfig()))
+	}
+	return dialSentinelOptions
+}
+
+func sentinelWriteDial(options *SentinelOptions, sentinelDetails *sentinel.Sentinel, monitor *sentinelMonitor) func() (redis.Conn, error) {
 	network := options.GetNetwork()
+	setup := connectionSetup{
+		credentials:    options.GetCredentialsProvider(),
+		database:       options.GetDatabase(),
+		clientName:     options.GetWriteClientName(),
+		libraryInfo:    options.GetLibraryInfo(),
+		scriptRegistry: options.GetScriptRegistry(),
+	}
 
-	dialServerOptions := make([]redis.DialOption, 7)
-	dialServerOptions[0] = redis.DialPassword(options.GetPassword())
-	dialServerOptions[1] = redis.DialDatabase(options.GetDatabase())
-	dialServerOptions[2] = redis.DialConnectTimeout(options.GetConnectTimeout())
-	dialServerOptions[3] = redis.DialWriteTimeout(options.GetWriteTimeout())
-	dialServerOptions[4] = redis.DialReadTimeout(options.GetReadTimeout())
-	dialServerOptions[5] = redis.DialTLSSkipVerify(options.GetTlsSkipVerify())
-	dialServerOptions[6] = redis.DialTLSConfig(options.GetTlsConfig())
+	dialServerOptions := make([]redis.DialOption, 5)
+	dialServerOptions[0] = redis.DialConnectTimeout(options.GetConnectTimeout())
+	dialServerOptions[1] = redis.DialWriteTimeout(options.GetWriteTimeout())
+	dialServerOptions[2] = redis.DialReadTimeout(options.GetReadTimeout())
+	dialServerOptions[3] = redis.DialTLSSkipVerify(options.GetTlsSkipVerify())
+	dialServerOptions[4] = redis.DialTLSConfig(options.GetTlsConfig())
 
 	return func() (redis.Conn, error) {
 		address, err := sentinelDetails.MasterAddr()
@@ -236,23 +339,47 @@ func sentinelWriteDial(options *SentinelOptions) func() (redis.Conn, error) {
 		if err != nil {
 			return nil, err
 		}
-		return connection, nil
+
+		connection, err = setup.prepare(connection)
+		if err != nil {
+			return nil, err
+		}
+		return monitor.track(connection, address, true), nil
 	}
 }
 
-func sentinelReadDial(options *SentinelOptions) func() (redis.Conn, error) {
-	sentinelDetails := createSentinel(options)
-
+func sentinelReadDial(options *SentinelOptions, sentinelDetails *sentinel.Sentinel, monitor *sentinelMonitor) func() (redis.Conn, error) {
 	network := options.GetNetwork()
+	selector := options.GetReplicaSelector()
+	setup := connectionSetup{
+		credentials:    options.GetCredentialsProvider(),
+		database:       options.GetDatabase(),
+		clientName:     options.GetReadClientName(),
+		libraryInfo:    options.GetLibraryInfo(),
+		scriptRegistry: options.GetScriptRegistry(),
+	}
+	probeSetup := connectionSetup{credentials: setup.credentials, database: setup.database}
 
-	dialServerOptions := make([]redis.DialOption, 7)
-	dialServerOptions[0] = redis.DialPassword(options.GetPassword())
-	dialServerOptions[1] = redis.DialDatabase(options.GetDatabase())
-	dialServerOptions[2] = redis.DialConnectTimeout(options.GetConnectTimeout())
-	dialServerOptions[3] = redis.DialWriteTimeout(options.GetWriteTimeout())
-	dialServerOptions[4] = redis.DialReadTimeout(options.GetReadTimeout())
-	dialServerOptions[5] = redis.DialTLSSkipVerify(options.GetTlsSkipVerify())
-	dialServerOptions[6] = redis.DialTLSConfig(options.GetTlsConfig())
+	dialServerOptions := make([]redis.DialOption, 5)
+	dialServerOptions[0] = redis.DialConnectTimeout(options.GetConnectTimeout())
+	dialServerOptions[1] = redis.DialWriteTimeout(options.GetWriteTimeout())
+	dialServerOptions[2] = redis.DialReadTimeout(options.GetReadTimeout())
+	dialServerOptions[3] = redis.DialTLSSkipVerify(options.GetTlsSkipVerify())
+	dialServerOptions[4] = redis.DialTLSConfig(options.GetTlsConfig())
+
+	connect := func(address string, setup connectionSetup) (redis.Conn, error) {
+		connection, err := redis.Dial(network, address, dialServerOptions...)
+		if err != nil {
+			return nil, err
+		}
+		return setup.prepare(connection)
+	}
+
+	probeDial := func(address string) (redis.Conn, error) {
+		return connect(address, probeSetup)
+	}
+
+	probe := &ReplicaProbe{dial: probeDial, master: sentinelDetails.MasterAddr, monitor: monitor, ttl: defaultReplicaProbeTTL}
 
 	return func() (redis.Conn, error) {
 		addresses, err := sentinelDetails.SlaveAddrs()
@@ -260,22 +387,19 @@ func sentinelReadDial(options *SentinelOptions) func() (redis.Conn, error) {
 			return nil, err
 		}
 
-		var address string
-		if len(addresses) > 0 {
-			rand.Seed(time.Now().Unix())
-			address = addresses[rand.Int()%len(addresses)]
-		} else {
+		address, ok := selector.Select(addresses, probe)
+		if !ok {
 			address, err = sentinelDetails.MasterAddr()
 			if err != nil {
 				return nil, err
 			}
 		}
 
-		connection, err := redis.Dial(network, address, dialServerOptions...)
+		connection, err := connect(address, setup)
 		if err != nil {
 			return nil, err
 		}
-		return connection, nil
+		return monitor.track(connection, address, false), nil
 	}
 }
 
@@ -283,11 +407,21 @@ func sentinelTestOnBorrow(options *SentinelOptions) func(redis.Conn, time.Time)
 	period := options.GetTestOnBorrowPeriod()
 
 	return func(connection redis.Conn, t time.Time) error {
+		err := borrowMonitored(connection)
+		if err != nil {
+			return err
+		}
+
+		err = connection.Err()
+		if err != nil {
+			return err
+		}
+
 		if time.Since(t) < period {
 			return nil
 		}
 
-		_, err := connection.Do(pingCommand)
+		_, err = connection.Do(pingCommand)
 		return err
 	}
 }
@@ -296,6 +430,16 @@ func sentinelMasterTestOnBorrow(options *SentinelOptions) func(redis.Conn, time.
 	period := options.GetTestOnBorrowPeriod()
 
 	return func(connection redis.Conn, t time.Time) error {
+		err := borrowMonitored(connection)
+		if err != nil {
+			return err
+		}
+
+		err = connection.Err()
+		if err != nil {
+			return err
+		}
+
 		if !sentinel.TestRole(connection, masterRole) {
 			return errors.New(masterRoleCheckError)
 		}
@@ -304,7 +448,7 @@ func sentinelMasterTestOnBorrow(options *SentinelOptions) func(redis.Conn, time.
 			return nil
 		}
 
-		_, err := connection.Do(pingCommand)
+		_, err = connection.Do(pingCommand)
 		return err
 	}
 }
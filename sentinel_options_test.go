@@ -3,6 +3,7 @@ package xredis
 import (
 	"crypto/tls"
 	"github.com/stretchr/testify/assert"
+	"net"
 	"testing"
 	"time"
 )
@@ -151,3 +152,124 @@ func TestSentinelOptions_GetTestOnBorrowPeriod(t *testing.T) {
 	options = SentinelOptions{TestOnBorrowPeriod: -1}
 	assert.Equal(t, options.GetTestOnBorrowPeriod(), defaultTestOnBorrowTimeout)
 }
+
+func TestSentinelOptions_GetScriptRegistry(t *testing.T) {
+	options := SentinelOptions{}
+	assert.Nil(t, options.GetScriptRegistry())
+
+	registry := NewScriptRegistry()
+	options = SentinelOptions{ScriptRegistry: registry}
+	assert.Equal(t, options.GetScriptRegistry(), registry)
+}
+
+func TestSentinelOptions_GetClientName(t *testing.T) {
+	options := SentinelOptions{WriteClientNameSuffix: ":write", ReadClientNameSuffix: ":read"}
+	assert.Equal(t, options.GetClientName(), "")
+	assert.Equal(t, options.GetWriteClientName(), "")
+	assert.Equal(t, options.GetReadClientName(), "")
+	assert.False(t, options.GetLibraryInfo())
+
+	options = SentinelOptions{ClientName: "worker", LibraryInfo: true}
+	assert.Equal(t, options.GetWriteClientName(), "worker")
+	assert.Equal(t, options.GetReadClientName(), "worker")
+	assert.True(t, options.GetLibraryInfo())
+
+	options = SentinelOptions{ClientName: "worker", WriteClientNameSuffix: ":write", ReadClientNameSuffix: ":read"}
+	assert.Equal(t, options.GetClientName(), "worker")
+	assert.Equal(t, options.GetWriteClientName(), "worker:write")
+	assert.Equal(t, options.GetReadClientName(), "worker:read")
+}
+
+func TestSentinelOptions_GetSentinelCredentials(t *testing.T) {
+	options := SentinelOptions{Password: "abc"}
+	assert.Equal(t, options.GetSentinelUsername(), "")
+	assert.Equal(t, options.GetSentinelPassword(), "")
+
+	options = SentinelOptions{SentinelUsername: "sentinel", SentinelPassword: "def"}
+	assert.Equal(t, options.GetSentinelUsername(), "sentinel")
+	assert.Equal(t, options.GetSentinelPassword(), "def")
+}
+
+func TestSentinelOptions_GetSentinelTls(t *testing.T) {
+	options := SentinelOptions{TlsConfig: &tls.Config{}, TlsSkipVerify: true}
+	assert.Nil(t, options.GetSentinelTlsConfig())
+	assert.False(t, options.GetSentinelTlsSkipVerify())
+
+	config := &tls.Config{ServerName: "sentinel"}
+	options = SentinelOptions{SentinelTlsConfig: config, SentinelTlsSkipVerify: true}
+	assert.Equal(t, options.GetSentinelTlsConfig(), config)
+	assert.True(t, options.GetSentinelTlsSkipVerify())
+}
+
+func TestSentinelOptions_GetCredentialsProvider(t *testing.T) {
+	options := SentinelOptions{Username: "user", Password: "secret"}
+	assert.Equal(t, options.GetUsername(), "user")
+
+	username, password, err := options.GetCredentialsProvider()()
+	assert.Equal(t, username, "user")
+	assert.Equal(t, password, "secret")
+	assert.Nil(t, err)
+
+	options = SentinelOptions{CredentialsProvider: func() (string, string, error) {
+		return "rotated", "token", nil
+	}}
+
+	username, password, err = options.GetCredentialsProvider()()
+	assert.Equal(t, username, "rotated")
+	assert.Equal(t, password, "token")
+	assert.Nil(t, err)
+}
+
+func TestSentinelOptions_GetReplicaSelector(t *testing.T) {
+	options := SentinelOptions{}
+	assert.Equal(t, options.GetReplicaSelector(), RandomSelector{})
+
+	selector := &RoundRobinSelector{}
+	options = SentinelOptions{ReplicaSelector: selector}
+	assert.Equal(t, options.GetReplicaSelector(), selector)
+}
+
+func TestSentinelDialOptions(t *testing.T) {
+	assert.Equal(t, firstSentinelByte(t, &SentinelOptions{}), byte('*'))
+	assert.Equal(t, firstSentinelByte(t, &SentinelOptions{SentinelTlsSkipVerify: true}), tlsHandshakeByte)
+	assert.Equal(t, firstSentinelByte(t, &SentinelOptions{SentinelTlsConfig: &tls.Config{ServerName: "sentinel.local"}}), tlsHandshakeByte)
+}
+
+// tlsHandshakeByte starts every tls handshake record
+const tlsHandshakeByte = byte(0x16)
+
+// firstSentinelByte returns the first byte a sentinel receives from a connection dialed with the options,
+// which tells a tls handshake apart from a plain command
+func firstSentinelByte(t *testing.T, options *SentinelOptions) byte {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer listener.Close()
+
+	received := make(chan byte, 1)
+	go func() {
+		connection, err := listener.Accept()
+		if err != nil {
+			close(received)
+			return
+		}
+		defer connection.Close()
+
+		buffer := make([]byte, 1)
+		connection.SetReadDeadline(time.Now().Add(time.Second))
+		if _, err := connection.Read(buffer); err == nil {
+			received <- buffer[0]
+		}
+		close(received)
+	}()
+
+	options.ConnectTimeout = time.Second
+	options.ReadTimeout = time.Second
+	options.WriteTimeout = time.Second
+
+	connection, err := createSentinel(options).Dial(listener.Addr().String())
+	if err == nil {
+		connection.Do("PING")
+		connection.Close()
+	}
+	return <-received
+}
@@ -0,0 +1,181 @@
+package xredis
+
+import (
+	"github.com/garyburd/redigo/redis"
+)
+
+const (
+	sAddCommand        = "SADD"
+	sRemCommand        = "SREM"
+	sMembersCommand    = "SMEMBERS"
+	sIsMemberCommand   = "SISMEMBER"
+	sMIsMemberCommand  = "SMISMEMBER"
+	sCardCommand       = "SCARD"
+	sPopCommand        = "SPOP"
+	sRandMemberCommand = "SRANDMEMBER"
+	sMoveCommand       = "SMOVE"
+	sInterCommand      = "SINTER"
+	sUnionCommand      = "SUNION"
+	sDiffCommand       = "SDIFF"
+	sInterStoreCommand = "SINTERSTORE"
+	sUnionStoreCommand = "SUNIONSTORE"
+	sDiffStoreCommand  = "SDIFFSTORE"
+	sScanCommand       = "SSCAN"
+)
+
+// SAdd adds members to a set
+func (c *Client) SAdd(key string, members ...string) (int64, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return redis.Int64(connection.Do(sAddCommand, prepend(key, members)...))
+}
+
+// SRem removes members from a set
+func (c *Client) SRem(key string, members ...string) (int64, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return redis.Int64(connection.Do(sRemCommand, prepend(key, members)...))
+}
+
+// SMembers retrieves a set's members
+func (c *Client) SMembers(key string) ([]string, error) {
+	connection := c.getReadConnection()
+	defer connection.Close()
+
+	return redis.Strings(connection.Do(sMembersCommand, key))
+}
+
+// SIsMember determines a member's existence in a set
+func (c *Client) SIsMember(key string, member string) (bool, error) {
+	connection := c.getReadConnection()
+	defer connection.Close()
+
+	return redis.Bool(connection.Do(sIsMemberCommand, key, member))
+}
+
+// SMIsMember determines each member's existence in a set
+func (c *Client) SMIsMember(key string, members ...string) ([]bool, error) {
+	connection := c.getReadConnection()
+	defer connection.Close()
+
+	values, err := redis.Ints(connection.Do(sMIsMemberCommand, prepend(key, members)...))
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]bool, len(values))
+	for i, value := range values {
+		results[i] = value > 0
+	}
+	return results, nil
+}
+
+// SCard returns the number of members in a set
+func (c *Client) SCard(key string) (int64, error) {
+	connection := c.getReadConnection()
+	defer connection.Close()
+
+	return redis.Int64(connection.Do(sCardCommand, key))
+}
+
+// SPop removes and returns a random member of a set
+func (c *Client) SPop(key string) (string, bool, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return toString(connection.Do(sPopCommand, key))
+}
+
+// SPopCount removes and returns up to count random members of a set
+func (c *Client) SPopCount(key string, count int64) ([]string, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return toStrings(connection.Do(sPopCommand, key, count))
+}
+
+// SRandMember returns a random member of a set
+func (c *Client) SRandMember(key string) (string, bool, error) {
+	connection := c.getReadConnection()
+	defer connection.Close()
+
+	return toString(connection.Do(sRandMemberCommand, key))
+}
+
+// SRandMemberCount returns count random members of a set. A negative count allows repeated members
+func (c *Client) SRandMemberCount(key string, count int64) ([]string, error) {
+	connection := c.getReadConnection()
+	defer connection.Close()
+
+	return toStrings(connection.Do(sRandMemberCommand, key, count))
+}
+
+// SMove moves a member from one set to another
+func (c *Client) SMove(source string, destination string, member string) (bool, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return toPositive(connection.Do(sMoveCommand, source, destination, member))
+}
+
+// SInter returns the intersection of sets
+func (c *Client) SInter(keys ...string) ([]string, error) {
+	connection := c.getReadConnection()
+	defer connection.Close()
+
+	return redis.Strings(connection.Do(sInterCommand, toInterfaces(keys)...))
+}
+
+// SUnion returns the union of sets
+func (c *Client) SUnion(keys ...string) ([]string, error) {
+	connection := c.getReadConnection()
+	defer connection.Close()
+
+	return redis.Strings(connection.Do(sUnionCommand, toInterfaces(keys)...))
+}
+
+// SDiff returns the difference between the first set and the others
+func (c *Client) SDiff(keys ...string) ([]string, error) {
+	connection := c.getReadConnection()
+	defer connection.Close()
+
+	return redis.Strings(connection.Do(sDiffCommand, toInterfaces(keys)...))
+}
+
+// SInterStore stores the intersection of sets in the destination
+func (c *Client) SInterStore(destination string, keys ...string) (int64, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return redis.Int64(connection.Do(sInterStoreCommand, prepend(destination, keys)...))
+}
+
+// SUnionStore stores the union of sets in the destination
+func (c *Client) SUnionStore(destination string, keys ...string) (int64, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return redis.Int64(connection.Do(sUnionStoreCommand, prepend(destination, keys)...))
+}
+
+// SDiffStore stores the difference between the first set and the others in the destination
+func (c *Client) SDiffStore(destination string, keys ...string) (int64, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return redis.Int64(connection.Do(sDiffStoreCommand, prepend(destination, keys)...))
+}
+
+// SScan incrementally iterate over a set's members
+func (c *Client) SScan(key string, startIndex int64, pattern string) (int64, []string, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	results, err := redis.Values(connection.Do(sScanCommand, key, startIndex, matchOption, pattern))
+	if err != nil {
+		return 0, nil, err
+	}
+	return parseScanResults(results)
+}
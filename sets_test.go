@@ -0,0 +1,149 @@
+package xredis
+
+import (
+	"errors"
+	"github.com/garyburd/redigo/redis"
+	"github.com/rafaeljusto/redigomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestClient_SAdd(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("SADD", "set", "a", "b").Expect(int64(2))
+	connection.Command("SREM", "set", "a").Expect(int64(1))
+	connection.Command("SCARD", "set").Expect(int64(1))
+
+	client := mockClient(connection)
+
+	count, err := client.SAdd("set", "a", "b")
+	assert.Equal(t, count, int64(2))
+	assert.Nil(t, err)
+
+	count, err = client.SRem("set", "a")
+	assert.Equal(t, count, int64(1))
+	assert.Nil(t, err)
+
+	count, err = client.SCard("set")
+	assert.Equal(t, count, int64(1))
+	assert.Nil(t, err)
+}
+
+func TestClient_SMembers(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("SMEMBERS", "set").Expect([]interface{}{[]byte("a"), []byte("b")})
+	connection.Command("SISMEMBER", "set", "a").Expect(int64(1))
+	connection.Command("SMISMEMBER", "set", "a", "c").Expect([]interface{}{int64(1), int64(0)})
+	connection.Command("SMISMEMBER", "unknown", "a").ExpectError(errors.New("Oops"))
+
+	client := mockClient(connection)
+
+	members, err := client.SMembers("set")
+	assert.Equal(t, members, []string{"a", "b"})
+	assert.Nil(t, err)
+
+	ok, err := client.SIsMember("set", "a")
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	results, err := client.SMIsMember("set", "a", "c")
+	assert.Equal(t, results, []bool{true, false})
+	assert.Nil(t, err)
+
+	results, err = client.SMIsMember("unknown", "a")
+	assert.Nil(t, results)
+	assert.NotNil(t, err)
+}
+
+func TestClient_SPop(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("SPOP", "set").Expect([]byte("a"))
+	connection.Command("SPOP", "unknown").ExpectError(redis.ErrNil)
+	connection.Command("SPOP", "set", int64(2)).Expect([]interface{}{[]byte("b"), []byte("c")})
+	connection.Command("SRANDMEMBER", "set").Expect([]byte("d"))
+	connection.Command("SRANDMEMBER", "set", int64(-2)).Expect([]interface{}{[]byte("d"), []byte("d")})
+
+	client := mockClient(connection)
+
+	member, ok, err := client.SPop("set")
+	assert.Equal(t, member, "a")
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	member, ok, err = client.SPop("unknown")
+	assert.Equal(t, member, "")
+	assert.False(t, ok)
+	assert.Nil(t, err)
+
+	members, err := client.SPopCount("set", 2)
+	assert.Equal(t, members, []string{"b", "c"})
+	assert.Nil(t, err)
+
+	member, ok, err = client.SRandMember("set")
+	assert.Equal(t, member, "d")
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	members, err = client.SRandMemberCount("set", -2)
+	assert.Equal(t, members, []string{"d", "d"})
+	assert.Nil(t, err)
+}
+
+func TestClient_SMove(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("SMOVE", "source", "destination", "a").Expect(int64(1))
+
+	client := mockClient(connection)
+
+	ok, err := client.SMove("source", "destination", "a")
+	assert.True(t, ok)
+	assert.Nil(t, err)
+}
+
+func TestClient_SInter(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("SINTER", "a", "b").Expect([]interface{}{[]byte("x")})
+	connection.Command("SUNION", "a", "b").Expect([]interface{}{[]byte("x"), []byte("y")})
+	connection.Command("SDIFF", "a", "b").Expect([]interface{}{[]byte("y")})
+	connection.Command("SINTERSTORE", "c", "a", "b").Expect(int64(1))
+	connection.Command("SUNIONSTORE", "c", "a", "b").Expect(int64(2))
+	connection.Command("SDIFFSTORE", "c", "a", "b").Expect(int64(1))
+
+	client := mockClient(connection)
+
+	members, err := client.SInter("a", "b")
+	assert.Equal(t, members, []string{"x"})
+	assert.Nil(t, err)
+
+	members, err = client.SUnion("a", "b")
+	assert.Equal(t, members, []string{"x", "y"})
+	assert.Nil(t, err)
+
+	members, err = client.SDiff("a", "b")
+	assert.Equal(t, members, []string{"y"})
+	assert.Nil(t, err)
+
+	count, err := client.SInterStore("c", "a", "b")
+	assert.Equal(t, count, int64(1))
+	assert.Nil(t, err)
+
+	count, err = client.SUnionStore("c", "a", "b")
+	assert.Equal(t, count, int64(2))
+	assert.Nil(t, err)
+
+	count, err = client.SDiffStore("c", "a", "b")
+	assert.Equal(t, count, int64(1))
+	assert.Nil(t, err)
+}
+
+func TestClient_SScan(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("SSCAN", "set", int64(0), "MATCH", "*").Expect([]interface{}{[]byte("0"), []interface{}{[]byte("a")}})
+
+	client := mockClient(connection)
+
+	index, results, err := client.SScan("set", 0, "*")
+	assert.Equal(t, index, int64(0))
+	assert.Equal(t, results, []string{"a"})
+	assert.Nil(t, err)
+}
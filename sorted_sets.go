@@ -0,0 +1,343 @@
+package xredis
+
+import (
+	"errors"
+	"github.com/garyburd/redigo/redis"
+	"strconv"
+	"time"
+)
+
+const (
+	greaterThanOption = "GT"
+	lessThanOption    = "LT"
+	changedOption     = "CH"
+	incrOption        = "INCR"
+	withScoresOption  = "WITHSCORES"
+	byScoreOption     = "BYSCORE"
+	byLexOption       = "BYLEX"
+	revOption         = "REV"
+	limitOption       = "LIMIT"
+
+	zAddCommand             = "ZADD"
+	zIncrByCommand          = "ZINCRBY"
+	zScoreCommand           = "ZSCORE"
+	zRankCommand            = "ZRANK"
+	zRevRankCommand         = "ZREVRANK"
+	zRemCommand             = "ZREM"
+	zCardCommand            = "ZCARD"
+	zCountCommand           = "ZCOUNT"
+	zRangeCommand           = "ZRANGE"
+	zRemRangeByRankCommand  = "ZREMRANGEBYRANK"
+	zRemRangeByScoreCommand = "ZREMRANGEBYSCORE"
+	zRemRangeByLexCommand   = "ZREMRANGEBYLEX"
+	zPopMinCommand          = "ZPOPMIN"
+	zPopMaxCommand          = "ZPOPMAX"
+	bZPopMinCommand         = "BZPOPMIN"
+	bZPopMaxCommand         = "BZPOPMAX"
+	zScanCommand            = "ZSCAN"
+
+	oddRepliesError = "expected an even number of replies"
+)
+
+// ZMember is a sorted set's member and its score
+type ZMember struct {
+	Member string
+	Score  float64
+}
+
+// ZAddOptions contains ZADD options
+type ZAddOptions struct {
+	NX bool
+	XX bool
+	GT bool
+	LT bool
+	CH bool
+}
+
+// ZRangeOptions contains options for score and lex ranges.
+// An offset without a count skips the first members and returns all the others
+type ZRangeOptions struct {
+	Rev    bool
+	Offset int64
+	Count  int64
+}
+
+// ZAdd adds members to a sorted set, or updates their scores if they exist
+func (c *Client) ZAdd(key string, members ...ZMember) (int64, error) {
+	return c.ZAddWithOptions(key, ZAddOptions{}, members...)
+}
+
+// ZAddWithOptions adds members to a sorted set using the options provided.
+// Returns the number of added members, or the number of changed members if CH is set
+func (c *Client) ZAddWithOptions(key string, options ZAddOptions, members ...ZMember) (int64, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	args := append(zAddArgs(key, options), fromZMembers(members)...)
+	return redis.Int64(connection.Do(zAddCommand, args...))
+}
+
+// ZAddIncr increments a member's score using the options provided.
+// Returns the new score and whether the member was updated
+func (c *Client) ZAddIncr(key string, options ZAddOptions, member ZMember) (float64, bool, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	args := append(zAddArgs(key, options), incrOption, member.Score, member.Member)
+	return toFloat(connection.Do(zAddCommand, args...))
+}
+
+// ZIncrBy increments a member's score by the increment provided
+func (c *Client) ZIncrBy(key string, increment float64, member string) (float64, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return redis.Float64(connection.Do(zIncrByCommand, key, increment, member))
+}
+
+// ZScore retrieves a member's score
+func (c *Client) ZScore(key string, member string) (float64, bool, error) {
+	connection := c.getReadConnection()
+	defer connection.Close()
+
+	return toFloat(connection.Do(zScoreCommand, key, member))
+}
+
+// ZRank retrieves a member's rank ordered from the lowest score
+func (c *Client) ZRank(key string, member string) (int64, bool, error) {
+	connection := c.getReadConnection()
+	defer connection.Close()
+
+	return toInt(connection.Do(zRankCommand, key, member))
+}
+
+// ZRevRank retrieves a member's rank ordered from the highest score
+func (c *Client) ZRevRank(key string, member string) (int64, bool, error) {
+	connection := c.getReadConnection()
+	defer connection.Close()
+
+	return toInt(connection.Do(zRevRankCommand, key, member))
+}
+
+// ZRem removes members from a sorted set
+func (c *Client) ZRem(key string, members ...string) (int64, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return redis.Int64(connection.Do(zRemCommand, prepend(key, members)...))
+}
+
+// ZCard returns the number of members in a sorted set
+func (c *Client) ZCard(key string) (int64, error) {
+	connection := c.getReadConnection()
+	defer connection.Close()
+
+	return redis.Int64(connection.Do(zCardCommand, key))
+}
+
+// ZCount returns the number of members with a score between min and max, such as "-inf", "(1" or "+inf"
+func (c *Client) ZCount(key string, min string, max string) (int64, error) {
+	connection := c.getReadConnection()
+	defer connection.Close()
+
+	return redis.Int64(connection.Do(zCountCommand, key, min, max))
+}
+
+// ZRange retrieves the members between the start and stop ranks ordered from the lowest score
+func (c *Client) ZRange(key string, start int64, stop int64) ([]ZMember, error) {
+	connection := c.getReadConnection()
+	defer connection.Close()
+
+	return toZMembers(connection.Do(zRangeCommand, key, start, stop, withScoresOption))
+}
+
+// ZRevRange retrieves the members between the start and stop ranks ordered from the highest score
+func (c *Client) ZRevRange(key string, start int64, stop int64) ([]ZMember, error) {
+	connection := c.getReadConnection()
+	defer connection.Close()
+
+	return toZMembers(connection.Do(zRangeCommand, key, start, stop, revOption, withScoresOption))
+}
+
+// ZRangeByScore retrieves the members with a score between min and max, such as "-inf", "(1" or "+inf"
+func (c *Client) ZRangeByScore(key string, min string, max string, options ZRangeOptions) ([]ZMember, error) {
+	connection := c.getReadConnection()
+	defer connection.Close()
+
+	args := append(zRangeArgs(key, min, max, byScoreOption, options), withScoresOption)
+	return toZMembers(connection.Do(zRangeCommand, args...))
+}
+
+// ZRangeByLex retrieves the members between min and max lexicographically, such as "-", "[a", "(b" or "+"
+func (c *Client) ZRangeByLex(key string, min string, max string, options ZRangeOptions) ([]string, error) {
+	connection := c.getReadConnection()
+	defer connection.Close()
+
+	return redis.Strings(connection.Do(zRangeCommand, zRangeArgs(key, min, max, byLexOption, options)...))
+}
+
+// ZRemRangeByRank removes the members between the start and stop ranks
+func (c *Client) ZRemRangeByRank(key string, start int64, stop int64) (int64, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return redis.Int64(connection.Do(zRemRangeByRankCommand, key, start, stop))
+}
+
+// ZRemRangeByScore removes the members with a score between min and max
+func (c *Client) ZRemRangeByScore(key string, min string, max string) (int64, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return redis.Int64(connection.Do(zRemRangeByScoreCommand, key, min, max))
+}
+
+// ZRemRangeByLex removes the members between min and max lexicographically
+func (c *Client) ZRemRangeByLex(key string, min string, max string) (int64, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return redis.Int64(connection.Do(zRemRangeByLexCommand, key, min, max))
+}
+
+// ZPopMin removes and returns up to count members with the lowest scores
+func (c *Client) ZPopMin(key string, count int64) ([]ZMember, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return toZMembers(connection.Do(zPopMinCommand, key, count))
+}
+
+// ZPopMax removes and returns up to count members with the highest scores
+func (c *Client) ZPopMax(key string, count int64) ([]ZMember, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return toZMembers(connection.Do(zPopMaxCommand, key, count))
+}
+
+// BZPopMin removes and returns the member with the lowest score of the first non empty sorted set,
+// blocking up to the timeout. A timeout of zero blocks indefinitely
+func (c *Client) BZPopMin(timeout time.Duration, keys ...string) (string, ZMember, bool, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return toKeyZMember(doBlocking(connection, timeout, bZPopMinCommand, append(toInterfaces(keys), timeout.Seconds())...))
+}
+
+// BZPopMax removes and returns the member with the highest score of the first non empty sorted set,
+// blocking up to the timeout. A timeout of zero blocks indefinitely
+func (c *Client) BZPopMax(timeout time.Duration, keys ...string) (string, ZMember, bool, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return toKeyZMember(doBlocking(connection, timeout, bZPopMaxCommand, append(toInterfaces(keys), timeout.Seconds())...))
+}
+
+// ZScan incrementally iterate over a sorted set's members and scores
+func (c *Client) ZScan(key string, startIndex int64, pattern string) (int64, []ZMember, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	results, err := redis.Values(connection.Do(zScanCommand, key, startIndex, matchOption, pattern))
+	if err != nil {
+		return 0, nil, err
+	}
+
+	cursorIndex, values, err := parseScanResults(results)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	members, err := parseZMembers(values)
+	if err != nil {
+		return 0, nil, err
+	}
+	return cursorIndex, members, nil
+}
+
+func zAddArgs(key string, options ZAddOptions) []interface{} {
+	args := []interface{}{key}
+	if options.NX {
+		args = append(args, notExistsOption)
+	}
+	if options.XX {
+		args = append(args, existsOption)
+	}
+	if options.GT {
+		args = append(args, greaterThanOption)
+	}
+	if options.LT {
+		args = append(args, lessThanOption)
+	}
+	if options.CH {
+		args = append(args, changedOption)
+	}
+	return args
+}
+
+func zRangeArgs(key string, min string, max string, by string, options ZRangeOptions) []interface{} {
+	args := []interface{}{key, min, max, by}
+	if options.Rev {
+		args = []interface{}{key, max, min, by, revOption}
+	}
+	switch {
+	case options.Count != 0:
+		args = append(args, limitOption, options.Offset, options.Count)
+	case options.Offset != 0:
+		args = append(args, limitOption, options.Offset, int64(-1))
+	}
+	return args
+}
+
+func fromZMembers(members []ZMember) []interface{} {
+	args := make([]interface{}, 0, len(members)*2)
+	for _, member := range members {
+		args = append(args, member.Score, member.Member)
+	}
+	return args
+}
+
+func toZMembers(reply interface{}, err error) ([]ZMember, error) {
+	values, err := toStrings(reply, err)
+	if err != nil {
+		return nil, err
+	}
+	return parseZMembers(values)
+}
+
+func toKeyZMember(reply interface{}, err error) (string, ZMember, bool, error) {
+	values, e := redis.Strings(reply, err)
+	if e == redis.ErrNil {
+		return "", ZMember{}, false, nil
+	}
+	if e != nil {
+		return "", ZMember{}, false, e
+	}
+
+	if len(values) != 3 {
+		return "", ZMember{}, false, nil
+	}
+
+	members, e := parseZMembers(values[1:])
+	if e != nil {
+		return "", ZMember{}, false, e
+	}
+	return values[0], members[0], true, nil
+}
+
+func parseZMembers(values []string) ([]ZMember, error) {
+	if len(values)%2 != 0 {
+		return nil, errors.New(oddRepliesError)
+	}
+
+	members := make([]ZMember, len(values)/2)
+	for i := range members {
+		score, err := strconv.ParseFloat(values[2*i+1], 64)
+		if err != nil {
+			return nil, err
+		}
+		members[i] = ZMember{Member: values[2*i], Score: score}
+	}
+	return members, nil
+}
@@ -0,0 +1,192 @@
+package xredis
+
+import (
+	"errors"
+	"github.com/garyburd/redigo/redis"
+	"github.com/rafaeljusto/redigomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestClient_ZAdd(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("ZADD", "board", 1.5, "a", float64(2), "b").Expect(int64(2))
+	connection.Command("ZADD", "board", "XX", "GT", "CH", float64(3), "a").Expect(int64(1))
+	connection.Command("ZADD", "board", "NX", "INCR", float64(1), "a").Expect(nil)
+	connection.Command("ZADD", "board", "LT", "INCR", float64(-1), "a").Expect([]byte("2"))
+
+	client := mockClient(connection)
+
+	count, err := client.ZAdd("board", ZMember{Member: "a", Score: 1.5}, ZMember{Member: "b", Score: 2})
+	assert.Equal(t, count, int64(2))
+	assert.Nil(t, err)
+
+	count, err = client.ZAddWithOptions("board", ZAddOptions{XX: true, GT: true, CH: true}, ZMember{Member: "a", Score: 3})
+	assert.Equal(t, count, int64(1))
+	assert.Nil(t, err)
+
+	score, ok, err := client.ZAddIncr("board", ZAddOptions{NX: true}, ZMember{Member: "a", Score: 1})
+	assert.Equal(t, score, float64(0))
+	assert.False(t, ok)
+	assert.Nil(t, err)
+
+	score, ok, err = client.ZAddIncr("board", ZAddOptions{LT: true}, ZMember{Member: "a", Score: -1})
+	assert.Equal(t, score, float64(2))
+	assert.True(t, ok)
+	assert.Nil(t, err)
+}
+
+func TestClient_ZScore(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("ZSCORE", "board", "a").Expect([]byte("1.5"))
+	connection.Command("ZSCORE", "board", "unknown").Expect(nil)
+	connection.Command("ZINCRBY", "board", float64(2), "a").Expect([]byte("3.5"))
+	connection.Command("ZRANK", "board", "a").Expect(int64(0))
+	connection.Command("ZREVRANK", "board", "unknown").Expect(nil)
+
+	client := mockClient(connection)
+
+	score, ok, err := client.ZScore("board", "a")
+	assert.Equal(t, score, 1.5)
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	score, ok, err = client.ZScore("board", "unknown")
+	assert.False(t, ok)
+	assert.Nil(t, err)
+
+	score, err = client.ZIncrBy("board", 2, "a")
+	assert.Equal(t, score, 3.5)
+	assert.Nil(t, err)
+
+	rank, ok, err := client.ZRank("board", "a")
+	assert.Equal(t, rank, int64(0))
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	rank, ok, err = client.ZRevRank("board", "unknown")
+	assert.False(t, ok)
+	assert.Nil(t, err)
+}
+
+func TestClient_ZRem(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("ZREM", "board", "a", "b").Expect(int64(2))
+	connection.Command("ZCARD", "board").Expect(int64(3))
+	connection.Command("ZCOUNT", "board", "-inf", "(5").Expect(int64(1))
+	connection.Command("ZREMRANGEBYRANK", "board", int64(0), int64(1)).Expect(int64(2))
+	connection.Command("ZREMRANGEBYSCORE", "board", "-inf", "10").Expect(int64(1))
+	connection.Command("ZREMRANGEBYLEX", "board", "[a", "[c").Expect(int64(0))
+
+	client := mockClient(connection)
+
+	count, err := client.ZRem("board", "a", "b")
+	assert.Equal(t, count, int64(2))
+	assert.Nil(t, err)
+
+	count, err = client.ZCard("board")
+	assert.Equal(t, count, int64(3))
+	assert.Nil(t, err)
+
+	count, err = client.ZCount("board", "-inf", "(5")
+	assert.Equal(t, count, int64(1))
+	assert.Nil(t, err)
+
+	count, err = client.ZRemRangeByRank("board", 0, 1)
+	assert.Equal(t, count, int64(2))
+	assert.Nil(t, err)
+
+	count, err = client.ZRemRangeByScore("board", "-inf", "10")
+	assert.Equal(t, count, int64(1))
+	assert.Nil(t, err)
+
+	count, err = client.ZRemRangeByLex("board", "[a", "[c")
+	assert.Equal(t, count, int64(0))
+	assert.Nil(t, err)
+}
+
+func TestClient_ZRange(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("ZRANGE", "board", int64(0), int64(-1), "WITHSCORES").Expect([]interface{}{[]byte("a"), []byte("1"), []byte("b"), []byte("2")})
+	connection.Command("ZRANGE", "board", int64(0), int64(0), "REV", "WITHSCORES").Expect([]interface{}{[]byte("b"), []byte("2")})
+	connection.Command("ZRANGE", "board", "+inf", "(1", "BYSCORE", "REV", "LIMIT", int64(0), int64(1), "WITHSCORES").Expect([]interface{}{[]byte("b"), []byte("2")})
+	connection.Command("ZRANGE", "board", "-", "+", "BYLEX").Expect([]interface{}{[]byte("a"), []byte("b")})
+	connection.Command("ZRANGE", "board", "-", "+", "BYLEX", "LIMIT", int64(1), int64(-1)).Expect([]interface{}{[]byte("b")})
+	connection.Command("ZRANGE", "unknown", int64(0), int64(-1), "WITHSCORES").Expect([]interface{}{[]byte("a")})
+
+	client := mockClient(connection)
+
+	members, err := client.ZRange("board", 0, -1)
+	assert.Equal(t, members, []ZMember{{Member: "a", Score: 1}, {Member: "b", Score: 2}})
+	assert.Nil(t, err)
+
+	members, err = client.ZRevRange("board", 0, 0)
+	assert.Equal(t, members, []ZMember{{Member: "b", Score: 2}})
+	assert.Nil(t, err)
+
+	members, err = client.ZRangeByScore("board", "(1", "+inf", ZRangeOptions{Rev: true, Offset: 0, Count: 1})
+	assert.Equal(t, members, []ZMember{{Member: "b", Score: 2}})
+	assert.Nil(t, err)
+
+	values, err := client.ZRangeByLex("board", "-", "+", ZRangeOptions{})
+	assert.Equal(t, values, []string{"a", "b"})
+	assert.Nil(t, err)
+
+	values, err = client.ZRangeByLex("board", "-", "+", ZRangeOptions{Offset: 1})
+	assert.Equal(t, values, []string{"b"})
+	assert.Nil(t, err)
+
+	members, err = client.ZRange("unknown", 0, -1)
+	assert.Nil(t, members)
+	assert.NotNil(t, err)
+}
+
+func TestClient_ZPopMin(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("ZPOPMIN", "board", int64(1)).Expect([]interface{}{[]byte("a"), []byte("1")})
+	connection.Command("ZPOPMAX", "board", int64(1)).Expect([]interface{}{})
+	connection.Command("BZPOPMIN", "a", "b", float64(1)).Expect([]interface{}{[]byte("b"), []byte("x"), []byte("5")})
+	connection.Command("BZPOPMAX", "a", float64(0)).Expect(nil)
+	connection.Command("BZPOPMAX", "b", float64(0)).ExpectError(errors.New("Oops"))
+
+	client := mockBlockingClient(connection)
+
+	members, err := client.ZPopMin("board", 1)
+	assert.Equal(t, members, []ZMember{{Member: "a", Score: 1}})
+	assert.Nil(t, err)
+
+	members, err = client.ZPopMax("board", 1)
+	assert.Equal(t, members, []ZMember{})
+	assert.Nil(t, err)
+
+	key, member, ok, err := client.BZPopMin(time.Second, "a", "b")
+	assert.Equal(t, key, "b")
+	assert.Equal(t, member, ZMember{Member: "x", Score: 5})
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	_, _, ok, err = client.BZPopMax(0, "a")
+	assert.False(t, ok)
+	assert.Nil(t, err)
+
+	_, _, ok, err = client.BZPopMax(0, "b")
+	assert.False(t, ok)
+	assert.NotNil(t, err)
+}
+
+func TestClient_ZScan(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("ZSCAN", "board", int64(0), "MATCH", "*").Expect([]interface{}{[]byte("0"), []interface{}{[]byte("a"), []byte("1")}})
+	connection.Command("ZSCAN", "unknown", int64(0), "MATCH", "*").ExpectError(redis.ErrNil)
+
+	client := mockClient(connection)
+
+	index, members, err := client.ZScan("board", 0, "*")
+	assert.Equal(t, index, int64(0))
+	assert.Equal(t, members, []ZMember{{Member: "a", Score: 1}})
+	assert.Nil(t, err)
+
+	_, _, err = client.ZScan("unknown", 0, "*")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,235 @@
+package xredis
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+const (
+	newMessagesID     = ">"
+	pendingMessagesID = "0"
+	autoClaimStartID  = "0-0"
+	busyGroupError    = "BUSYGROUP"
+
+	defaultStreamConsumerStartID       = "$"
+	defaultStreamConsumerCount         = 10
+	defaultStreamConsumerBlock         = 5 * time.Second
+	defaultStreamConsumerMinIdle       = time.Minute
+	defaultStreamConsumerClaimInterval = 30 * time.Second
+	defaultStreamConsumerRetryBackoff  = time.Second
+)
+
+// StreamHandler handles a stream's message. The message is acknowledged if no error is returned
+type StreamHandler func(message StreamMessage) error
+
+// StreamConsumerOptions contains stream consumer options.
+// A negative Count or Block uses the default, while zero means no limit or waiting indefinitely.
+// MinIdle, ClaimInterval and RetryBackoff use the default unless positive, since zero would reclaim
+// in-flight messages or retry without pausing
+type StreamConsumerOptions struct {
+	Stream        string
+	Group         string
+	Consumer      string
+	StartID       string
+	Count         int64
+	Block         time.Duration
+	MinIdle       time.Duration
+	ClaimInterval time.Duration
+	RetryBackoff  time.Duration
+	ErrorHandler  func(err error)
+}
+
+// GetStartID returns the id the group starts at if it is created
+func (o *StreamConsumerOptions) GetStartID() string {
+	if len(o.StartID) == 0 {
+		return defaultStreamConsumerStartID
+	}
+	return o.StartID
+}
+
+// GetCount returns the maximum number of messages per read, zero for no limit
+func (o *StreamConsumerOptions) GetCount() int64 {
+	if o.Count < 0 {
+		return defaultStreamConsumerCount
+	}
+	return o.Count
+}
+
+// GetBlock returns how long a read waits for new messages, zero to wait indefinitely
+func (o *StreamConsumerOptions) GetBlock() time.Duration {
+	if o.Block < 0 {
+		return defaultStreamConsumerBlock
+	}
+	return o.Block
+}
+
+// GetMinIdle returns how long a message stays pending before it is reclaimed
+func (o *StreamConsumerOptions) GetMinIdle() time.Duration {
+	if o.MinIdle <= 0 {
+		return defaultStreamConsumerMinIdle
+	}
+	return o.MinIdle
+}
+
+// GetClaimInterval returns how often stale pending messages are reclaimed
+func (o *StreamConsumerOptions) GetClaimInterval() time.Duration {
+	if o.ClaimInterval <= 0 {
+		return defaultStreamConsumerClaimInterval
+	}
+	return o.ClaimInterval
+}
+
+// GetRetryBackoff returns how long to wait after an error
+func (o *StreamConsumerOptions) GetRetryBackoff() time.Duration {
+	if o.RetryBackoff <= 0 {
+		return defaultStreamConsumerRetryBackoff
+	}
+	return o.RetryBackoff
+}
+
+// StreamConsumer reads, handles and acknowledges a stream's messages as a member of a consumer group
+type StreamConsumer struct {
+	client  *Client
+	options *StreamConsumerOptions
+	handler StreamHandler
+}
+
+// NewStreamConsumer returns a stream consumer
+func (c *Client) NewStreamConsumer(options *StreamConsumerOptions, handler StreamHandler) *StreamConsumer {
+	return &StreamConsumer{client: c, options: options, handler: handler}
+}
+
+// Run creates the group if it does not exist, handles the consumer's pending messages and then new ones
+// while periodically reclaiming stale pending messages, until the context is done
+func (s *StreamConsumer) Run(ctx context.Context) error {
+	client := s.client.WithContext(ctx)
+
+	for {
+		err := s.createGroup(client)
+		if err == nil {
+			break
+		}
+		if s.fail(client, err) != nil {
+			return ctx.Err()
+		}
+	}
+
+	var lastClaim time.Time
+	claimInterval := s.options.GetClaimInterval()
+	pendingID := pendingMessagesID
+	for ctx.Err() == nil {
+		var err error
+		if time.Since(lastClaim) >= claimInterval {
+			err = s.claim(client)
+			lastClaim = time.Now()
+		} else if len(pendingID) > 0 {
+			pendingID, err = s.readPending(client, pendingID)
+		} else {
+			err = s.readNew(client)
+		}
+
+		if err != nil {
+			s.fail(client, err)
+		}
+	}
+	return ctx.Err()
+}
+
+func (s *StreamConsumer) createGroup(client *Client) error {
+	err := client.XGroupCreate(s.options.Stream, s.options.Group, s.options.GetStartID(), true)
+	if err != nil && strings.HasPrefix(err.Error(), busyGroupError) {
+		return nil
+	}
+	return err
+}
+
+func (s *StreamConsumer) readPending(client *Client, id string) (string, error) {
+	streams, err := client.XReadGroup(s.options.Group, s.options.Consumer, XReadGroupOptions{
+		Streams: []string{s.options.Stream},
+		IDs:     []string{id},
+		Count:   s.options.GetCount(),
+	})
+	if err != nil {
+		return id, err
+	}
+
+	messages := streamMessages(streams)
+	if len(messages) == 0 {
+		return "", nil
+	}
+
+	s.handle(messages)
+	return messages[len(messages)-1].ID, nil
+}
+
+func (s *StreamConsumer) readNew(client *Client) error {
+	streams, err := client.XReadGroup(s.options.Group, s.options.Consumer, XReadGroupOptions{
+		Streams: []string{s.options.Stream},
+		IDs:     []string{newMessagesID},
+		Count:   s.options.GetCount(),
+		Block:   true,
+		Timeout: s.options.GetBlock(),
+	})
+	if err != nil {
+		return err
+	}
+
+	s.handle(streamMessages(streams))
+	return nil
+}
+
+func (s *StreamConsumer) claim(client *Client) error {
+	start := autoClaimStartID
+	for {
+		next, messages, err := client.XAutoClaim(s.options.Stream, s.options.Group, s.options.Consumer, s.options.GetMinIdle(), start, s.options.GetCount())
+		if err != nil {
+			return err
+		}
+
+		s.handle(messages)
+		if next == autoClaimStartID || next == start || client.Context().Err() != nil {
+			return nil
+		}
+		start = next
+	}
+}
+
+func (s *StreamConsumer) handle(messages []StreamMessage) {
+	for _, message := range messages {
+		err := s.handler(message)
+		if err != nil {
+			s.report(err)
+			continue
+		}
+
+		// Acknowledged without the context so that handled messages are not redelivered on shutdown
+		_, err = s.client.XAck(s.options.Stream, s.options.Group, message.ID)
+		if err != nil {
+			s.report(err)
+		}
+	}
+}
+
+func (s *StreamConsumer) fail(client *Client, err error) error {
+	if client.Context().Err() != nil {
+		return client.Context().Err()
+	}
+
+	s.report(err)
+	return client.sleep(s.options.GetRetryBackoff())
+}
+
+func (s *StreamConsumer) report(err error) {
+	if s.options.ErrorHandler != nil {
+		s.options.ErrorHandler(err)
+	}
+}
+
+func streamMessages(streams []Stream) []StreamMessage {
+	var messages []StreamMessage
+	for _, stream := range streams {
+		messages = append(messages, stream.Messages...)
+	}
+	return messages
+}
@@ -0,0 +1,87 @@
+package xredis
+
+import (
+	"context"
+	"errors"
+	"github.com/garyburd/redigo/redis"
+	"github.com/rafaeljusto/redigomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestStreamConsumerOptions_Getters(t *testing.T) {
+	options := StreamConsumerOptions{}
+	assert.Equal(t, options.GetStartID(), defaultStreamConsumerStartID)
+	assert.Equal(t, options.GetCount(), int64(0))
+	assert.Equal(t, options.GetBlock(), time.Duration(0))
+	assert.Equal(t, options.GetMinIdle(), defaultStreamConsumerMinIdle)
+	assert.Equal(t, options.GetClaimInterval(), defaultStreamConsumerClaimInterval)
+	assert.Equal(t, options.GetRetryBackoff(), defaultStreamConsumerRetryBackoff)
+
+	options = StreamConsumerOptions{Count: -1, Block: -1, MinIdle: -1, ClaimInterval: -1, RetryBackoff: -1}
+	assert.Equal(t, options.GetCount(), int64(defaultStreamConsumerCount))
+	assert.Equal(t, options.GetBlock(), defaultStreamConsumerBlock)
+	assert.Equal(t, options.GetMinIdle(), defaultStreamConsumerMinIdle)
+	assert.Equal(t, options.GetClaimInterval(), defaultStreamConsumerClaimInterval)
+	assert.Equal(t, options.GetRetryBackoff(), defaultStreamConsumerRetryBackoff)
+
+	options = StreamConsumerOptions{StartID: "0", Count: 1, Block: 2, MinIdle: 3, ClaimInterval: 4, RetryBackoff: 5}
+	assert.Equal(t, options.GetStartID(), "0")
+	assert.Equal(t, options.GetCount(), int64(1))
+	assert.Equal(t, options.GetBlock(), time.Duration(2))
+	assert.Equal(t, options.GetMinIdle(), time.Duration(3))
+	assert.Equal(t, options.GetClaimInterval(), time.Duration(4))
+	assert.Equal(t, options.GetRetryBackoff(), time.Duration(5))
+}
+
+func TestStreamConsumer_Run(t *testing.T) {
+	stale := []interface{}{[]byte("1-0"), []interface{}{[]byte("a"), []byte("1")}}
+	pending := []interface{}{[]byte("2-0"), []interface{}{[]byte("a"), []byte("2")}}
+	fresh := []interface{}{[]byte("3-0"), []interface{}{[]byte("a"), []byte("3")}}
+
+	connection := redigomock.NewConn()
+	connection.Command("XGROUP", "CREATE", "stream", "group", "$", "MKSTREAM").ExpectError(redis.Error("BUSYGROUP Consumer Group name already exists"))
+	connection.Command("XAUTOCLAIM", "stream", "group", "consumer", int64(60000), "0-0", "COUNT", int64(10)).Expect([]interface{}{[]byte("0-0"), []interface{}{stale}, []interface{}{}})
+	connection.Command("XREADGROUP", "GROUP", "group", "consumer", "COUNT", int64(10), "STREAMS", "stream", "0").Expect([]interface{}{[]interface{}{[]byte("stream"), []interface{}{pending}}})
+	connection.Command("XREADGROUP", "GROUP", "group", "consumer", "COUNT", int64(10), "STREAMS", "stream", "2-0").Expect([]interface{}{[]interface{}{[]byte("stream"), []interface{}{}}})
+	connection.Command("XREADGROUP", "GROUP", "group", "consumer", "COUNT", int64(10), "BLOCK", int64(5000), "STREAMS", "stream", ">").Expect([]interface{}{[]interface{}{[]byte("stream"), []interface{}{fresh}}})
+	connection.Command("XACK", "stream", "group", "1-0").Expect(int64(1))
+	connection.Command("XACK", "stream", "group", "3-0").Expect(int64(1))
+
+	client := mockBlockingClient(connection)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var handled []string
+	var reported []error
+	options := &StreamConsumerOptions{
+		Stream:        "stream",
+		Group:         "group",
+		Consumer:      "consumer",
+		Count:         10,
+		Block:         5 * time.Second,
+		MinIdle:       time.Minute,
+		ClaimInterval: time.Hour,
+		ErrorHandler: func(err error) {
+			reported = append(reported, err)
+		},
+	}
+
+	consumer := client.NewStreamConsumer(options, func(message StreamMessage) error {
+		handled = append(handled, message.ID)
+		switch message.ID {
+		case "2-0":
+			return errors.New("Oops")
+		case "3-0":
+			cancel()
+		}
+		return nil
+	})
+
+	err := consumer.Run(ctx)
+	assert.Equal(t, err, context.Canceled)
+	assert.Equal(t, handled, []string{"1-0", "2-0", "3-0"})
+	assert.Equal(t, len(reported), 1)
+}
@@ -0,0 +1,670 @@
+package xredis
+
+import (
+	"errors"
+	"fmt"
+	"github.com/garyburd/redigo/redis"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	autoGenerateID    = "*"
+	noMkStreamOption  = "NOMKSTREAM"
+	maxLenOption      = "MAXLEN"
+	minIDOption       = "MINID"
+	approximateFlag   = "~"
+	countOption       = "COUNT"
+	blockOption       = "BLOCK"
+	streamsOption     = "STREAMS"
+	groupOption       = "GROUP"
+	noAckOption       = "NOACK"
+	mkStreamOption    = "MKSTREAM"
+	idleOption        = "IDLE"
+	createOption      = "CREATE"
+	destroyOption     = "DESTROY"
+	delConsumerOption = "DELCONSUMER"
+	streamOption      = "STREAM"
+	groupsOption      = "GROUPS"
+	consumersOption   = "CONSUMERS"
+
+	xAddCommand       = "XADD"
+	xLenCommand       = "XLEN"
+	xDelCommand       = "XDEL"
+	xRangeCommand     = "XRANGE"
+	xRevRangeCommand  = "XREVRANGE"
+	xReadCommand      = "XREAD"
+	xReadGroupCommand = "XREADGROUP"
+	xGroupCommand     = "XGROUP"
+	xAckCommand       = "XACK"
+	xPendingCommand   = "XPENDING"
+	xClaimCommand     = "XCLAIM"
+	xAutoClaimCommand = "XAUTOCLAIM"
+	xInfoCommand      = "XINFO"
+
+	streamIDsMismatchError     = "the number of streams and ids must match"
+	unexpectedStreamReplyError = "unexpected stream reply: %v"
+)
+
+// StreamMessage is a stream's entry
+type StreamMessage struct {
+	ID     string
+	Values map[string]string
+}
+
+// Stream is a stream's name and the messages read from it
+type Stream struct {
+	Name     string
+	Messages []StreamMessage
+}
+
+// XAddOptions contains XADD options.
+// MaxLen or MinID trim the stream, approximately if Approximate is set and up to Limit entries.
+// A MaxLen of zero applies only if HasMaxLen is set, which trims the stream to nothing
+type XAddOptions struct {
+	ID          string
+	NoMkStream  bool
+	MaxLen      int64
+	HasMaxLen   bool
+	MinID       string
+	Approximate bool
+	Limit       int64
+}
+
+// XReadOptions contains XREAD options.
+// If Block is set the read waits up to the Timeout for messages, a Timeout of zero blocks indefinitely
+type XReadOptions struct {
+	Streams []string
+	IDs     []string
+	Count   int64
+	Block   bool
+	Timeout time.Duration
+}
+
+// XReadGroupOptions contains XREADGROUP options
+type XReadGroupOptions struct {
+	Streams []string
+	IDs     []string
+	Count   int64
+	Block   bool
+	Timeout time.Duration
+	NoAck   bool
+}
+
+// XPendingSummary is the summary of a group's pending messages
+type XPendingSummary struct {
+	Count     int64
+	Lowest    string
+	Highest   string
+	Consumers map[string]int64
+}
+
+// XPendingOptions contains the options of the extended XPENDING form
+type XPendingOptions struct {
+	Start    string
+	End      string
+	Count    int64
+	Consumer string
+	Idle     time.Duration
+}
+
+// XPendingEntry is a pending message's details
+type XPendingEntry struct {
+	ID         string
+	Consumer   string
+	Idle       time.Duration
+	Deliveries int64
+}
+
+// XInfoStream contains a stream's information
+type XInfoStream struct {
+	Length          int64
+	RadixTreeKeys   int64
+	RadixTreeNodes  int64
+	Groups          int64
+	LastGeneratedID string
+	FirstEntry      *StreamMessage
+	LastEntry       *StreamMessage
+}
+
+// XInfoGroup contains a consumer group's information
+type XInfoGroup struct {
+	Name            string
+	Consumers       int64
+	Pending         int64
+	LastDeliveredID string
+}
+
+// XInfoConsumer contains a consumer's information
+type XInfoConsumer struct {
+	Name    string
+	Pending int64
+	Idle    time.Duration
+}
+
+// XAdd appends a message to a stream and returns its generated id
+func (c *Client) XAdd(stream string, values map[string]string) (string, error) {
+	id, _, err := c.XAddWithOptions(stream, XAddOptions{}, values)
+	return id, err
+}
+
+// XAddWithOptions appends a message to a stream using the options provided.
+// Returns the message's id and whether it was added
+func (c *Client) XAddWithOptions(stream string, options XAddOptions, values map[string]string) (string, bool, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	args := []interface{}{stream}
+	if options.NoMkStream {
+		args = append(args, noMkStreamOption)
+	}
+
+	if options.MaxLen > 0 || options.HasMaxLen {
+		args = append(args, trimArgs(maxLenOption, options.MaxLen, options)...)
+	} else if len(options.MinID) > 0 {
+		args = append(args, trimArgs(minIDOption, options.MinID, options)...)
+	}
+
+	id := options.ID
+	if len(id) == 0 {
+		id = autoGenerateID
+	}
+	args = append(args, id)
+
+	return toString(connection.Do(xAddCommand, append(args, fromStringMap(values)...)...))
+}
+
+// XLen returns the number of messages in a stream
+func (c *Client) XLen(stream string) (int64, error) {
+	connection := c.getReadConnection()
+	defer connection.Close()
+
+	return redis.Int64(connection.Do(xLenCommand, stream))
+}
+
+// XDel deletes messages from a stream
+func (c *Client) XDel(stream string, ids ...string) (int64, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return redis.Int64(connection.Do(xDelCommand, prepend(stream, ids)...))
+}
+
+// XRange retrieves the messages between the start and end ids, such as "-" and "+".
+// A count of zero retrieves all of them
+func (c *Client) XRange(stream string, start string, end string, count int64) ([]StreamMessage, error) {
+	connection := c.getReadConnection()
+	defer connection.Close()
+
+	return toStreamMessages(connection.Do(xRangeCommand, countArgs([]interface{}{stream, start, end}, count)...))
+}
+
+// XRevRange retrieves the messages between the end and start ids in reverse order.
+// A count of zero retrieves all of them
+func (c *Client) XRevRange(stream string, end string, start string, count int64) ([]StreamMessage, error) {
+	connection := c.getReadConnection()
+	defer connection.Close()
+
+	return toStreamMessages(connection.Do(xRevRangeCommand, countArgs([]interface{}{stream, end, start}, count)...))
+}
+
+// XRead reads messages from streams with an id greater than the ones provided, such as "0" or "$".
+// Returns no streams if the read timed out
+func (c *Client) XRead(options XReadOptions) ([]Stream, error) {
+	if len(options.Streams) != len(options.IDs) {
+		return nil, errors.New(streamIDsMismatchError)
+	}
+
+	connection := c.getReadConnection()
+	defer connection.Close()
+
+	args := countArgs([]interface{}{}, options.Count)
+	if options.Block {
+		args = append(args, blockOption, options.Timeout.Milliseconds())
+	}
+	args = append(args, streamsArgs(options.Streams, options.IDs)...)
+
+	if !options.Block {
+		return toStreams(connection.Do(xReadCommand, args...))
+	}
+	return toStreams(doBlocking(connection, options.Timeout, xReadCommand, args...))
+}
+
+// XGroupCreate creates a consumer group starting at the id provided, such as "0" or "$".
+// The stream is created if it does not exist and mkStream is set
+func (c *Client) XGroupCreate(stream string, group string, start string, mkStream bool) error {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	args := []interface{}{createOption, stream, group, start}
+	if mkStream {
+		args = append(args, mkStreamOption)
+	}
+	return toError(connection.Do(xGroupCommand, args...))
+}
+
+// XGroupDestroy destroys a consumer group
+func (c *Client) XGroupDestroy(stream string, group string) (bool, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return toPositive(connection.Do(xGroupCommand, destroyOption, stream, group))
+}
+
+// XGroupDelConsumer deletes a consumer from a group and returns the number of its pending messages
+func (c *Client) XGroupDelConsumer(stream string, group string, consumer string) (int64, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return redis.Int64(connection.Do(xGroupCommand, delConsumerOption, stream, group, consumer))
+}
+
+// XReadGroup reads messages from streams on behalf of a group's consumer.
+// Use the id ">" for new messages or "0" for the consumer's pending messages
+func (c *Client) XReadGroup(group string, consumer string, options XReadGroupOptions) ([]Stream, error) {
+	if len(options.Streams) != len(options.IDs) {
+		return nil, errors.New(streamIDsMismatchError)
+	}
+
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	args := countArgs([]interface{}{groupOption, group, consumer}, options.Count)
+	if options.Block {
+		args = append(args, blockOption, options.Timeout.Milliseconds())
+	}
+	if options.NoAck {
+		args = append(args, noAckOption)
+	}
+	args = append(args, streamsArgs(options.Streams, options.IDs)...)
+
+	if !options.Block {
+		return toStreams(connection.Do(xReadGroupCommand, args...))
+	}
+	return toStreams(doBlocking(connection, options.Timeout, xReadGroupCommand, args...))
+}
+
+// XAck acknowledges a group's messages
+func (c *Client) XAck(stream string, group string, ids ...string) (int64, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return redis.Int64(connection.Do(xAckCommand, append([]interface{}{stream, group}, toInterfaces(ids)...)...))
+}
+
+// XPending returns the summary of a group's pending messages
+func (c *Client) XPending(stream string, group string) (*XPendingSummary, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	values, err := redis.Values(connection.Do(xPendingCommand, stream, group))
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != 4 {
+		return nil, fmt.Errorf(unexpectedStreamReplyError, values)
+	}
+
+	count, err := redis.Int64(values[0], nil)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &XPendingSummary{Count: count, Consumers: map[string]int64{}}
+	summary.Lowest, _, _ = toString(values[1], nil)
+	summary.Highest, _, _ = toString(values[2], nil)
+
+	consumers, err := redis.Values(values[3], nil)
+	if err == redis.ErrNil {
+		return summary, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, consumer := range consumers {
+		pair, err := redis.Strings(consumer, nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(pair) != 2 {
+			return nil, fmt.Errorf(unexpectedStreamReplyError, pair)
+		}
+
+		pending, err := strconv.ParseInt(pair[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		summary.Consumers[pair[0]] = pending
+	}
+	return summary, nil
+}
+
+// XPendingExt returns the details of a group's pending messages
+func (c *Client) XPendingExt(stream string, group string, options XPendingOptions) ([]XPendingEntry, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	args := []interface{}{stream, group}
+	if options.Idle > 0 {
+		args = append(args, idleOption, options.Idle.Milliseconds())
+	}
+	args = append(args, defaultString(options.Start, "-"), defaultString(options.End, "+"), options.Count)
+	if len(options.Consumer) > 0 {
+		args = append(args, options.Consumer)
+	}
+
+	values, err := redis.Values(connection.Do(xPendingCommand, args...))
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]XPendingEntry, len(values))
+	for i, value := range values {
+		fields, err := redis.Values(value, nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(fields) != 4 {
+			return nil, fmt.Errorf(unexpectedStreamReplyError, fields)
+		}
+
+		id, err := redis.String(fields[0], nil)
+		if err != nil {
+			return nil, err
+		}
+
+		consumer, err := redis.String(fields[1], nil)
+		if err != nil {
+			return nil, err
+		}
+
+		idle, err := redis.Int64(fields[2], nil)
+		if err != nil {
+			return nil, err
+		}
+
+		deliveries, err := redis.Int64(fields[3], nil)
+		if err != nil {
+			return nil, err
+		}
+
+		entries[i] = XPendingEntry{
+			ID:         id,
+			Consumer:   consumer,
+			Idle:       time.Duration(idle) * time.Millisecond,
+			Deliveries: deliveries,
+		}
+	}
+	return entries, nil
+}
+
+// XClaim changes the ownership of pending messages idle for at least minIdle to the consumer
+func (c *Client) XClaim(stream string, group string, consumer string, minIdle time.Duration, ids ...string) ([]StreamMessage, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	args := append([]interface{}{stream, group, consumer, minIdle.Milliseconds()}, toInterfaces(ids)...)
+	return toStreamMessages(connection.Do(xClaimCommand, args...))
+}
+
+// XAutoClaim changes the ownership of up to count pending messages idle for at least minIdle to the consumer,
+// scanning from the start id. Returns the id to continue scanning from and the claimed messages
+func (c *Client) XAutoClaim(stream string, group string, consumer string, minIdle time.Duration, start string, count int64) (string, []StreamMessage, error) {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	args := countArgs([]interface{}{stream, group, consumer, minIdle.Milliseconds(), start}, count)
+	values, err := redis.Values(connection.Do(xAutoClaimCommand, args...))
+	if err != nil {
+		return "", nil, err
+	}
+	if len(values) < 2 {
+		return "", nil, fmt.Errorf(unexpectedStreamReplyError, values)
+	}
+
+	next, err := redis.String(values[0], nil)
+	if err != nil {
+		return "", nil, err
+	}
+
+	messages, err := toStreamMessages(values[1], nil)
+	if err != nil {
+		return "", nil, err
+	}
+	return next, messages, nil
+}
+
+// XInfoStream returns a stream's information
+func (c *Client) XInfoStream(stream string) (*XInfoStream, error) {
+	connection := c.getReadConnection()
+	defer connection.Close()
+
+	fields, err := toFieldMap(connection.Do(xInfoCommand, streamOption, stream))
+	if err != nil {
+		return nil, err
+	}
+
+	info := &XInfoStream{}
+	info.Length, _ = redis.Int64(fields["length"], nil)
+	info.RadixTreeKeys, _ = redis.Int64(fields["radix-tree-keys"], nil)
+	info.RadixTreeNodes, _ = redis.Int64(fields["radix-tree-nodes"], nil)
+	info.Groups, _ = redis.Int64(fields["groups"], nil)
+	info.LastGeneratedID, _ = redis.String(fields["last-generated-id"], nil)
+
+	info.FirstEntry, err = toOptionalStreamMessage(fields["first-entry"])
+	if err != nil {
+		return nil, err
+	}
+
+	info.LastEntry, err = toOptionalStreamMessage(fields["last-entry"])
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// XInfoGroups returns the information of a stream's consumer groups
+func (c *Client) XInfoGroups(stream string) ([]XInfoGroup, error) {
+	connection := c.getReadConnection()
+	defer connection.Close()
+
+	values, err := redis.Values(connection.Do(xInfoCommand, groupsOption, stream))
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]XInfoGroup, len(values))
+	for i, value := range values {
+		fields, err := toFieldMap(value, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		groups[i].Name, _ = redis.String(fields["name"], nil)
+		groups[i].Consumers, _ = redis.Int64(fields["consumers"], nil)
+		groups[i].Pending, _ = redis.Int64(fields["pending"], nil)
+		groups[i].LastDeliveredID, _ = redis.String(fields["last-delivered-id"], nil)
+	}
+	return groups, nil
+}
+
+// XInfoConsumers returns the information of a group's consumers
+func (c *Client) XInfoConsumers(stream string, group string) ([]XInfoConsumer, error) {
+	connection := c.getReadConnection()
+	defer connection.Close()
+
+	values, err := redis.Values(connection.Do(xInfoCommand, consumersOption, stream, group))
+	if err != nil {
+		return nil, err
+	}
+
+	consumers := make([]XInfoConsumer, len(values))
+	for i, value := range values {
+		fields, err := toFieldMap(value, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		idle, _ := redis.Int64(fields["idle"], nil)
+		consumers[i].Name, _ = redis.String(fields["name"], nil)
+		consumers[i].Pending, _ = redis.Int64(fields["pending"], nil)
+		consumers[i].Idle = time.Duration(idle) * time.Millisecond
+	}
+	return consumers, nil
+}
+
+func trimArgs(strategy string, threshold interface{}, options XAddOptions) []interface{} {
+	args := []interface{}{strategy}
+	if options.Approximate {
+		args = append(args, approximateFlag)
+	}
+	args = append(args, threshold)
+	if options.Approximate && options.Limit > 0 {
+		args = append(args, limitOption, options.Limit)
+	}
+	return args
+}
+
+func countArgs(args []interface{}, count int64) []interface{} {
+	if count > 0 {
+		args = append(args, countOption, count)
+	}
+	return args
+}
+
+func streamsArgs(streams []string, ids []string) []interface{} {
+	args := []interface{}{streamsOption}
+	args = append(args, toInterfaces(streams)...)
+	return append(args, toInterfaces(ids)...)
+}
+
+func defaultString(value string, defaultValue string) string {
+	if len(value) == 0 {
+		return defaultValue
+	}
+	return value
+}
+
+func fromStringMap(values map[string]string) []interface{} {
+	fields := make([]string, 0, len(values))
+	for field := range values {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	args := make([]interface{}, 0, len(values)*2)
+	for _, field := range fields {
+		args = append(args, field, values[field])
+	}
+	return args
+}
+
+func toStreams(reply interface{}, err error) ([]Stream, error) {
+	values, err := redis.Values(reply, err)
+	if err == redis.ErrNil {
+		return []Stream{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	streams := make([]Stream, len(values))
+	for i, value := range values {
+		pair, err := redis.Values(value, nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(pair) != 2 {
+			return nil, fmt.Errorf(unexpectedStreamReplyError, pair)
+		}
+
+		name, err := redis.String(pair[0], nil)
+		if err != nil {
+			return nil, err
+		}
+
+		messages, err := toStreamMessages(pair[1], nil)
+		if err != nil {
+			return nil, err
+		}
+		streams[i] = Stream{Name: name, Messages: messages}
+	}
+	return streams, nil
+}
+
+func toStreamMessages(reply interface{}, err error) ([]StreamMessage, error) {
+	values, err := redis.Values(reply, err)
+	if err == redis.ErrNil {
+		return []StreamMessage{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]StreamMessage, 0, len(values))
+	for _, value := range values {
+		if value == nil {
+			continue
+		}
+
+		message, err := toStreamMessage(value)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, *message)
+	}
+	return messages, nil
+}
+
+func toOptionalStreamMessage(reply interface{}) (*StreamMessage, error) {
+	if reply == nil {
+		return nil, nil
+	}
+	return toStreamMessage(reply)
+}
+
+func toStreamMessage(reply interface{}) (*StreamMessage, error) {
+	pair, err := redis.Values(reply, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(pair) != 2 {
+		return nil, fmt.Errorf(unexpectedStreamReplyError, pair)
+	}
+
+	id, err := redis.String(pair[0], nil)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := redis.StringMap(pair[1], nil)
+	if err == redis.ErrNil {
+		return &StreamMessage{ID: id, Values: map[string]string{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &StreamMessage{ID: id, Values: values}, nil
+}
+
+func toFieldMap(reply interface{}, err error) (map[string]interface{}, error) {
+	values, err := redis.Values(reply, err)
+	if err != nil {
+		return nil, err
+	}
+	if len(values)%2 != 0 {
+		return nil, errors.New(oddRepliesError)
+	}
+
+	fields := make(map[string]interface{}, len(values)/2)
+	for i := 0; i < len(values); i += 2 {
+		name, err := redis.String(values[i], nil)
+		if err != nil {
+			return nil, err
+		}
+		fields[name] = values[i+1]
+	}
+	return fields, nil
+}
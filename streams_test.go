@@ -0,0 +1,217 @@
+package xredis
+
+import (
+	"github.com/rafaeljusto/redigomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestClient_XAdd(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("XADD", "stream", "*", "a", "1", "b", "2").Expect([]byte("1-0"))
+	connection.Command("XADD", "stream", "NOMKSTREAM", "MAXLEN", "~", int64(100), "LIMIT", int64(10), "*", "a", "1").Expect(nil)
+	connection.Command("XADD", "stream", "MINID", "1-0", "2-0", "a", "1").Expect([]byte("2-0"))
+	connection.Command("XADD", "stream", "MAXLEN", int64(0), "*", "a", "1").Expect([]byte("3-0"))
+
+	client := mockClient(connection)
+
+	id, err := client.XAdd("stream", map[string]string{"b": "2", "a": "1"})
+	assert.Equal(t, id, "1-0")
+	assert.Nil(t, err)
+
+	id, ok, err := client.XAddWithOptions("stream", XAddOptions{NoMkStream: true, MaxLen: 100, Approximate: true, Limit: 10}, map[string]string{"a": "1"})
+	assert.Equal(t, id, "")
+	assert.False(t, ok)
+	assert.Nil(t, err)
+
+	id, ok, err = client.XAddWithOptions("stream", XAddOptions{ID: "2-0", MinID: "1-0"}, map[string]string{"a": "1"})
+	assert.Equal(t, id, "2-0")
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	id, ok, err = client.XAddWithOptions("stream", XAddOptions{HasMaxLen: true}, map[string]string{"a": "1"})
+	assert.Equal(t, id, "3-0")
+	assert.True(t, ok)
+	assert.Nil(t, err)
+}
+
+func TestClient_XRange(t *testing.T) {
+	message := []interface{}{[]byte("1-0"), []interface{}{[]byte("a"), []byte("1")}}
+
+	connection := redigomock.NewConn()
+	connection.Command("XRANGE", "stream", "-", "+").Expect([]interface{}{message})
+	connection.Command("XREVRANGE", "stream", "+", "-", "COUNT", int64(1)).Expect([]interface{}{message})
+	connection.Command("XLEN", "stream").Expect(int64(1))
+	connection.Command("XDEL", "stream", "1-0").Expect(int64(1))
+
+	client := mockClient(connection)
+
+	messages, err := client.XRange("stream", "-", "+", 0)
+	assert.Equal(t, messages, []StreamMessage{{ID: "1-0", Values: map[string]string{"a": "1"}}})
+	assert.Nil(t, err)
+
+	messages, err = client.XRevRange("stream", "+", "-", 1)
+	assert.Equal(t, messages, []StreamMessage{{ID: "1-0", Values: map[string]string{"a": "1"}}})
+	assert.Nil(t, err)
+
+	length, err := client.XLen("stream")
+	assert.Equal(t, length, int64(1))
+	assert.Nil(t, err)
+
+	count, err := client.XDel("stream", "1-0")
+	assert.Equal(t, count, int64(1))
+	assert.Nil(t, err)
+}
+
+func TestClient_XRead(t *testing.T) {
+	message := []interface{}{[]byte("1-0"), []interface{}{[]byte("a"), []byte("1")}}
+
+	connection := redigomock.NewConn()
+	connection.Command("XREAD", "COUNT", int64(10), "STREAMS", "stream", "0").Expect([]interface{}{[]interface{}{[]byte("stream"), []interface{}{message}}})
+	connection.Command("XREAD", "BLOCK", int64(100), "STREAMS", "stream", "$").Expect(nil)
+
+	client := mockBlockingClient(connection)
+
+	streams, err := client.XRead(XReadOptions{Streams: []string{"stream"}, IDs: []string{"0"}, Count: 10})
+	assert.Equal(t, streams, []Stream{{Name: "stream", Messages: []StreamMessage{{ID: "1-0", Values: map[string]string{"a": "1"}}}}})
+	assert.Nil(t, err)
+
+	streams, err = client.XRead(XReadOptions{Streams: []string{"stream"}, IDs: []string{"$"}, Block: true, Timeout: 100 * time.Millisecond})
+	assert.Equal(t, streams, []Stream{})
+	assert.Nil(t, err)
+
+	streams, err = client.XRead(XReadOptions{Streams: []string{"stream"}})
+	assert.Nil(t, streams)
+	assert.NotNil(t, err)
+}
+
+func TestClient_XGroup(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("XGROUP", "CREATE", "stream", "group", "$", "MKSTREAM").Expect("OK")
+	connection.Command("XGROUP", "DESTROY", "stream", "group").Expect(int64(1))
+	connection.Command("XGROUP", "DELCONSUMER", "stream", "group", "consumer").Expect(int64(2))
+
+	client := mockClient(connection)
+
+	assert.Nil(t, client.XGroupCreate("stream", "group", "$", true))
+
+	ok, err := client.XGroupDestroy("stream", "group")
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	count, err := client.XGroupDelConsumer("stream", "group", "consumer")
+	assert.Equal(t, count, int64(2))
+	assert.Nil(t, err)
+}
+
+func TestClient_XReadGroup(t *testing.T) {
+	message := []interface{}{[]byte("1-0"), []interface{}{[]byte("a"), []byte("1")}}
+	deleted := []interface{}{[]byte("0-1"), nil}
+
+	connection := redigomock.NewConn()
+	connection.Command("XREADGROUP", "GROUP", "group", "consumer", "COUNT", int64(1), "BLOCK", int64(1000), "NOACK", "STREAMS", "stream", ">").Expect([]interface{}{[]interface{}{[]byte("stream"), []interface{}{message}}})
+	connection.Command("XREADGROUP", "GROUP", "group", "consumer", "STREAMS", "stream", "0").Expect([]interface{}{[]interface{}{[]byte("stream"), []interface{}{deleted}}})
+	connection.Command("XACK", "stream", "group", "1-0").Expect(int64(1))
+
+	client := mockBlockingClient(connection)
+
+	streams, err := client.XReadGroup("group", "consumer", XReadGroupOptions{Streams: []string{"stream"}, IDs: []string{">"}, Count: 1, Block: true, Timeout: time.Second, NoAck: true})
+	assert.Equal(t, streams, []Stream{{Name: "stream", Messages: []StreamMessage{{ID: "1-0", Values: map[string]string{"a": "1"}}}}})
+	assert.Nil(t, err)
+
+	streams, err = client.XReadGroup("group", "consumer", XReadGroupOptions{Streams: []string{"stream"}, IDs: []string{"0"}})
+	assert.Equal(t, streams, []Stream{{Name: "stream", Messages: []StreamMessage{{ID: "0-1", Values: map[string]string{}}}}})
+	assert.Nil(t, err)
+
+	count, err := client.XAck("stream", "group", "1-0")
+	assert.Equal(t, count, int64(1))
+	assert.Nil(t, err)
+}
+
+func TestClient_XPending(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("XPENDING", "stream", "group").Expect([]interface{}{int64(2), []byte("1-0"), []byte("2-0"), []interface{}{[]interface{}{[]byte("consumer"), []byte("2")}}})
+	connection.Command("XPENDING", "empty", "group").Expect([]interface{}{int64(0), nil, nil, nil})
+	connection.Command("XPENDING", "stream", "group", "IDLE", int64(60000), "-", "+", int64(10), "consumer").Expect([]interface{}{[]interface{}{[]byte("1-0"), []byte("consumer"), int64(61000), int64(3)}})
+
+	client := mockClient(connection)
+
+	summary, err := client.XPending("stream", "group")
+	assert.Equal(t, summary, &XPendingSummary{Count: 2, Lowest: "1-0", Highest: "2-0", Consumers: map[string]int64{"consumer": 2}})
+	assert.Nil(t, err)
+
+	summary, err = client.XPending("empty", "group")
+	assert.Equal(t, summary, &XPendingSummary{Consumers: map[string]int64{}})
+	assert.Nil(t, err)
+
+	entries, err := client.XPendingExt("stream", "group", XPendingOptions{Count: 10, Consumer: "consumer", Idle: time.Minute})
+	assert.Equal(t, entries, []XPendingEntry{{ID: "1-0", Consumer: "consumer", Idle: 61 * time.Second, Deliveries: 3}})
+	assert.Nil(t, err)
+}
+
+func TestClient_XClaim(t *testing.T) {
+	message := []interface{}{[]byte("1-0"), []interface{}{[]byte("a"), []byte("1")}}
+
+	connection := redigomock.NewConn()
+	connection.Command("XCLAIM", "stream", "group", "consumer", int64(60000), "1-0").Expect([]interface{}{message})
+	connection.Command("XAUTOCLAIM", "stream", "group", "consumer", int64(60000), "0-0", "COUNT", int64(10)).Expect([]interface{}{[]byte("0-0"), []interface{}{message}, []interface{}{}})
+
+	client := mockClient(connection)
+
+	messages, err := client.XClaim("stream", "group", "consumer", time.Minute, "1-0")
+	assert.Equal(t, messages, []StreamMessage{{ID: "1-0", Values: map[string]string{"a": "1"}}})
+	assert.Nil(t, err)
+
+	next, messages, err := client.XAutoClaim("stream", "group", "consumer", time.Minute, "0-0", 10)
+	assert.Equal(t, next, "0-0")
+	assert.Equal(t, messages, []StreamMessage{{ID: "1-0", Values: map[string]string{"a": "1"}}})
+	assert.Nil(t, err)
+}
+
+func TestClient_XInfo(t *testing.T) {
+	message := []interface{}{[]byte("1-0"), []interface{}{[]byte("a"), []byte("1")}}
+
+	connection := redigomock.NewConn()
+	connection.Command("XINFO", "STREAM", "stream").Expect([]interface{}{
+		[]byte("length"), int64(1),
+		[]byte("radix-tree-keys"), int64(2),
+		[]byte("radix-tree-nodes"), int64(3),
+		[]byte("groups"), int64(1),
+		[]byte("last-generated-id"), []byte("1-0"),
+		[]byte("first-entry"), message,
+		[]byte("last-entry"), nil,
+	})
+	connection.Command("XINFO", "GROUPS", "stream").Expect([]interface{}{[]interface{}{
+		[]byte("name"), []byte("group"),
+		[]byte("consumers"), int64(1),
+		[]byte("pending"), int64(2),
+		[]byte("last-delivered-id"), []byte("1-0"),
+	}})
+	connection.Command("XINFO", "CONSUMERS", "stream", "group").Expect([]interface{}{[]interface{}{
+		[]byte("name"), []byte("consumer"),
+		[]byte("pending"), int64(2),
+		[]byte("idle"), int64(1500),
+	}})
+
+	client := mockClient(connection)
+
+	info, err := client.XInfoStream("stream")
+	assert.Equal(t, info, &XInfoStream{
+		Length:          1,
+		RadixTreeKeys:   2,
+		RadixTreeNodes:  3,
+		Groups:          1,
+		LastGeneratedID: "1-0",
+		FirstEntry:      &StreamMessage{ID: "1-0", Values: map[string]string{"a": "1"}},
+	})
+	assert.Nil(t, err)
+
+	groups, err := client.XInfoGroups("stream")
+	assert.Equal(t, groups, []XInfoGroup{{Name: "group", Consumers: 1, Pending: 2, LastDeliveredID: "1-0"}})
+	assert.Nil(t, err)
+
+	consumers, err := client.XInfoConsumers("stream", "group")
+	assert.Equal(t, consumers, []XInfoConsumer{{Name: "consumer", Pending: 2, Idle: 1500 * time.Millisecond}})
+	assert.Nil(t, err)
+}
@@ -0,0 +1,308 @@
+package xredis
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/garyburd/redigo/redis"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	hMGetCommand = "HMGET"
+
+	structTag       = "redis"
+	omitEmptyOption = "omitempty"
+	skipTag         = "-"
+
+	invalidStructError = "value must be a non-nil pointer to a struct"
+	unknownFieldError  = "unknown struct field: "
+)
+
+var (
+	timeType  = reflect.TypeOf(time.Time{})
+	bytesType = reflect.TypeOf([]byte(nil))
+
+	structFieldsCache sync.Map
+)
+
+type structField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+// HSetStruct sets the hash's fields to the struct's exported fields in one round trip and returns the number of fields added.
+// A field is named by its `redis:"name"` tag or else by its name, `redis:"-"` skips it and `redis:"name,omitempty"` skips its zero value.
+// Strings, numbers, bools, byte slices and time.Time are stored as text, other values as JSON
+func (c *Client) HSetStruct(key string, value interface{}) (int64, error) {
+	structValue := reflect.Indirect(reflect.ValueOf(value))
+	if structValue.Kind() != reflect.Struct {
+		return 0, errors.New(invalidStructError)
+	}
+
+	args := []interface{}{key}
+	for _, field := range fieldsOf(structValue.Type()) {
+		fieldValue, ok := fieldByIndex(structValue, field.index)
+		if !ok || (field.omitEmpty && fieldValue.IsZero()) {
+			continue
+		}
+
+		encoded, ok, err := encodeField(fieldValue)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			args = append(args, field.name, encoded)
+		}
+	}
+
+	if len(args) == 1 {
+		return 0, nil
+	}
+
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	return redis.Int64(connection.Do(hSetCommand, args...))
+}
+
+// HGetStruct loads the hash's fields into the struct pointed to by the value and returns whether any field exists.
+// If field names are provided, only those are loaded using HMGET
+func (c *Client) HGetStruct(key string, value interface{}, fields ...string) (bool, error) {
+	pointer := reflect.ValueOf(value)
+	if pointer.Kind() != reflect.Ptr || pointer.IsNil() || pointer.Elem().Kind() != reflect.Struct {
+		return false, errors.New(invalidStructError)
+	}
+
+	structValue := pointer.Elem()
+	structFields := map[string]structField{}
+	for _, field := range fieldsOf(structValue.Type()) {
+		structFields[field.name] = field
+	}
+
+	for _, name := range fields {
+		if _, ok := structFields[name]; !ok {
+			return false, errors.New(unknownFieldError + name)
+		}
+	}
+
+	values, err := c.hashValues(key, fields)
+	if err != nil {
+		return false, err
+	}
+
+	for name, data := range values {
+		field, ok := structFields[name]
+		if !ok {
+			continue
+		}
+
+		fieldValue, ok := fieldByIndexAlloc(structValue, field.index)
+		if !ok {
+			continue
+		}
+
+		err = decodeField(fieldValue, data)
+		if err != nil {
+			return false, err
+		}
+	}
+	return len(values) > 0, nil
+}
+
+// hashValues retrieves all the hash's fields or only the provided ones, skipping those that do not exist
+func (c *Client) hashValues(key string, fields []string) (map[string][]byte, error) {
+	if len(fields) == 0 {
+		return c.HGetAllBytes(key)
+	}
+
+	connection := c.getReadConnection()
+	defer connection.Close()
+
+	values, err := redis.ByteSlices(connection.Do(hMGetCommand, prepend(key, fields)...))
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[string][]byte, len(values))
+	for i, value := range values {
+		if value != nil && i < len(fields) {
+			results[fields[i]] = value
+		}
+	}
+	return results, nil
+}
+
+func fieldsOf(structType reflect.Type) []structField {
+	if fields, ok := structFieldsCache.Load(structType); ok {
+		return fields.([]structField)
+	}
+
+	fields := collectFields(structType, nil, map[reflect.Type]bool{})
+	structFieldsCache.Store(structType, fields)
+	return fields
+}
+
+// collectFields skips embedded structs already being collected, so a struct embedding a pointer to its own type ends
+func collectFields(structType reflect.Type, index []int, visited map[reflect.Type]bool) []structField {
+	visited[structType] = true
+	defer delete(visited, structType)
+
+	var fields []structField
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		tag := field.Tag.Get(structTag)
+		if tag == skipTag {
+			continue
+		}
+
+		fieldIndex := append(append([]int{}, index...), i)
+
+		// Untagged embedded structs are flattened like encoding/json does
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && len(tag) == 0 && fieldType.Kind() == reflect.Struct {
+			if !visited[fieldType] {
+				fields = append(fields, collectFields(fieldType, fieldIndex, visited)...)
+			}
+			continue
+		}
+
+		if len(field.PkgPath) > 0 {
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+		if len(name) == 0 {
+			name = field.Name
+		}
+		fields = append(fields, structField{name: name, index: fieldIndex, omitEmpty: options == omitEmptyOption})
+	}
+	return fields
+}
+
+// fieldByIndex returns the nested field, which is not available if an embedded pointer on the way is nil
+func fieldByIndex(value reflect.Value, index []int) (reflect.Value, bool) {
+	for i, position := range index {
+		if i > 0 && value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				return reflect.Value{}, false
+			}
+			value = value.Elem()
+		}
+		value = value.Field(position)
+	}
+	return value, true
+}
+
+// fieldByIndexAlloc returns the nested field, allocating the embedded pointers on the way. The field is not
+// available if a nil embedded pointer on the way cannot be set, such as a pointer to an unexported struct
+func fieldByIndexAlloc(value reflect.Value, index []int) (reflect.Value, bool) {
+	for i, position := range index {
+		if i > 0 && value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				if !value.CanSet() {
+					return reflect.Value{}, false
+				}
+				value.Set(reflect.New(value.Type().Elem()))
+			}
+			value = value.Elem()
+		}
+		value = value.Field(position)
+	}
+	return value, value.CanSet()
+}
+
+func encodeField(value reflect.Value) (interface{}, bool, error) {
+	if value.Type() == timeType {
+		return value.Interface().(time.Time).Format(time.RFC3339Nano), true, nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		return value.String(), true, nil
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool()), true, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10), true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10), true, nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'g', -1, value.Type().Bits()), true, nil
+	case reflect.Ptr:
+		if value.IsNil() {
+			return nil, false, nil
+		}
+		return encodeField(value.Elem())
+	}
+
+	if value.Type() == bytesType {
+		return value.Bytes(), true, nil
+	}
+
+	data, err := json.Marshal(value.Interface())
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+func decodeField(value reflect.Value, data []byte) error {
+	if value.Type() == timeType {
+		parsed, err := time.Parse(time.RFC3339Nano, string(data))
+		if err != nil {
+			return err
+		}
+		value.Set(reflect.ValueOf(parsed))
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(string(data))
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(string(data))
+		if err != nil {
+			return err
+		}
+		value.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(string(data), 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(string(data), 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(string(data), value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetFloat(parsed)
+	case reflect.Ptr:
+		element := reflect.New(value.Type().Elem())
+		err := decodeField(element.Elem(), data)
+		if err != nil {
+			return err
+		}
+		value.Set(element)
+	default:
+		if value.Type() == bytesType {
+			value.SetBytes(append([]byte{}, data...))
+			return nil
+		}
+		return json.Unmarshal(data, value.Addr().Interface())
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package xredis
+
+import (
+	"errors"
+	"github.com/rafaeljusto/redigomock"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testAudit struct {
+	CreatedAt time.Time `redis:"created_at"`
+}
+
+type testAccount struct {
+	testAudit
+	Name     string            `redis:"name"`
+	Age      int               `redis:"age"`
+	Balance  float64           `redis:"balance"`
+	Active   bool              `redis:"active"`
+	Visits   uint32            `redis:"visits,omitempty"`
+	Nickname *string           `redis:"nickname"`
+	Tags     []string          `redis:"tags"`
+	Settings map[string]string `redis:"settings"`
+	Avatar   []byte            `redis:"avatar"`
+	Secret   string            `redis:"-"`
+	Untagged string
+	private  string
+}
+
+func TestClient_HSetStruct(t *testing.T) {
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	account := testAccount{
+		testAudit: testAudit{CreatedAt: createdAt},
+		Name:      "Raed",
+		Age:       30,
+		Balance:   10.5,
+		Active:    true,
+		Tags:      []string{"a", "b"},
+		Settings:  map[string]string{"theme": "dark"},
+		Avatar:    []byte{0x01},
+		Secret:    "secret",
+		Untagged:  "value",
+		private:   "private",
+	}
+
+	connection := redigomock.NewConn()
+	connection.Command("HSET", "account",
+		"created_at", "2024-01-02T03:04:05.000000006Z",
+		"name", "Raed",
+		"age", "30",
+		"balance", "10.5",
+		"active", "true",
+		"tags", []byte(`["a","b"]`),
+		"settings", []byte(`{"theme":"dark"}`),
+		"avatar", []byte{0x01},
+		"Untagged", "value",
+	).Expect(int64(9))
+
+	client := mockClient(connection)
+
+	count, err := client.HSetStruct("account", &account)
+	assert.Equal(t, count, int64(9))
+	assert.Nil(t, err)
+
+	count, err = client.HSetStruct("account", "invalid")
+	assert.Equal(t, count, int64(0))
+	assert.Equal(t, err, errors.New(invalidStructError))
+
+	count, err = client.HSetStruct("account", struct {
+		Visits int `redis:"visits,omitempty"`
+	}{})
+	assert.Equal(t, count, int64(0))
+	assert.Nil(t, err)
+}
+
+func TestClient_HGetStruct(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("HGETALL", "account").Expect([]interface{}{
+		[]byte("created_at"), []byte("2024-01-02T03:04:05.000000006Z"),
+		[]byte("name"), []byte("Raed"),
+		[]byte("age"), []byte("30"),
+		[]byte("balance"), []byte("10.5"),
+		[]byte("active"), []byte("true"),
+		[]byte("visits"), []byte("7"),
+		[]byte("nickname"), []byte("rae"),
+		[]byte("tags"), []byte(`["a","b"]`),
+		[]byte("settings"), []byte(`{"theme":"dark"}`),
+		[]byte("avatar"), []byte{0x01},
+		[]byte("unknown"), []byte("ignored"),
+	})
+
+	client := mockClient(connection)
+
+	var account testAccount
+	found, err := client.HGetStruct("account", &account)
+	assert.True(t, found)
+	assert.Nil(t, err)
+
+	nickname := "rae"
+	assert.Equal(t, account, testAccount{
+		testAudit: testAudit{CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)},
+		Name:      "Raed",
+		Age:       30,
+		Balance:   10.5,
+		Active:    true,
+		Visits:    7,
+		Nickname:  &nickname,
+		Tags:      []string{"a", "b"},
+		Settings:  map[string]string{"theme": "dark"},
+		Avatar:    []byte{0x01},
+	})
+}
+
+type testProfile struct {
+	*testAudit
+	Name string `redis:"name"`
+}
+
+func TestClient_HGetStruct_UnexportedEmbeddedPointer(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("HGETALL", "profile").Expect([]interface{}{
+		[]byte("created_at"), []byte("2024-01-02T03:04:05Z"),
+		[]byte("name"), []byte("Raed"),
+	})
+
+	client := mockClient(connection)
+
+	var profile testProfile
+	found, err := client.HGetStruct("profile", &profile)
+	assert.True(t, found)
+	assert.Nil(t, err)
+	assert.Equal(t, profile, testProfile{Name: "Raed"})
+
+	profile = testProfile{testAudit: &testAudit{}}
+	found, err = client.HGetStruct("profile", &profile)
+	assert.True(t, found)
+	assert.Nil(t, err)
+	assert.Equal(t, profile.CreatedAt, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+}
+
+func TestClient_HGetStruct_Fields(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("HMGET", "account", "name", "age").Expect([]interface{}{[]byte("Raed"), nil})
+
+	client := mockClient(connection)
+
+	account := testAccount{Age: 1}
+	found, err := client.HGetStruct("account", &account, "name", "age")
+	assert.True(t, found)
+	assert.Nil(t, err)
+	assert.Equal(t, account, testAccount{Name: "Raed", Age: 1})
+
+	found, err = client.HGetStruct("account", &account, "missing")
+	assert.False(t, found)
+	assert.Equal(t, err, errors.New(unknownFieldError+"missing"))
+}
+
+type testNode struct {
+	*testNode
+	Name string `redis:"name"`
+}
+
+func TestClient_HSetStruct_SelfEmbedded(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("HSET", "node", "name", "root").Expect(int64(1))
+
+	client := mockClient(connection)
+
+	count, err := client.HSetStruct("node", testNode{testNode: &testNode{Name: "child"}, Name: "root"})
+	assert.Equal(t, count, int64(1))
+	assert.Nil(t, err)
+	assert.Equal(t, fieldsOf(reflect.TypeOf(testNode{})), []structField{{name: "name", index: []int{1}}})
+}
+
+func TestClient_HGetStruct_Errors(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("HGETALL", "empty").Expect([]interface{}{})
+	connection.Command("HGETALL", "invalid").Expect([]interface{}{[]byte("age"), []byte("abc")})
+
+	client := mockClient(connection)
+
+	var account testAccount
+	found, err := client.HGetStruct("empty", &account)
+	assert.False(t, found)
+	assert.Nil(t, err)
+
+	found, err = client.HGetStruct("invalid", &account)
+	assert.False(t, found)
+	assert.NotNil(t, err)
+
+	found, err = client.HGetStruct("account", account)
+	assert.False(t, found)
+	assert.Equal(t, err, errors.New(invalidStructError))
+
+	var nilAccount *testAccount
+	found, err = client.HGetStruct("account", nilAccount)
+	assert.False(t, found)
+	assert.Equal(t, err, errors.New(invalidStructError))
+}
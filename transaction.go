@@ -0,0 +1,185 @@
+package xredis
+
+import (
+	"errors"
+	"github.com/garyburd/redigo/redis"
+	"time"
+)
+
+const (
+	multiCommand   = "MULTI"
+	execCommand    = "EXEC"
+	watchCommand   = "WATCH"
+	unwatchCommand = "UNWATCH"
+
+	transactionAbortedError = "transaction aborted"
+
+	defaultRetryMaxAttempts = 3
+	defaultRetryBackoff     = 10 * time.Millisecond
+)
+
+// ErrTransactionAborted is returned when a transaction was aborted on every attempt because a watched key changed
+var ErrTransactionAborted = errors.New(transactionAbortedError)
+
+// RetryPolicy determines how aborted transactions are retried.
+// A transaction is always attempted once, so a MaxAttempts of zero or one disables retries
+type RetryPolicy struct {
+	MaxAttempts int
+	Backoff     time.Duration
+}
+
+// GetMaxAttempts returns max attempts
+func (p *RetryPolicy) GetMaxAttempts() int {
+	if p.MaxAttempts < 0 {
+		return defaultRetryMaxAttempts
+	}
+	return p.MaxAttempts
+}
+
+// GetBackoff returns the backoff between attempts
+func (p *RetryPolicy) GetBackoff() time.Duration {
+	if p.Backoff < 0 {
+		return defaultRetryBackoff
+	}
+	return p.Backoff
+}
+
+// Tx is a transaction. Reads run immediately on the transaction's connection,
+// while commands queued on Multi run atomically on EXEC
+type Tx struct {
+	connection redis.Conn
+	pipeline   *Pipeline
+}
+
+// Multi returns the queue of commands to run atomically
+func (tx *Tx) Multi() *Pipeline {
+	return tx.pipeline
+}
+
+// Do runs a command immediately
+func (tx *Tx) Do(commandName string, args ...interface{}) (interface{}, error) {
+	return tx.connection.Do(commandName, args...)
+}
+
+// Get retrieves a key's value immediately
+func (tx *Tx) Get(key string) (string, bool, error) {
+	return toString(tx.connection.Do(getCommand, key))
+}
+
+// Exists checks immediately whether keys exist
+func (tx *Tx) Exists(keys ...string) (bool, error) {
+	return toPositive(tx.connection.Do(existsCommand, toInterfaces(keys)...))
+}
+
+// HGet retrieves a key's field's value immediately
+func (tx *Tx) HGet(key string, field string) (string, bool, error) {
+	return toString(tx.connection.Do(hGetCommand, key, field))
+}
+
+// HGetAll retrieves a key's fields and values immediately
+func (tx *Tx) HGetAll(key string) (map[string]string, error) {
+	return redis.StringMap(tx.connection.Do(hGetAllCommand, key))
+}
+
+// WithRetryPolicy returns a shallow copy of the client that retries aborted transactions using the provided policy
+func (c *Client) WithRetryPolicy(policy *RetryPolicy) *Client {
+	client := *c
+	client.retryPolicy = policy
+	return &client
+}
+
+// Transaction watches the keys, runs the function and executes its queued commands atomically.
+// The transaction is retried according to the client's retry policy if a watched key changed
+func (c *Client) Transaction(fn func(tx *Tx) error, watchKeys ...string) error {
+	policy := c.retryPolicy
+	if policy == nil {
+		policy = &RetryPolicy{MaxAttempts: defaultRetryMaxAttempts, Backoff: defaultRetryBackoff}
+	}
+
+	maxAttempts := policy.GetMaxAttempts()
+	backoff := policy.GetBackoff()
+
+	for attempt := 1; ; attempt++ {
+		err := c.transaction(fn, watchKeys)
+		if err != ErrTransactionAborted || attempt >= maxAttempts {
+			return err
+		}
+
+		err = c.sleep(backoff)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Client) transaction(fn func(tx *Tx) error, watchKeys []string) error {
+	connection := c.getWriteConnection()
+	defer connection.Close()
+
+	if len(watchKeys) > 0 {
+		_, err := connection.Do(watchCommand, toInterfaces(watchKeys)...)
+		if err != nil {
+			return err
+		}
+	}
+
+	tx := &Tx{connection: connection, pipeline: c.Pipeline()}
+	err := fn(tx)
+	if err != nil {
+		connection.Do(unwatchCommand)
+		return err
+	}
+
+	commands := tx.pipeline.commands
+	if len(commands) == 0 {
+		_, err = connection.Do(unwatchCommand)
+		return err
+	}
+
+	err = connection.Send(multiCommand)
+	if err != nil {
+		return failCommands(commands, err)
+	}
+
+	for _, command := range commands {
+		err = connection.Send(command.name, command.args...)
+		if err != nil {
+			return failCommands(commands, err)
+		}
+	}
+
+	replies, err := redis.Values(connection.Do(execCommand))
+	if err == redis.ErrNil {
+		return ErrTransactionAborted
+	}
+	if err != nil {
+		return failCommands(commands, err)
+	}
+
+	for index, command := range commands {
+		if index >= len(replies) {
+			command.result.set(nil, redis.ErrNil)
+			continue
+		}
+
+		reply := replies[index]
+		if e, ok := reply.(redis.Error); ok {
+			command.result.set(nil, e)
+			continue
+		}
+		command.result.set(reply, nil)
+	}
+	return nil
+}
+
+func (c *Client) sleep(duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-c.Context().Done():
+		return c.Context().Err()
+	}
+}
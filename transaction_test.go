@@ -0,0 +1,132 @@
+package xredis
+
+import (
+	"errors"
+	"github.com/garyburd/redigo/redis"
+	"github.com/rafaeljusto/redigomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy_GetMaxAttempts(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 1}
+	assert.Equal(t, policy.GetMaxAttempts(), 1)
+
+	policy = RetryPolicy{MaxAttempts: 0}
+	assert.Equal(t, policy.GetMaxAttempts(), 0)
+
+	policy = RetryPolicy{MaxAttempts: -1}
+	assert.Equal(t, policy.GetMaxAttempts(), defaultRetryMaxAttempts)
+}
+
+func TestRetryPolicy_GetBackoff(t *testing.T) {
+	policy := RetryPolicy{Backoff: 1}
+	assert.Equal(t, policy.GetBackoff(), time.Duration(1))
+
+	policy = RetryPolicy{Backoff: 0}
+	assert.Equal(t, policy.GetBackoff(), time.Duration(0))
+
+	policy = RetryPolicy{Backoff: -1}
+	assert.Equal(t, policy.GetBackoff(), defaultRetryBackoff)
+}
+
+func TestClient_Transaction(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("WATCH", "counter").Expect("OK")
+	connection.Command("GET", "counter").Expect("10")
+	connection.Command("MULTI").Expect("OK")
+	connection.Command("SET", "counter", "11").Expect("QUEUED")
+	connection.Command("INCRBY", "name", int64(1)).Expect("QUEUED")
+	connection.Command("EXEC").Expect([]interface{}{"OK", redis.Error("ERR value is not an integer or out of range")})
+
+	client := mockClient(connection)
+
+	var set *BoolResult
+	var incr *IntResult
+	err := client.Transaction(func(tx *Tx) error {
+		value, found, err := tx.Get("counter")
+		assert.Equal(t, value, "10")
+		assert.True(t, found)
+		assert.Nil(t, err)
+
+		set = tx.Multi().Set("counter", "11")
+		incr = tx.Multi().Incr("name")
+		return nil
+	}, "counter")
+	assert.Nil(t, err)
+
+	ok, err := set.Result()
+	assert.True(t, ok)
+	assert.Nil(t, err)
+	assert.NotNil(t, incr.Err())
+}
+
+func TestClient_TransactionAborted(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("WATCH", "counter").Expect("OK")
+	connection.Command("MULTI").Expect("OK")
+	connection.Command("SET", "counter", "11").Expect("QUEUED")
+	connection.Command("EXEC").Expect(nil).Expect(nil).Expect([]interface{}{"OK"})
+
+	client := mockClient(connection).WithRetryPolicy(&RetryPolicy{MaxAttempts: 2, Backoff: 0})
+
+	attempts := 0
+	err := client.Transaction(func(tx *Tx) error {
+		attempts++
+		tx.Multi().Set("counter", "11")
+		return nil
+	}, "counter")
+	assert.Equal(t, err, ErrTransactionAborted)
+	assert.Equal(t, attempts, 2)
+
+	var set *BoolResult
+	err = client.WithRetryPolicy(&RetryPolicy{MaxAttempts: 2, Backoff: 0}).Transaction(func(tx *Tx) error {
+		set = tx.Multi().Set("counter", "11")
+		return nil
+	}, "counter")
+	assert.Nil(t, err)
+
+	ok, err := set.Result()
+	assert.True(t, ok)
+	assert.Nil(t, err)
+}
+
+func TestClient_TransactionWithoutRetries(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("WATCH", "counter").Expect("OK")
+	connection.Command("MULTI").Expect("OK")
+	connection.Command("SET", "counter", "11").Expect("QUEUED")
+	connection.Command("EXEC").Expect(nil)
+
+	client := mockClient(connection).WithRetryPolicy(&RetryPolicy{MaxAttempts: 0})
+
+	attempts := 0
+	err := client.Transaction(func(tx *Tx) error {
+		attempts++
+		tx.Multi().Set("counter", "11")
+		return nil
+	}, "counter")
+	assert.Equal(t, err, ErrTransactionAborted)
+	assert.Equal(t, attempts, 1)
+}
+
+func TestClient_TransactionError(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("WATCH", "counter").Expect("OK")
+	connection.Command("UNWATCH").Expect("OK")
+
+	client := mockClient(connection)
+
+	oops := errors.New("Oops")
+	err := client.Transaction(func(tx *Tx) error {
+		tx.Multi().Set("counter", "11")
+		return oops
+	}, "counter")
+	assert.Equal(t, err, oops)
+
+	err = client.Transaction(func(tx *Tx) error {
+		return nil
+	}, "counter")
+	assert.Nil(t, err)
+}
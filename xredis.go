@@ -1,14 +1,24 @@
 package xredis
 
 import (
+	"context"
+	"errors"
+	"github.com/FZambia/go-sentinel"
 	"github.com/garyburd/redigo/redis"
 	"strconv"
+	"time"
 )
 
 const (
-	expireOption    = "EX"
-	notExistsOption = "NX"
-	matchOption     = "MATCH"
+	expireOption               = "EX"
+	expireMillisecondsOption   = "PX"
+	expireAtOption             = "EXAT"
+	expireAtMillisecondsOption = "PXAT"
+	keepTTLOption              = "KEEPTTL"
+	getOption                  = "GET"
+	notExistsOption            = "NX"
+	existsOption               = "XX"
+	matchOption                = "MATCH"
 
 	setCommand          = "SET"
 	delCommand          = "DEL"
@@ -36,41 +46,90 @@ const (
 	incrByFloatCommand  = "INCRBYFLOAT"
 	hIncrByCommand      = "HINCRBY"
 	hIncrByFloatCommand = "HINCRBYFLOAT"
+
+	blockingReadTimeoutMargin = time.Second
 )
 
 // DefaultClient returns a client with default options
 func DefaultClient() *Client {
-	pool := newServerPool(&Options{})
-	return NewClient(pool)
+	return SetupClient(&Options{})
 }
 
 // SetupClient returns a client with provided options
 func SetupClient(options *Options) *Client {
 	pool := newServerPool(options)
-	return NewClient(pool)
+	return &Client{writePool: pool, readPool: pool, interruptible: true}
 }
 
 // SetupSentinelClient returns a client with provided options
 func SetupSentinelClient(options *SentinelOptions) *Client {
-	writePool := newWriteSentinelPool(options)
-	readPool := newReadSentinelPool(options)
-	return &Client{writePool: writePool, readPool: readPool}
+	sentinelDetails := createSentinel(options)
+	monitor := newSentinelMonitor(options)
+	writePool := newWriteSentinelPool(options, sentinelDetails, monitor)
+	readPool := newReadSentinelPool(options, sentinelDetails, monitor)
+	monitor.start(sentinelsDial(options, sentinelDetails))
+	return &Client{writePool: writePool, readPool: readPool, sentinel: sentinelDetails, monitor: monitor, interruptible: true}
+}
+
+// SetupClusterClient returns a client that routes commands to the cluster's nodes with provided options,
+// or an error if none of the nodes provided returned the cluster's topology
+func SetupClusterClient(options *ClusterOptions) (*Client, error) {
+	clusterDetails := newCluster(options, clusterDial(options))
+	err := clusterDetails.start()
+	if err != nil {
+		clusterDetails.close()
+		return nil, err
+	}
+	return &Client{cluster: clusterDetails, interruptible: true}, nil
 }
 
-// NewClient returns a client using provided redis.Pool
+// NewClient returns a client using provided redis.Pool.
+// The pool is left untouched, so a call abandoned when the client's context is done keeps its connection busy until it completes
 func NewClient(pool *redis.Pool) *Client {
 	return &Client{writePool: pool, readPool: pool}
 }
 
 // Client redis client
 type Client struct {
-	writePool *redis.Pool
-	readPool  *redis.Pool
+	writePool      *redis.Pool
+	readPool       *redis.Pool
+	sentinel       *sentinel.Sentinel
+	monitor        *sentinelMonitor
+	cluster        *cluster
+	ctx            context.Context
+	retryPolicy    *RetryPolicy
+	codec          Codec
+	session        *session
+	readFromMaster bool
+	interruptible  bool
+}
+
+// WithContext returns a shallow copy of the client whose commands honor the provided context.
+// A nil context is treated as context.Background()
+func (c *Client) WithContext(ctx context.Context) *Client {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	client := *c
+	client.ctx = ctx
+	return &client
+}
+
+// Context returns the client's context
+func (c *Client) Context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
 }
 
-// GetConnection gets a connection from the pool
+// GetConnection gets a connection from the pool, honoring the client's context
 func (c *Client) GetConnection() redis.Conn {
-	return c.writePool.Get()
+	if c.cluster != nil {
+		return newClusterConnection(c, false)
+	}
+	return c.getConnection(c.writePool)
 }
 
 // Ping pings redis
@@ -81,20 +140,18 @@ func (c *Client) Ping() (string, error) {
 	return redis.String(connection.Do(pingCommand))
 }
 
-// FlushDb flushes the keys of the current database
+// FlushDb flushes the keys of the current database. On a cluster, the keys of every master are flushed
 func (c *Client) FlushDb() error {
-	connection := c.getWriteConnection()
-	defer connection.Close()
-
-	return toError(connection.Do(flushDbCommand))
+	return c.forEachNode(func(connection redis.Conn) error {
+		return toError(connection.Do(flushDbCommand))
+	})
 }
 
-// FlushAll flushes the keys of all databases
+// FlushAll flushes the keys of all databases. On a cluster, the keys of every master are flushed
 func (c *Client) FlushAll() error {
-	connection := c.getWriteConnection()
-	defer connection.Close()
-
-	return toError(connection.Do(flushAllCommand))
+	return c.forEachNode(func(connection redis.Conn) error {
+		return toError(connection.Do(flushAllCommand))
+	})
 }
 
 // Echo echoes the message
@@ -105,8 +162,12 @@ func (c *Client) Echo(message string) (string, error) {
 	return redis.String(connection.Do(echoCommand, message))
 }
 
-// Info returns redis information and statistics
+// Info returns redis information and statistics. It is not supported on a cluster, whose nodes each have their own
 func (c *Client) Info() (string, error) {
+	if c.cluster != nil {
+		return "", errors.New(clusterUnsupportedError)
+	}
+
 	connection := c.getWriteConnection()
 	defer connection.Close()
 
@@ -154,8 +215,7 @@ func (c *Client) Expire(key string, timeout int64) (bool, error) {
 	connection := c.getWriteConnection()
 	defer connection.Close()
 
-	count, err := redis.Int64(connection.Do(expireCommand, key, timeout))
-	return count > 0, err
+	return toPositive(connection.Do(expireCommand, key, timeout))
 }
 
 // Set sets a key/value pair
@@ -195,12 +255,7 @@ func (c *Client) Exists(keys ...string) (bool, error) {
 	connection := c.getReadConnection()
 	defer connection.Close()
 
-	interfaces := make([]interface{}, len(keys))
-	for i, key := range keys {
-		interfaces[i] = key
-	}
-	count, err := redis.Int64(connection.Do(existsCommand, interfaces...))
-	return count > 0, err
+	return toPositive(connection.Do(existsCommand, toInterfaces(keys)...))
 }
 
 // Del deletes keys
@@ -208,15 +263,21 @@ func (c *Client) Del(keys ...string) (int64, error) {
 	connection := c.getWriteConnection()
 	defer connection.Close()
 
-	interfaces := make([]interface{}, len(keys))
-	for i, key := range keys {
-		interfaces[i] = key
-	}
-	return redis.Int64(connection.Do(delCommand, interfaces...))
+	return redis.Int64(connection.Do(delCommand, toInterfaces(keys)...))
 }
 
-// Keys retrieves keys that match a pattern
+// Keys retrieves keys that match a pattern. On a cluster, the keys of every master are retrieved
 func (c *Client) Keys(pattern string) ([]string, error) {
+	if c.cluster != nil {
+		var keys []string
+		err := c.forEachNode(func(connection redis.Conn) error {
+			nodeKeys, err := redis.Strings(connection.Do(keysCommand, pattern))
+			keys = append(keys, nodeKeys...)
+			return err
+		})
+		return keys, err
+	}
+
 	connection := c.getReadConnection()
 	defer connection.Close()
 
@@ -276,8 +337,7 @@ func (c *Client) HSet(key string, field string, value string) (bool, error) {
 	connection := c.getWriteConnection()
 	defer connection.Close()
 
-	code, err := redis.Int(connection.Do(hSetCommand, key, field, value))
-	return code > 0, err
+	return toPositive(connection.Do(hSetCommand, key, field, value))
 }
 
 // HKeys retrieves a hash's keys
@@ -317,12 +377,7 @@ func (c *Client) HDel(key string, fields ...string) (int64, error) {
 	connection := c.getWriteConnection()
 	defer connection.Close()
 
-	interfaces := make([]interface{}, len(fields)+1)
-	interfaces[0] = key
-	for i, key := range fields {
-		interfaces[i+1] = key
-	}
-	return redis.Int64(connection.Do(hDelCommand, interfaces...))
+	return redis.Int64(connection.Do(hDelCommand, prepend(key, fields)...))
 }
 
 // HIncr increments the key's field's value
@@ -363,20 +418,148 @@ func (c *Client) HDecrByFloat(key string, field string, decrement float64) (floa
 
 // Close closes connections writePool
 func (c *Client) Close() error {
+	if c.cluster != nil {
+		return c.cluster.close()
+	}
+
+	if c.monitor != nil {
+		c.monitor.close()
+	}
+
 	err := c.writePool.Close()
 	if err != nil {
 		return err
 	}
 
-	return c.readPool.Close()
+	err = c.readPool.Close()
+	if err != nil {
+		return err
+	}
+
+	if c.sentinel != nil {
+		return c.sentinel.Close()
+	}
+	return nil
 }
 
 func (c *Client) getWriteConnection() redis.Conn {
-	return c.writePool.Get()
+	connection := c.getMasterConnection()
+	if c.session == nil {
+		return connection
+	}
+	return &sessionConnection{Conn: connection, session: c.session, cluster: c.cluster != nil}
 }
 
+// getReadConnection returns a connection to a replica unless the client reads from the master
+// or its session's writes require it
 func (c *Client) getReadConnection() redis.Conn {
-	return c.readPool.Get()
+	master, offset := c.session.state()
+	if c.readFromMaster || master {
+		return c.getMasterConnection()
+	}
+
+	if c.cluster != nil {
+		return newClusterConnection(c, c.cluster.options.GetReadFromReplicas())
+	}
+
+	connection := c.getConnection(c.readPool)
+	if offset <= 0 || c.readPool == c.writePool || caughtUp(connection, offset) {
+		return connection
+	}
+
+	connection.Close()
+	return c.getMasterConnection()
+}
+
+func (c *Client) getMasterConnection() redis.Conn {
+	if c.cluster != nil {
+		return newClusterConnection(c, false)
+	}
+	return c.getConnection(c.writePool)
+}
+
+// nodeConnection returns a connection to the cluster's node, or a write connection if the address is empty
+func (c *Client) nodeConnection(address string) redis.Conn {
+	if c.cluster == nil || len(address) == 0 {
+		return c.getWriteConnection()
+	}
+	return c.getConnection(c.cluster.pool(address))
+}
+
+// nodeAddresses returns the addresses of the cluster's masters, or a single empty address outside of a cluster
+func (c *Client) nodeAddresses() []string {
+	if c.cluster == nil {
+		return []string{""}
+	}
+	return c.cluster.masterAddresses()
+}
+
+// forEachNode runs the function on a write connection, or on a connection to each of the cluster's masters,
+// and stops at the first error
+func (c *Client) forEachNode(fn func(connection redis.Conn) error) error {
+	for _, address := range c.nodeAddresses() {
+		err := c.onNode(address, fn)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Client) onNode(address string, fn func(connection redis.Conn) error) error {
+	connection := c.nodeConnection(address)
+	defer connection.Close()
+
+	return fn(connection)
+}
+
+// dial creates a connection outside of the pools
+func (c *Client) dial() (redis.Conn, error) {
+	if c.cluster != nil {
+		return c.cluster.dial(c.cluster.anyMaster())
+	}
+
+	connection, err := c.writePool.Dial()
+	if interruptible, ok := connection.(*interruptibleConnection); ok {
+		return interruptible.Conn, err
+	}
+	return connection, err
+}
+
+func (c *Client) masterAddress() (string, error) {
+	if c.sentinel == nil {
+		return "", nil
+	}
+	return c.sentinel.MasterAddr()
+}
+
+func (c *Client) getConnection(pool *redis.Pool) redis.Conn {
+	if c.ctx == nil {
+		return pool.Get()
+	}
+
+	if err := c.ctx.Err(); err != nil {
+		return errorConnection{err: err}
+	}
+
+	connection, err := pool.GetContext(c.ctx)
+	if err != nil {
+		return errorConnection{err: err}
+	}
+	return newContextConnection(c.ctx, connection, c.interruptible)
+}
+
+// doBlocking extends the connection's read timeout to cover a command that blocks up to the timeout
+func doBlocking(connection redis.Conn, timeout time.Duration, commandName string, args ...interface{}) (interface{}, error) {
+	if _, ok := connection.(redis.ConnWithTimeout); !ok {
+		return connection.Do(commandName, args...)
+	}
+
+	var readTimeout time.Duration
+	if timeout > 0 {
+		readTimeout = timeout + blockingReadTimeoutMargin
+	}
+	return redis.DoWithTimeout(connection, readTimeout, commandName, args...)
 }
 
 func toError(reply interface{}, err error) error {
@@ -389,6 +572,33 @@ func toBool(reply interface{}, err error) (bool, error) {
 	return ok, e
 }
 
+func toInt(reply interface{}, err error) (int64, bool, error) {
+	result, e := redis.Int64(reply, err)
+	if e == redis.ErrNil {
+		return result, false, nil
+	}
+	if e != nil {
+		return result, false, e
+	}
+	return result, true, nil
+}
+
+func toFloat(reply interface{}, err error) (float64, bool, error) {
+	result, e := redis.Float64(reply, err)
+	if e == redis.ErrNil {
+		return result, false, nil
+	}
+	if e != nil {
+		return result, false, e
+	}
+	return result, true, nil
+}
+
+func toPositive(reply interface{}, err error) (bool, error) {
+	count, e := redis.Int64(reply, err)
+	return count > 0, e
+}
+
 func toString(reply interface{}, err error) (string, bool, error) {
 	result, e := redis.String(reply, err)
 	if e == redis.ErrNil {
@@ -400,6 +610,31 @@ func toString(reply interface{}, err error) (string, bool, error) {
 	return result, true, nil
 }
 
+func toStrings(reply interface{}, err error) ([]string, error) {
+	results, e := redis.Strings(reply, err)
+	if e == redis.ErrNil {
+		return []string{}, nil
+	}
+	return results, e
+}
+
+func toInterfaces(values []string) []interface{} {
+	interfaces := make([]interface{}, len(values))
+	for i, value := range values {
+		interfaces[i] = value
+	}
+	return interfaces
+}
+
+func prepend(first string, values []string) []interface{} {
+	interfaces := make([]interface{}, len(values)+1)
+	interfaces[0] = first
+	for i, value := range values {
+		interfaces[i+1] = value
+	}
+	return interfaces
+}
+
 func parseScanResults(results []interface{}) (int64, []string, error) {
 	if len(results) != 2 {
 		return 0, []string{}, nil
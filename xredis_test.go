@@ -1,11 +1,13 @@
 package xredis
 
 import (
+	"context"
 	"errors"
 	"github.com/garyburd/redigo/redis"
 	"github.com/rafaeljusto/redigomock"
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 )
 
 func TestClient_Close(t *testing.T) {
@@ -489,6 +491,32 @@ func TestClient_HDecrByFloat(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestClient_WithContext(t *testing.T) {
+	connection := redigomock.NewConn()
+	connection.Command("GET", "key").Expect("value")
+
+	client := mockClient(connection)
+	assert.Equal(t, client.Context(), context.Background())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	contextClient := client.WithContext(ctx)
+	assert.Equal(t, contextClient.Context(), ctx)
+
+	result, ok, err := contextClient.Get("key")
+	assert.Equal(t, result, "value")
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	cancel()
+
+	result, ok, err = contextClient.Get("key")
+	assert.Equal(t, result, "")
+	assert.False(t, ok)
+	assert.Equal(t, err, context.Canceled)
+
+	assert.Equal(t, client.WithContext(nil).Context(), context.Background())
+}
+
 func TestDefaultClient(t *testing.T) {
 	client := DefaultClient()
 	defer client.Close()
@@ -504,6 +532,12 @@ func TestSetupSentinelClient(t *testing.T) {
 	defer client.Close()
 }
 
+func TestSetupClusterClient(t *testing.T) {
+	client, err := SetupClusterClient(&ClusterOptions{Addresses: []string{"127.0.0.1:1"}})
+	assert.Nil(t, client)
+	assert.NotNil(t, err)
+}
+
 func mockClient(connection *redigomock.Conn) *Client {
 	pool := &redis.Pool{
 		Dial: func() (redis.Conn, error) {
@@ -512,3 +546,24 @@ func mockClient(connection *redigomock.Conn) *Client {
 	}
 	return NewClient(pool)
 }
+
+type timeoutConnection struct {
+	*redigomock.Conn
+}
+
+func (c timeoutConnection) DoWithTimeout(timeout time.Duration, commandName string, args ...interface{}) (interface{}, error) {
+	return c.Do(commandName, args...)
+}
+
+func (c timeoutConnection) ReceiveWithTimeout(timeout time.Duration) (interface{}, error) {
+	return c.Receive()
+}
+
+func mockBlockingClient(connection *redigomock.Conn) *Client {
+	pool := &redis.Pool{
+		Dial: func() (redis.Conn, error) {
+			return timeoutConnection{Conn: connection}, nil
+		},
+	}
+	return NewClient(pool)
+}